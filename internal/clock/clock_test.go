@@ -0,0 +1,23 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+	assert.Equal(t, start, c.Now())
+
+	c.Advance(time.Hour)
+	assert.Equal(t, start.Add(time.Hour), c.Now())
+
+	c.AdvanceDays(14)
+	assert.Equal(t, time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC), c.Now())
+
+	c.Set(start)
+	assert.Equal(t, start, c.Now())
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -13,10 +14,57 @@ type Config struct {
 	DBUser     string
 	DBPassword string
 	DBName     string
+
+	// RoundingMode is the policy used to round computed amounts to cents: half_up (default) or half_even.
+	RoundingMode string
+	// RoundingRemainder is the installment absorbing the cents a schedule does not split evenly: final (default) or first.
+	RoundingRemainder string
+	// AllowPartialPayments and AllowOverpayments relax the rule that a payment must equal the amount due.
+	AllowPartialPayments bool
+	AllowOverpayments    bool
+	// PayoffRebatePolicy is how unearned interest is rebated on early payoff: pro_rata (default), rule_of_78 or none.
+	PayoffRebatePolicy string
+	// LateFeeFlatPerInstallment and LateFeePercent are charged on every installment still unpaid LateFeeGraceDays after
+	// its due date, the percent applies to what is left of the installment. LateFeeCap limits the fees of one loan,
+	// empty or 0 means no cap.
+	LateFeeFlatPerInstallment string
+	LateFeePercent            string
+	LateFeeGraceDays          int
+	LateFeeCap                string
+	// DelinquencyJobSchedule is the cron expression of the job refreshing delinquent weeks, empty disables it.
+	DelinquencyJobSchedule string
+	// DelinquencyJobBatchSize is how many loans the job lists per query.
+	DelinquencyJobBatchSize int
+	// WriteOffAfterInstallments makes the delinquency job write off delinquent loans with that many installments
+	// overdue, 0 (default) leaves write-offs to be made manually.
+	WriteOffAfterInstallments int
+	// InterestAccrualMethod is how interest is earned day by day: straight_line (default) or effective_interest.
+	InterestAccrualMethod string
+	// AccrualJobSchedule is the cron expression of the job earning accrued interest, empty disables it.
+	AccrualJobSchedule string
+	// AccrualJobBatchSize is how many loans the accrual job lists per query.
+	AccrualJobBatchSize int
+	// BusinessDayConvention moves due dates off weekends and holidays: none (default), following, preceding or
+	// modified_following. HolidayRegion picks the holidays, read from HolidayFile when set and from the holidays table otherwise.
+	BusinessDayConvention string
+	HolidayRegion         string
+	HolidayFile           string
+	// IdempotencyLease is how long an Idempotency-Key stays reserved by a request that never finished, e.g. because
+	// the service was restarted, before a retry can take it over. It must be longer than any request may take.
+	IdempotencyLease time.Duration
 }
 
 func LoadConfig() *Config {
 	viper.SetConfigFile(".env")
+	viper.SetDefault("PAYMENT_ALLOW_PARTIAL", true)
+	viper.SetDefault("PAYMENT_ALLOW_OVERPAYMENT", true)
+	viper.SetDefault("DELINQUENCY_JOB_SCHEDULE", "@hourly")
+	viper.SetDefault("DELINQUENCY_JOB_BATCH_SIZE", 100)
+	viper.SetDefault("ACCRUAL_JOB_SCHEDULE", "@daily")
+	viper.SetDefault("ACCRUAL_JOB_BATCH_SIZE", 100)
+	viper.SetDefault("BUSINESS_DAY_CONVENTION", "none")
+	viper.SetDefault("HOLIDAY_REGION", "ID")
+	viper.SetDefault("IDEMPOTENCY_LEASE", "5m")
 	if err := viper.ReadInConfig(); err != nil {
 		log.Fatalf("Error reading config file, %s", err)
 	}
@@ -27,6 +75,31 @@ func LoadConfig() *Config {
 		DBUser:     viper.GetString("DB_USER"),
 		DBPassword: viper.GetString("DB_PASSWORD"),
 		DBName:     viper.GetString("DB_NAME"),
+
+		RoundingMode:         viper.GetString("ROUNDING_MODE"),
+		RoundingRemainder:    viper.GetString("ROUNDING_REMAINDER"),
+		AllowPartialPayments: viper.GetBool("PAYMENT_ALLOW_PARTIAL"),
+		AllowOverpayments:    viper.GetBool("PAYMENT_ALLOW_OVERPAYMENT"),
+		PayoffRebatePolicy:   viper.GetString("PAYOFF_REBATE_POLICY"),
+
+		LateFeeFlatPerInstallment: viper.GetString("LATE_FEE_FLAT_PER_INSTALLMENT"),
+		LateFeePercent:            viper.GetString("LATE_FEE_PERCENT"),
+		LateFeeGraceDays:          viper.GetInt("LATE_FEE_GRACE_DAYS"),
+		LateFeeCap:                viper.GetString("LATE_FEE_CAP"),
+
+		DelinquencyJobSchedule:    viper.GetString("DELINQUENCY_JOB_SCHEDULE"),
+		DelinquencyJobBatchSize:   viper.GetInt("DELINQUENCY_JOB_BATCH_SIZE"),
+		WriteOffAfterInstallments: viper.GetInt("WRITE_OFF_AFTER_INSTALLMENTS"),
+
+		InterestAccrualMethod: viper.GetString("INTEREST_ACCRUAL_METHOD"),
+		AccrualJobSchedule:    viper.GetString("ACCRUAL_JOB_SCHEDULE"),
+		AccrualJobBatchSize:   viper.GetInt("ACCRUAL_JOB_BATCH_SIZE"),
+
+		BusinessDayConvention: viper.GetString("BUSINESS_DAY_CONVENTION"),
+		HolidayRegion:         viper.GetString("HOLIDAY_REGION"),
+		HolidayFile:           viper.GetString("HOLIDAY_FILE"),
+
+		IdempotencyLease: viper.GetDuration("IDEMPOTENCY_LEASE"),
 	}
 }
 
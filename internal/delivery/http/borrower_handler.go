@@ -0,0 +1,185 @@
+package http
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BorrowerHandler struct {
+	bu usecase.BorrowerUsecase
+}
+
+func NewBorrowerHandler(e *echo.Echo, bu usecase.BorrowerUsecase) {
+	handler := &BorrowerHandler{bu: bu}
+	e.POST("/borrowers", handler.CreateBorrower)
+	e.GET("/borrowers", handler.ListBorrowers)
+	e.GET("/borrowers/:id", handler.GetBorrower)
+	e.PATCH("/borrowers/:id", handler.UpdateBorrower)
+	e.DELETE("/borrowers/:id", handler.DeleteBorrower)
+	e.GET("/borrowers/:id/loans", handler.GetBorrowerLoans)
+}
+
+// @Summary Create a borrower
+// @Description Register a new borrower, the email must be unique
+// @ID create-borrower
+// @Accept json
+// @Produce json
+// @Param name body string true "Name"
+// @Param email body string true "Email"
+// @Param phone body string true "Phone"
+// @Success 201 {object} domain.Borrower
+// @Failure 409 {object} map[string]string
+// @Router /borrowers [post]
+func (bh *BorrowerHandler) CreateBorrower(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		Phone string `json:"phone"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	borrower, err := bh.bu.CreateBorrower(ctx, request.Name, request.Email, request.Phone)
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, borrower)
+}
+
+// @Summary List borrowers
+// @Description Get a page of borrowers that have not been deleted
+// @ID list-borrowers
+// @Produce json
+// @Param limit query int true "Limit"
+// @Param offset query int true "Offset"
+// @Success 200 {array} domain.Borrower
+// @Router /borrowers [get]
+func (bh *BorrowerHandler) ListBorrowers(c echo.Context) error {
+	ctx := c.Request().Context()
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid offset"})
+	}
+
+	borrowers, err := bh.bu.ListBorrowers(ctx, uint(limit), uint(offset))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, borrowers)
+}
+
+// @Summary Get a borrower
+// @Description Get a borrower by ID
+// @ID get-borrower
+// @Produce json
+// @Param id path int true "Borrower ID"
+// @Success 200 {object} domain.Borrower
+// @Failure 404 {object} map[string]string
+// @Router /borrowers/{id} [get]
+func (bh *BorrowerHandler) GetBorrower(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid borrower ID"})
+	}
+
+	borrower, err := bh.bu.GetBorrower(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, borrower)
+}
+
+// @Summary Update a borrower
+// @Description Partially update a borrower, omitted fields are left unchanged
+// @ID update-borrower
+// @Accept json
+// @Produce json
+// @Param id path int true "Borrower ID"
+// @Param name body string false "Name"
+// @Param email body string false "Email"
+// @Param phone body string false "Phone"
+// @Success 200 {object} domain.Borrower
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /borrowers/{id} [patch]
+func (bh *BorrowerHandler) UpdateBorrower(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid borrower ID"})
+	}
+	var request struct {
+		Name  *string `json:"name"`
+		Email *string `json:"email"`
+		Phone *string `json:"phone"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	borrower, err := bh.bu.UpdateBorrower(ctx, uint(id), &domain.BorrowerUpdate{
+		Name:  request.Name,
+		Email: request.Email,
+		Phone: request.Phone,
+	})
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, borrower)
+}
+
+// @Summary Delete a borrower
+// @Description Soft delete a borrower, every loan of the borrower must be paid off, written off or cancelled.
+// @Description The email of a deleted borrower can be registered again.
+// @ID delete-borrower
+// @Produce json
+// @Param id path int true "Borrower ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /borrowers/{id} [delete]
+func (bh *BorrowerHandler) DeleteBorrower(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid borrower ID"})
+	}
+
+	if err := bh.bu.DeleteBorrower(ctx, uint(id)); err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "borrower deleted"})
+}
+
+// @Summary Get loans of a borrower
+// @Description Get all loans belonging to a borrower
+// @ID get-borrower-loans
+// @Produce json
+// @Param id path int true "Borrower ID"
+// @Success 200 {array} domain.Loan
+// @Failure 404 {object} map[string]string
+// @Router /borrowers/{id}/loans [get]
+func (bh *BorrowerHandler) GetBorrowerLoans(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid borrower ID"})
+	}
+
+	loans, err := bh.bu.GetBorrowerLoans(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, loans)
+}
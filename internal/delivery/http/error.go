@@ -0,0 +1,36 @@
+package http
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/internal/utils"
+	"errors"
+	"net/http"
+)
+
+// errorStatus maps domain errors returned by the usecases to an HTTP status code.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidBorrower),
+		errors.Is(err, domain.ErrInvalidLoan),
+		errors.Is(err, domain.ErrInvalidLoanProduct),
+		errors.Is(err, domain.ErrInvalidMoney),
+		errors.Is(err, domain.ErrInvalidPayment),
+		errors.Is(err, domain.ErrInvalidRecovery):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrBorrowerNotFound),
+		errors.Is(err, domain.ErrLoanNotFound),
+		errors.Is(err, domain.ErrLoanProductNotFound),
+		errors.Is(err, domain.ErrPaymentNotFound),
+		errors.Is(err, domain.ErrWriteOffNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrBorrowerEmailTaken),
+		errors.Is(err, domain.ErrBorrowerHasOpenLoans),
+		errors.Is(err, domain.ErrLoanProductTaken),
+		errors.Is(err, domain.ErrLoanFullyPaid),
+		errors.Is(err, domain.ErrLoanNotActive),
+		errors.Is(err, domain.ErrInvalidTransition),
+		errors.Is(err, domain.ErrPaymentAlreadyReversed):
+		return http.StatusConflict
+	}
+	return utils.ErrorCode(err)
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"billing-engine/internal/domain"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// Idempotency makes a handler safe to retry: the first response for an Idempotency-Key is stored
+// and replayed for every later request with the same key and payload to the same method and path.
+// Requests without the header are passed through unchanged.
+func Idempotency(repo domain.IdempotencyRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := strings.TrimSpace(c.Request().Header.Get(IdempotencyKeyHeader))
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Idempotency-Key is too long"})
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			// the outcome must be recorded even if the client goes away mid-request
+			ctx := context.WithoutCancel(c.Request().Context())
+			hash := requestHash(c.Request().Method, c.Request().URL.Path, body)
+
+			// the same key sent to another endpoint or resource is another key
+			key = c.Request().Method + " " + c.Request().URL.Path + " " + key
+			record, created, err := repo.Reserve(ctx, key, hash)
+			if err != nil {
+				log.Printf("Error: %v", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			}
+			if !created {
+				switch {
+				case record.RequestHash != hash:
+					return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": "Idempotency-Key was already used with a different request"})
+				case !record.Completed:
+					return c.JSON(http.StatusConflict, map[string]string{"error": "a request with this Idempotency-Key is still in progress"})
+				}
+				c.Response().Header().Set(IdempotentReplayedHeader, "true")
+				return c.JSONBlob(record.ResponseStatus, record.ResponseBody)
+			}
+
+			// a panicking handler never gets to the end, the key is freed on the way out so it can be retried
+			finished := false
+			defer func() {
+				if finished {
+					return
+				}
+				if err := repo.Release(ctx, key); err != nil {
+					log.Printf("Error: %v", err)
+				}
+			}()
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			finished = true
+
+			// server errors are not replayed, the client may retry them with the same key
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				err = repo.Release(ctx, key)
+			} else {
+				err = repo.Complete(ctx, key, status, recorder.body.Bytes())
+			}
+			if err != nil {
+				log.Printf("Error: %v", err)
+			}
+			return nil
+		}
+	}
+}
+
+// requestHash fingerprints a request, JSON bodies are compacted so whitespace does not count as a different payload.
+func requestHash(method, path string, body []byte) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, body); err == nil {
+		body = compact.Bytes()
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies everything written to the response so it can be stored.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,153 @@
+package http
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]*domain.IdempotencyRecord
+}
+
+func newMemoryIdempotencyRepository() *memoryIdempotencyRepository {
+	return &memoryIdempotencyRepository{records: map[string]*domain.IdempotencyRecord{}}
+}
+
+func (m *memoryIdempotencyRepository) Reserve(ctx context.Context, key, requestHash string) (*domain.IdempotencyRecord, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if record, ok := m.records[key]; ok {
+		copied := *record
+		return &copied, false, nil
+	}
+	m.records[key] = &domain.IdempotencyRecord{Key: key, RequestHash: requestHash}
+	return &domain.IdempotencyRecord{Key: key, RequestHash: requestHash}, true, nil
+}
+
+func (m *memoryIdempotencyRepository) Complete(ctx context.Context, key string, status int, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	record := m.records[key]
+	record.ResponseStatus = status
+	record.ResponseBody = append([]byte(nil), body...)
+	record.Completed = true
+	return nil
+}
+
+func (m *memoryIdempotencyRepository) Release(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, key)
+	return nil
+}
+
+// newIdempotentServer registers a handler that counts how often it actually runs.
+func newIdempotentServer(status int) (*echo.Echo, *int) {
+	calls := 0
+	e := echo.New()
+	e.POST("/loans/:id/payment", func(c echo.Context) error {
+		calls++
+		return c.JSON(status, map[string]int{"payment_id": calls})
+	}, Idempotency(newMemoryIdempotencyRepository()))
+	return e, &calls
+}
+
+func doPayment(e *echo.Echo, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/loans/1/payment", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotencyReplaysFirstResponse(t *testing.T) {
+	e, calls := newIdempotentServer(http.StatusOK)
+
+	first := doPayment(e, "abc", `{"amount": "100.00"}`)
+	// whitespace differences do not make it a different request
+	second := doPayment(e, "abc", `{"amount":"100.00"}`)
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.JSONEq(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotencyRejectsDifferentPayload(t *testing.T) {
+	e, calls := newIdempotentServer(http.StatusOK)
+
+	doPayment(e, "abc", `{"amount": "100.00"}`)
+	rec := doPayment(e, "abc", `{"amount": "200.00"}`)
+
+	assert.Equal(t, 1, *calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestIdempotencyWithoutKey(t *testing.T) {
+	e, calls := newIdempotentServer(http.StatusOK)
+
+	doPayment(e, "", `{"amount": "100.00"}`)
+	doPayment(e, "", `{"amount": "100.00"}`)
+
+	assert.Equal(t, 2, *calls)
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	e, calls := newIdempotentServer(http.StatusInternalServerError)
+
+	doPayment(e, "abc", `{"amount": "100.00"}`)
+	rec := doPayment(e, "abc", `{"amount": "100.00"}`)
+
+	assert.Equal(t, 2, *calls)
+	assert.Empty(t, rec.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotencyReleasesKeyWhenHandlerPanics(t *testing.T) {
+	calls := 0
+	e := echo.New()
+	e.Use(middleware.Recover())
+	e.POST("/loans/:id/payment", func(c echo.Context) error {
+		calls++
+		if calls == 1 {
+			panic("connection reset")
+		}
+		return c.JSON(http.StatusOK, map[string]int{"payment_id": calls})
+	}, Idempotency(newMemoryIdempotencyRepository()))
+
+	first := doPayment(e, "abc", `{"amount": "100.00"}`)
+	second := doPayment(e, "abc", `{"amount": "100.00"}`)
+
+	assert.Equal(t, http.StatusInternalServerError, first.Code)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Empty(t, second.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotencyScopesKeysToTheRoute(t *testing.T) {
+	e, calls := newIdempotentServer(http.StatusOK)
+
+	doPayment(e, "abc", `{"amount": "100.00"}`)
+	req := httptest.NewRequest(http.MethodPost, "/loans/2/payment", strings.NewReader(`{"amount": "100.00"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(IdempotencyKeyHeader, "abc")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, 2, *calls)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(IdempotentReplayedHeader))
+}
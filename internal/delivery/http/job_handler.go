@@ -0,0 +1,49 @@
+package http
+
+import (
+	"billing-engine/internal/usecase"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type JobHandler struct {
+	du usecase.DelinquencyUsecase
+	au usecase.AccrualUsecase
+}
+
+func NewJobHandler(e *echo.Echo, du usecase.DelinquencyUsecase, au usecase.AccrualUsecase) {
+	handler := &JobHandler{du: du, au: au}
+	e.GET("/jobs/delinquency", handler.GetDelinquencyRun)
+	e.GET("/jobs/accrual", handler.GetAccrualRun)
+}
+
+// @Summary Get the last delinquency job run
+// @Description Get the status and counters of the latest run of the job that refreshes delinquent weeks, which may still be running
+// @ID get-delinquency-run
+// @Produce json
+// @Success 200 {object} domain.DelinquencyRun
+// @Failure 404 {object} map[string]string
+// @Router /jobs/delinquency [get]
+func (jh *JobHandler) GetDelinquencyRun(c echo.Context) error {
+	run := jh.du.LastRun()
+	if run == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "delinquency job has not run yet"})
+	}
+	return c.JSON(http.StatusOK, run)
+}
+
+// @Summary Get the last accrual job run
+// @Description Get the status and counters of the latest run of the job that earns the interest accrued on loans, which may still be running
+// @ID get-accrual-run
+// @Produce json
+// @Success 200 {object} domain.AccrualRun
+// @Failure 404 {object} map[string]string
+// @Router /jobs/accrual [get]
+func (jh *JobHandler) GetAccrualRun(c echo.Context) error {
+	run := jh.au.LastRun()
+	if run == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "accrual job has not run yet"})
+	}
+	return c.JSON(http.StatusOK, run)
+}
@@ -0,0 +1,140 @@
+package http
+
+import (
+	"billing-engine/internal/usecase"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LedgerHandler struct {
+	lu usecase.LedgerUsecase
+}
+
+func NewLedgerHandler(e *echo.Echo, lu usecase.LedgerUsecase) {
+	handler := &LedgerHandler{lu: lu}
+	e.GET("/ledger/trial-balance", handler.GetTrialBalance)
+	e.GET("/reports/interest-accruals", handler.GetInterestAccrualReport)
+	e.GET("/reports/write-offs", handler.GetWriteOffReport)
+}
+
+// @Summary Get the trial balance
+// @Description Sum the debits and credits posted to every ledger account up to the end of the as_of day, the
+// @Description ledger is balanced when both totals are equal
+// @ID get-trial-balance
+// @Produce json
+// @Param as_of query string false "Date as YYYY-MM-DD, defaults to now"
+// @Success 200 {object} domain.TrialBalance
+// @Failure 400 {object} map[string]string
+// @Router /ledger/trial-balance [get]
+func (lh *LedgerHandler) GetTrialBalance(c echo.Context) error {
+	ctx := c.Request().Context()
+	var asOf time.Time
+	if param := c.QueryParam("as_of"); param != "" {
+		date, err := time.Parse(time.DateOnly, param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid as_of, expected YYYY-MM-DD"})
+		}
+		// the entries posted during the whole day are included
+		asOf = date.AddDate(0, 0, 1).Add(-time.Microsecond)
+	}
+
+	balance, err := lh.lu.GetTrialBalance(ctx, asOf)
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, balance)
+}
+
+// @Summary Get the interest accrual report
+// @Description Sum the interest accrued on all loans day by day from from to to, both included
+// @ID get-interest-accrual-report
+// @Produce json
+// @Param from query string false "First day as YYYY-MM-DD, defaults to the first day of the month of to"
+// @Param to query string false "Last day as YYYY-MM-DD, defaults to today"
+// @Success 200 {object} domain.InterestAccrualReport
+// @Failure 400 {object} map[string]string
+// @Router /reports/interest-accruals [get]
+func (lh *LedgerHandler) GetInterestAccrualReport(c echo.Context) error {
+	ctx := c.Request().Context()
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	report, err := lh.lu.GetInterestAccrualReport(ctx, from, to)
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// reportPeriod parses the optional from and to dates of a report, zero when they are not given.
+func reportPeriod(c echo.Context) (from, to time.Time, err error) {
+	for _, param := range []struct {
+		name string
+		date *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		value := c.QueryParam(param.name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid %s, expected YYYY-MM-DD", param.name)
+		}
+		*param.date = date
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return time.Time{}, time.Time{}, errors.New("from must not be after to")
+	}
+	return from, to, nil
+}
+
+// @Summary Get the interest accruals of a loan
+// @Description Get the interest earned on the loan by every run of the accrual job, by day
+// @ID get-loan-accruals
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {array} domain.InterestAccrual
+// @Failure 404 {object} map[string]string
+// @Router /loans/{id}/accruals [get]
+func (lh *LoanHandler) GetInterestAccruals(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+
+	accruals, err := lh.lu.GetInterestAccruals(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, accruals)
+}
+
+// @Summary Get the journal of a loan
+// @Description Get the ledger entries recorded for the loan with their debit and credit postings, oldest first
+// @ID get-loan-journal
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {array} domain.JournalEntry
+// @Failure 404 {object} map[string]string
+// @Router /loans/{id}/journal [get]
+func (lh *LoanHandler) GetJournal(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+
+	entries, err := lh.lu.GetJournal(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, entries)
+}
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"billing-engine/internal/domain"
 	"billing-engine/internal/usecase"
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -11,25 +13,74 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// CreateLoanTimeout bounds how long creating a loan may take.
+const CreateLoanTimeout = 5 * time.Second
+
 type LoanHandler struct {
 	lu usecase.LoanUsecase
 }
 
-func NewLoanHandler(e *echo.Echo, lu usecase.LoanUsecase) {
+func NewLoanHandler(e *echo.Echo, lu usecase.LoanUsecase, ir domain.IdempotencyRepository) {
 	handler := &LoanHandler{lu: lu}
+	idempotent := Idempotency(ir)
 	e.GET("/loans/:id/outstanding", handler.GetOutstanding)
 	e.GET("/loans/:id/delinquent", handler.IsDelinquent)
-	e.POST("/loans/:id/payment", handler.MakePayment)
+	e.POST("/loans/:id/payment", handler.MakePayment, idempotent)
+	e.GET("/loans/:id/payments", handler.GetPayments)
+	e.POST("/loans/:id/payments/:paymentId/reverse", handler.ReversePayment, idempotent)
+	e.GET("/loans/:id/payoff-quote", handler.GetPayoffQuote)
+	e.POST("/loans/:id/payoff", handler.PayOff, idempotent)
+	e.POST("/loans/:id/approve", handler.Transition(domain.LoanEventApprove))
+	e.POST("/loans/:id/disburse", handler.Transition(domain.LoanEventDisburse))
+	e.POST("/loans/:id/close", handler.Transition(domain.LoanEventClose))
+	e.POST("/loans/:id/cancel", handler.Transition(domain.LoanEventCancel))
+	e.GET("/loans/:id/status-history", handler.GetStatusHistory)
+	e.POST("/loans/:id/restructure", handler.RestructureLoan, idempotent)
+	e.POST("/loans/:id/payment-holiday", handler.GrantPaymentHoliday, idempotent)
+	e.GET("/loans/:id/schedule", handler.GetSchedule)
+	e.GET("/loans/:id/journal", handler.GetJournal)
+	e.GET("/loans/:id/accruals", handler.GetInterestAccruals)
+	e.POST("/loans/:id/write-off", handler.WriteOffLoan)
+	e.GET("/loans/:id/write-off", handler.GetWriteOff)
+	e.POST("/loans/:id/recoveries", handler.RecordRecovery, idempotent)
+	e.GET("/loans/:id/recoveries", handler.GetRecoveries)
+	e.GET("/loans/:id", handler.GetLoan)
 	e.GET("/loans", handler.GetLoansWithBorrower)
-	e.POST("/loans", handler.CreateLoan)
+	e.POST("/loans", handler.CreateLoan, idempotent)
+}
+
+// @Summary Get loan details
+// @Description Get the loan with its borrower, its current schedule with the paid, unpaid or overdue state of
+// @Description every installment, the outstanding split into principal, interest and fees, the next installment
+// @Description to fall due and the arrears
+// @ID get-loan
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} domain.LoanDetail
+// @Failure 404 {object} map[string]string
+// @Router /loans/{id} [get]
+func (lh *LoanHandler) GetLoan(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+
+	detail, err := lh.lu.GetLoan(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, detail)
 }
 
 // @Summary Get outstanding amount
-// @Description Get the current outstanding amount for a loan
+// @Description Get the current outstanding amount for a loan, split between installments and unpaid late fees,
+// @Description with the principal, interest and installment fees still owed on the unpaid periods
 // @ID get-outstanding
 // @Produce json
 // @Param id path int true "Loan ID"
-// @Success 200 {object} map[string]float64
+// @Success 200 {object} map[string]domain.Money
+// @Failure 404 {object} map[string]string
 // @Router /loans/{id}/outstanding [get]
 func (lh *LoanHandler) GetOutstanding(c echo.Context) error {
 	ctx := c.Request().Context()
@@ -39,9 +90,16 @@ func (lh *LoanHandler) GetOutstanding(c echo.Context) error {
 	}
 	outstanding, err := lh.lu.GetOutstanding(ctx, uint(id))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusOK, map[string]float64{"outstanding": outstanding})
+	return c.JSON(http.StatusOK, map[string]domain.Money{
+		"outstanding":  outstanding.Total,
+		"installments": outstanding.Installments,
+		"principal":    outstanding.Components.Principal,
+		"interest":     outstanding.Components.Interest,
+		"fees":         outstanding.Components.Fee,
+		"late_fees":    outstanding.LateFees,
+	})
 }
 
 // @Summary Check if loan is delinquent
@@ -50,6 +108,7 @@ func (lh *LoanHandler) GetOutstanding(c echo.Context) error {
 // @Produce json
 // @Param id path int true "Loan ID"
 // @Success 200 {object} map[string]bool
+// @Failure 404 {object} map[string]string
 // @Router /loans/{id}/delinquent [get]
 func (lh *LoanHandler) IsDelinquent(c echo.Context) error {
 	ctx := c.Request().Context()
@@ -59,19 +118,21 @@ func (lh *LoanHandler) IsDelinquent(c echo.Context) error {
 	}
 	delinquent, err := lh.lu.IsDelinquent(ctx, uint(id))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
 	}
 	return c.JSON(http.StatusOK, delinquent)
 }
 
 // @Summary Make a payment
-// @Description Make a payment on the loan
+// @Description Make a payment on the loan, the amount is applied to the oldest unpaid periods first,
+// @Description settling the fee, then the interest and then the principal of each period
 // @ID make-payment
 // @Accept json
 // @Produce json
 // @Param id path int true "Loan ID"
-// @Param amount body float64 true "Payment Amount"
-// @Success 200 {object} map[string]string
+// @Param Idempotency-Key header string false "Replays the first response for retries with the same key"
+// @Param amount body string true "Payment Amount, as a decimal string"
+// @Success 200 {object} map[string]interface{}
 // @Router /loans/{id}/payment [post]
 func (lh *LoanHandler) MakePayment(c echo.Context) error {
 	ctx := c.Request().Context()
@@ -80,17 +141,112 @@ func (lh *LoanHandler) MakePayment(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
 	}
 	var request struct {
-		Amount float64 `json:"amount"`
+		Amount domain.Money `json:"amount"`
 	}
 	if err := c.Bind(&request); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
 
-	if err := lh.lu.MakePayment(ctx, uint(id), request.Amount); err != nil {
+	payment, err := lh.lu.MakePayment(ctx, uint(id), request.Amount)
+	if err != nil {
 		log.Printf("Error: %v", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":     "payment successful",
+		"payment_id":  payment.ID,
+		"allocations": payment.Allocations,
+	})
+}
+
+// @Summary Get payment history
+// @Description Get every payment received for a loan and the billing schedule periods it settled
+// @ID get-payments
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {array} domain.Payment
+// @Failure 404 {object} map[string]string
+// @Router /loans/{id}/payments [get]
+func (lh *LoanHandler) GetPayments(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+
+	payments, err := lh.lu.GetPayments(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, payments)
+}
+
+// @Summary Get early payoff quote
+// @Description Get the amount that settles the loan in full, after rebating unearned interest
+// @ID get-payoff-quote
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param as_of query string false "Quote date as YYYY-MM-DD, defaults to today"
+// @Success 200 {object} domain.PayoffQuote
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/payoff-quote [get]
+func (lh *LoanHandler) GetPayoffQuote(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+	var asOf time.Time
+	if param := c.QueryParam("as_of"); param != "" {
+		asOf, err = time.Parse(time.DateOnly, param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid as_of, expected YYYY-MM-DD"})
+		}
+	}
+
+	quote, err := lh.lu.GetPayoffQuote(ctx, uint(id), asOf)
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, quote)
+}
+
+// @Summary Pay off a loan
+// @Description Settle every remaining billing schedule period and close the loan, the amount must equal today's payoff quote
+// @ID pay-off
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param Idempotency-Key header string false "Replays the first response for retries with the same key"
+// @Param amount body string true "Payoff Amount, as a decimal string"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/payoff [post]
+func (lh *LoanHandler) PayOff(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
 	}
-	return c.JSON(http.StatusOK, map[string]string{"message": "payment successful"})
+	var request struct {
+		Amount domain.Money `json:"amount"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	payment, err := lh.lu.PayOff(ctx, uint(id), request.Amount)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":     "loan paid off",
+		"payment_id":  payment.ID,
+		"rebate":      payment.Rebate,
+		"allocations": payment.Allocations,
+	})
 }
 
 // @Summary Get loans with borrower information
@@ -120,37 +276,70 @@ func (lh *LoanHandler) GetLoansWithBorrower(c echo.Context) error {
 }
 
 // @Summary Create a new loan
-// @Description Create a new loan and generate a billing schedule
+// @Description Create a new loan priced by a loan product and generate a billing schedule with the principal,
+// @Description interest and fees of every installment. The amount and tenor must be offered by the product.
 // @ID create-loan
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Replays the first response for retries with the same key"
 // @Param borrower_id body int true "Borrower ID"
-// @Param amount body float64 true "Loan Amount"
-// @Param interest_rate body float64 true "Interest Rate"
-// @Param duration_weeks body int true "Duration in Weeks"
+// @Param product_id body int true "Loan Product ID"
+// @Param amount body string true "Loan Amount, as a decimal string"
+// @Param tenor_periods body int true "Number of installments, one of the product tenors"
+// @Param disbursement_date body string false "Start of the schedule as YYYY-MM-DD, today by default"
+// @Param first_due_date body string false "Due date of the first installment as YYYY-MM-DD, one period after the disbursement by default"
 // @Success 200 {object} map[string]uint
+// @Failure 400 {object} map[string]string
+// @Failure 504 {object} map[string]string
 // @Router /loans [post]
 func (lh *LoanHandler) CreateLoan(c echo.Context) error {
 	var request struct {
-		BorrowerID    uint    `json:"borrower_id"`
-		Amount        float64 `json:"amount"`
-		InterestRate  int     `json:"interest_rate"`
-		DurationWeeks int     `json:"duration_weeks"`
+		BorrowerID       uint         `json:"borrower_id"`
+		ProductID        uint         `json:"product_id"`
+		Amount           domain.Money `json:"amount"`
+		TenorPeriods     int          `json:"tenor_periods"`
+		DisbursementDate string       `json:"disbursement_date"`
+		FirstDueDate     string       `json:"first_due_date"`
 	}
 	if err := c.Bind(&request); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
 	}
+	disbursementDate, ok := parseDate(request.DisbursementDate)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid disbursement date"})
+	}
+	firstDueDate, ok := parseDate(request.FirstDueDate)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid first due date"})
+	}
 
 	// Create a context with a timeout
-	ctx, cancel := context.WithTimeout(c.Request().Context(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(c.Request().Context(), CreateLoanTimeout)
 	defer cancel()
-	loanID, err := lh.lu.CreateLoan(ctx, request.BorrowerID, request.Amount, request.InterestRate, request.DurationWeeks)
+	loanID, err := lh.lu.CreateLoan(ctx, usecase.LoanApplication{
+		BorrowerID:       request.BorrowerID,
+		ProductID:        request.ProductID,
+		Amount:           request.Amount,
+		TenorPeriods:     request.TenorPeriods,
+		DisbursementDate: disbursementDate,
+		FirstDueDate:     firstDueDate,
+	})
 	if err != nil {
-		if err == context.DeadlineExceeded {
-			return c.JSON(http.StatusRequestTimeout, map[string]string{"error": "request timed out"})
+		// a server side timeout, the idempotency key is released so the loan can be applied for again
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.JSON(http.StatusGatewayTimeout, map[string]string{"error": "request timed out"})
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, map[string]uint{"loan_id": loanID})
 }
+
+// parseDate reads an optional YYYY-MM-DD date, an empty string is the zero time.
+func parseDate(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, true
+	}
+	date, err := time.Parse(time.DateOnly, s)
+	return date, err == nil
+}
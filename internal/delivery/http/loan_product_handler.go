@@ -0,0 +1,157 @@
+package http
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/internal/usecase"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type LoanProductHandler struct {
+	pu usecase.LoanProductUsecase
+}
+
+func NewLoanProductHandler(e *echo.Echo, pu usecase.LoanProductUsecase) {
+	handler := &LoanProductHandler{pu: pu}
+	e.POST("/loan-products", handler.CreateLoanProduct)
+	e.GET("/loan-products", handler.ListLoanProducts)
+	e.GET("/loan-products/:id", handler.GetLoanProduct)
+	e.POST("/loan-products/:id/activate", handler.SetActive(true))
+	e.POST("/loan-products/:id/deactivate", handler.SetActive(false))
+}
+
+// parsePercent reads an optional decimal percent, an empty number is zero.
+func parsePercent(n json.Number) (*big.Rat, bool) {
+	if n == "" {
+		return new(big.Rat), true
+	}
+	return new(big.Rat).SetString(n.String())
+}
+
+// @Summary Create a loan product
+// @Description Define the terms new loans can be booked with, rates are in percent
+// @ID create-loan-product
+// @Accept json
+// @Produce json
+// @Param name body string true "Unique product name"
+// @Param min_amount body string true "Smallest loan amount, as a decimal string"
+// @Param max_amount body string true "Largest loan amount, as a decimal string"
+// @Param interest_rate body number true "Interest Rate in percent, for the whole term with flat interest and per year otherwise"
+// @Param interest_method body string false "flat (default), annuity or equal_principal"
+// @Param tenor_periods body []int true "Numbers of installments offered"
+// @Param repayment_frequency body string false "daily, weekly (default), bi_weekly, semi_monthly or monthly"
+// @Param installment_fee body string false "Fee added to every installment, as a decimal string"
+// @Param origination_fee_rate body number false "Percent of the amount charged with the first installment"
+// @Success 201 {object} domain.LoanProduct
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loan-products [post]
+func (ph *LoanProductHandler) CreateLoanProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+	var request struct {
+		Name               string       `json:"name"`
+		MinAmount          domain.Money `json:"min_amount"`
+		MaxAmount          domain.Money `json:"max_amount"`
+		InterestRate       json.Number  `json:"interest_rate"`
+		InterestMethod     string       `json:"interest_method"`
+		TenorPeriods       []int        `json:"tenor_periods"`
+		RepaymentFrequency string       `json:"repayment_frequency"`
+		InstallmentFee     domain.Money `json:"installment_fee"`
+		OriginationFeeRate json.Number  `json:"origination_fee_rate"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	interestRate, ok := parsePercent(request.InterestRate)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid interest rate"})
+	}
+	originationFeeRate, ok := parsePercent(request.OriginationFeeRate)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid origination fee rate"})
+	}
+
+	product, err := ph.pu.CreateLoanProduct(ctx, usecase.LoanProductTerms{
+		Name:               request.Name,
+		MinAmount:          request.MinAmount,
+		MaxAmount:          request.MaxAmount,
+		InterestRate:       interestRate,
+		InterestMethod:     domain.InterestMethod(request.InterestMethod),
+		TenorPeriods:       request.TenorPeriods,
+		RepaymentFrequency: domain.RepaymentFrequency(request.RepaymentFrequency),
+		InstallmentFee:     request.InstallmentFee,
+		OriginationFeeRate: originationFeeRate,
+	})
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, product)
+}
+
+// @Summary List loan products
+// @Description Get every loan product, including the ones closed to new loans
+// @ID list-loan-products
+// @Produce json
+// @Success 200 {array} domain.LoanProduct
+// @Router /loan-products [get]
+func (ph *LoanProductHandler) ListLoanProducts(c echo.Context) error {
+	products, err := ph.pu.ListLoanProducts(c.Request().Context())
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, products)
+}
+
+// @Summary Get a loan product
+// @Description Get a loan product by ID
+// @ID get-loan-product
+// @Produce json
+// @Param id path int true "Loan Product ID"
+// @Success 200 {object} domain.LoanProduct
+// @Failure 404 {object} map[string]string
+// @Router /loan-products/{id} [get]
+func (ph *LoanProductHandler) GetLoanProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan product ID"})
+	}
+
+	product, err := ph.pu.GetLoanProduct(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, product)
+}
+
+// @Summary Open or close a loan product
+// @Description Activate or deactivate a loan product, only active products accept new loans
+// @ID set-loan-product-active
+// @Produce json
+// @Param id path int true "Loan Product ID"
+// @Success 200 {object} domain.LoanProduct
+// @Failure 404 {object} map[string]string
+// @Router /loan-products/{id}/activate [post]
+// @Router /loan-products/{id}/deactivate [post]
+func (ph *LoanProductHandler) SetActive(active bool) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan product ID"})
+		}
+
+		if err := ph.pu.SetLoanProductActive(ctx, uint(id), active); err != nil {
+			return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+		}
+		product, err := ph.pu.GetLoanProduct(ctx, uint(id))
+		if err != nil {
+			return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusOK, product)
+	}
+}
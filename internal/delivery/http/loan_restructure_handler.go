@@ -0,0 +1,100 @@
+package http
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/internal/usecase"
+	"encoding/json"
+	"log"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary Restructure a loan
+// @Description Reschedule what is left of a loan with new terms. The unpaid principal plus the interest and fees
+// @Description already due become the principal of a new schedule starting today, the unpaid installments are
+// @Description superseded and kept for audit. Terms left out keep the loan's current ones.
+// @ID restructure-loan
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param Idempotency-Key header string false "Replays the first response for retries with the same key"
+// @Param tenor_periods body int true "Number of installments of the new schedule"
+// @Param interest_rate body number false "Interest Rate in percent, for the whole term with flat interest and per year otherwise"
+// @Param interest_method body string false "flat, annuity or equal_principal"
+// @Param repayment_frequency body string false "daily, weekly, bi_weekly, semi_monthly or monthly"
+// @Param first_due_date body string false "Due date of the first new installment as YYYY-MM-DD, one period from today by default"
+// @Param reason body string false "Why the loan is restructured"
+// @Success 200 {object} domain.LoanRestructure
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/restructure [post]
+func (lh *LoanHandler) RestructureLoan(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+	var request struct {
+		TenorPeriods       int         `json:"tenor_periods"`
+		InterestRate       json.Number `json:"interest_rate"`
+		InterestMethod     string      `json:"interest_method"`
+		RepaymentFrequency string      `json:"repayment_frequency"`
+		FirstDueDate       string      `json:"first_due_date"`
+		Reason             string      `json:"reason"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+	// an omitted rate keeps the current one rather than dropping it to zero
+	var interestRate *big.Rat
+	if request.InterestRate != "" {
+		var ok bool
+		if interestRate, ok = parsePercent(request.InterestRate); !ok {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid interest rate"})
+		}
+	}
+	firstDueDate, ok := parseDate(request.FirstDueDate)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid first due date"})
+	}
+
+	restructure, err := lh.lu.RestructureLoan(ctx, uint(id), usecase.LoanRestructuring{
+		TenorPeriods:       request.TenorPeriods,
+		InterestRate:       interestRate,
+		InterestMethod:     domain.InterestMethod(request.InterestMethod),
+		RepaymentFrequency: domain.RepaymentFrequency(request.RepaymentFrequency),
+		FirstDueDate:       firstDueDate,
+		Reason:             request.Reason,
+	})
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, restructure)
+}
+
+// @Summary Get the billing schedule
+// @Description Get every installment of a loan by version and period, installments superseded by a restructure
+// @Description have their SupersededAt set
+// @ID get-schedule
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {array} domain.BillingSchedule
+// @Failure 404 {object} map[string]string
+// @Router /loans/{id}/schedule [get]
+func (lh *LoanHandler) GetSchedule(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+
+	schedule, err := lh.lu.GetSchedule(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, schedule)
+}
@@ -0,0 +1,70 @@
+package http
+
+import (
+	"billing-engine/internal/domain"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary Change the loan status
+// @Description Apply a lifecycle event to the loan: approve, disburse, close or cancel. Loans are marked delinquent
+// @Description and cured by the delinquency job as their arrears change.
+// @Description Payments are only accepted once a loan is disbursed and until it is closed.
+// @ID transition-loan
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param reason body string false "Why the status changed"
+// @Success 200 {object} domain.LoanStatusTransition
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/approve [post]
+// @Router /loans/{id}/disburse [post]
+// @Router /loans/{id}/close [post]
+// @Router /loans/{id}/cancel [post]
+func (lh *LoanHandler) Transition(event domain.LoanEvent) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+		}
+		var request struct {
+			Reason string `json:"reason"`
+		}
+		if err := c.Bind(&request); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+		}
+
+		transition, err := lh.lu.TransitionLoan(ctx, uint(id), event, request.Reason)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusOK, transition)
+	}
+}
+
+// @Summary Get loan status history
+// @Description Get every status change of a loan, oldest first
+// @ID get-status-history
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {array} domain.LoanStatusTransition
+// @Failure 404 {object} map[string]string
+// @Router /loans/{id}/status-history [get]
+func (lh *LoanHandler) GetStatusHistory(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+
+	history, err := lh.lu.GetStatusHistory(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, history)
+}
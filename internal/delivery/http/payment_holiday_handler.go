@@ -0,0 +1,53 @@
+package http
+
+import (
+	"billing-engine/internal/usecase"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary Grant a payment holiday
+// @Description Defer the next installments not due yet by a number of periods, later installments move with them.
+// @Description Deferred installments are not in arrears before their new due date. With capitalise_interest the
+// @Description unpaid principal is charged the interest of the holiday, spread over the deferred installments.
+// @ID grant-payment-holiday
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param Idempotency-Key header string false "Replays the first response for retries with the same key"
+// @Param periods body int true "Number of periods without installments"
+// @Param capitalise_interest body bool false "Charge interest for the holiday, false by default"
+// @Param reason body string false "Why the installments are deferred"
+// @Success 200 {object} domain.PaymentHoliday
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/payment-holiday [post]
+func (lh *LoanHandler) GrantPaymentHoliday(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+	var request struct {
+		Periods            int    `json:"periods"`
+		CapitaliseInterest bool   `json:"capitalise_interest"`
+		Reason             string `json:"reason"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	holiday, err := lh.lu.GrantPaymentHoliday(ctx, uint(id), usecase.PaymentHolidayRequest{
+		Periods:            request.Periods,
+		CapitaliseInterest: request.CapitaliseInterest,
+		Reason:             request.Reason,
+	})
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, holiday)
+}
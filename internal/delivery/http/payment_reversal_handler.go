@@ -0,0 +1,50 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary Reverse a payment
+// @Description Undo a payment that bounced or was recalled: the installments and late fees it settled are owed
+// @Description again, the outstanding is restored and the delinquency of the loan is recomputed. A paid off loan
+// @Description is reopened. The payment is kept in the history with the reason of the reversal.
+// @ID reverse-payment
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param paymentId path int true "Payment ID"
+// @Param Idempotency-Key header string false "Replays the first response for retries with the same key"
+// @Param reason body string true "Why the payment is reversed"
+// @Success 200 {object} domain.Payment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/payments/{paymentId}/reverse [post]
+func (lh *LoanHandler) ReversePayment(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+	paymentID, err := strconv.Atoi(c.Param("paymentId"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid payment ID"})
+	}
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	payment, err := lh.lu.ReversePayment(ctx, uint(id), uint(paymentID), request.Reason)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, payment)
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"billing-engine/internal/domain"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// @Summary Write off a loan
+// @Description Write off what is left of an active or delinquent loan: its unpaid installments and late fees.
+// @Description The loan owes nothing afterwards and stops accruing interest, late fees and arrears.
+// @ID write-off-loan
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param reason body string false "Why the loan is written off"
+// @Success 200 {object} domain.LoanWriteOff
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/write-off [post]
+func (lh *LoanHandler) WriteOffLoan(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+	var request struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	writeOff, err := lh.lu.WriteOffLoan(ctx, uint(id), request.Reason)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, writeOff)
+}
+
+// @Summary Get the write-off of a loan
+// @Description Get what was written off on the loan and how much of it was recovered since
+// @ID get-write-off
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} domain.LoanWriteOff
+// @Failure 404 {object} map[string]string
+// @Router /loans/{id}/write-off [get]
+func (lh *LoanHandler) GetWriteOff(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+
+	writeOff, err := lh.lu.GetWriteOff(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, writeOff)
+}
+
+// @Summary Record a recovery
+// @Description Record money collected on a written-off loan, up to what was written off and not recovered yet.
+// @Description Recoveries settle no installment and are reported apart from payments.
+// @ID record-recovery
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param Idempotency-Key header string false "Replays the first response for retries with the same key"
+// @Param amount body string true "Recovered amount, as a decimal string"
+// @Success 201 {object} domain.Recovery
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /loans/{id}/recoveries [post]
+func (lh *LoanHandler) RecordRecovery(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+	var request struct {
+		Amount domain.Money `json:"amount"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid request"})
+	}
+
+	recovery, err := lh.lu.RecordRecovery(ctx, uint(id), request.Amount)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusCreated, recovery)
+}
+
+// @Summary Get the recoveries of a loan
+// @Description Get the money collected on a written-off loan, oldest first
+// @ID get-recoveries
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {array} domain.Recovery
+// @Failure 404 {object} map[string]string
+// @Router /loans/{id}/recoveries [get]
+func (lh *LoanHandler) GetRecoveries(c echo.Context) error {
+	ctx := c.Request().Context()
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid loan ID"})
+	}
+
+	recoveries, err := lh.lu.GetRecoveries(ctx, uint(id))
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, recoveries)
+}
+
+// @Summary Get the write-off report
+// @Description List the loans written off and the recoveries collected on written-off loans from from to to,
+// @Description both included, with their totals
+// @ID get-write-off-report
+// @Produce json
+// @Param from query string false "First day as YYYY-MM-DD, defaults to the first day of the month of to"
+// @Param to query string false "Last day as YYYY-MM-DD, defaults to today"
+// @Success 200 {object} domain.WriteOffReport
+// @Failure 400 {object} map[string]string
+// @Router /reports/write-offs [get]
+func (lh *LedgerHandler) GetWriteOffReport(c echo.Context) error {
+	ctx := c.Request().Context()
+	from, to, err := reportPeriod(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	report, err := lh.lu.GetWriteOffReport(ctx, from, to)
+	if err != nil {
+		return c.JSON(errorStatus(err), map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// AccrualMethod is how the interest of a loan is earned over the days of its schedule.
+type AccrualMethod string
+
+const (
+	// AccrualStraightLine earns the interest of every installment evenly over the days of its period.
+	AccrualStraightLine AccrualMethod = "straight_line"
+	// AccrualEffectiveInterest earns the constant rate per period at which the installments repay the principal,
+	// charged on the balance still owed, so flat interest is earned mostly early in the term. Within a period
+	// the interest is earned evenly by day.
+	AccrualEffectiveInterest AccrualMethod = "effective_interest"
+)
+
+// ParseAccrualMethod returns the method named s, straight line when s is empty.
+func ParseAccrualMethod(s string) (AccrualMethod, error) {
+	switch method := AccrualMethod(strings.ToLower(strings.TrimSpace(s))); method {
+	case "":
+		return AccrualStraightLine, nil
+	case AccrualStraightLine, AccrualEffectiveInterest:
+		return method, nil
+	}
+	return AccrualStraightLine, fmt.Errorf("unknown interest accrual method %q", s)
+}
+
+// InterestAccrual is the interest earned on a loan by one accrual run, AccruedToDate is the interest earned on the
+// current installments of the loan up to AccrualDate. Amount is negative when rescheduling moved interest later.
+type InterestAccrual struct {
+	ID            uint
+	LoanID        uint
+	AccrualDate   pgtype.Date
+	Method        AccrualMethod
+	Amount        Money
+	AccruedToDate Money
+}
+
+// InterestAccrualTotal is the interest earned on all loans on one day.
+type InterestAccrualTotal struct {
+	AccrualDate pgtype.Date
+	Loans       int
+	Amount      Money
+}
+
+// InterestAccrualReport sums the interest earned day by day from From to To, both included.
+type InterestAccrualReport struct {
+	From  pgtype.Date
+	To    pgtype.Date
+	Days  []InterestAccrualTotal
+	Total Money
+}
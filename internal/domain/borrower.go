@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrBorrowerNotFound   = errors.New("borrower not found")
+	ErrBorrowerEmailTaken = errors.New("borrower email already registered")
+	ErrInvalidBorrower    = errors.New("invalid borrower")
+	// ErrBorrowerHasOpenLoans is returned when deleting a borrower whose loans are not all closed.
+	ErrBorrowerHasOpenLoans = errors.New("borrower has open loans")
+)
+
+type Borrower struct {
+	ID        uint
+	Name      string
+	Email     string
+	Phone     string
+	CreatedAt pgtype.Timestamp
+	UpdatedAt pgtype.Timestamp
+}
+
+// BorrowerUpdate carries the fields of a partial update, nil fields are left untouched.
+type BorrowerUpdate struct {
+	Name  *string
+	Email *string
+	Phone *string
+}
+
+type BorrowerRepository interface {
+	CreateBorrower(ctx context.Context, borrower *Borrower) (*Borrower, error)
+	GetBorrowerByID(ctx context.Context, borrowerID uint) (*Borrower, error)
+	ListBorrowers(ctx context.Context, limit, offset uint) ([]Borrower, error)
+	UpdateBorrower(ctx context.Context, borrowerID uint, update *BorrowerUpdate) (*Borrower, error)
+	// DeleteBorrower soft deletes the borrower, or returns ErrBorrowerHasOpenLoans while one of its loans is not closed.
+	DeleteBorrower(ctx context.Context, borrowerID uint) error
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Holiday is a public holiday of a region, no installment falls due on it once dates are adjusted.
+type Holiday struct {
+	Region string
+	Date   time.Time
+	Name   string
+}
+
+type HolidayRepository interface {
+	// ListHolidays returns the holidays of the region by date.
+	ListHolidays(ctx context.Context, region string) ([]Holiday, error)
+}
+
+// BusinessDayConvention is how a date falling on a weekend or holiday is moved to a business day.
+type BusinessDayConvention string
+
+const (
+	// ConventionNone keeps dates as they are.
+	ConventionNone BusinessDayConvention = "none"
+	// ConventionFollowing moves dates to the next business day.
+	ConventionFollowing BusinessDayConvention = "following"
+	// ConventionPreceding moves dates to the previous business day.
+	ConventionPreceding BusinessDayConvention = "preceding"
+	// ConventionModifiedFollowing moves dates to the next business day, or to the previous one when the next
+	// is in the following month.
+	ConventionModifiedFollowing BusinessDayConvention = "modified_following"
+)
+
+// maxAdjustDays bounds the search for a business day, a calendar without one for a year is misconfigured.
+const maxAdjustDays = 366
+
+func ParseBusinessDayConvention(s string) (BusinessDayConvention, error) {
+	switch convention := BusinessDayConvention(strings.ToLower(strings.TrimSpace(s))); convention {
+	case "":
+		return ConventionNone, nil
+	case ConventionNone, ConventionFollowing, ConventionPreceding, ConventionModifiedFollowing:
+		return convention, nil
+	}
+	return ConventionNone, fmt.Errorf("unknown business day convention %q", s)
+}
+
+// BusinessCalendar tells business days from weekends and holidays.
+type BusinessCalendar struct {
+	weekend  map[time.Weekday]bool
+	holidays map[time.Time]string
+}
+
+// NewBusinessCalendar returns a calendar closed on the given holidays and weekend days, Saturday and Sunday
+// when no weekend day is given.
+func NewBusinessCalendar(holidays []Holiday, weekend ...time.Weekday) *BusinessCalendar {
+	if len(weekend) == 0 {
+		weekend = []time.Weekday{time.Saturday, time.Sunday}
+	}
+	c := &BusinessCalendar{
+		weekend:  make(map[time.Weekday]bool, len(weekend)),
+		holidays: make(map[time.Time]string, len(holidays)),
+	}
+	for _, day := range weekend {
+		c.weekend[day] = true
+	}
+	for _, holiday := range holidays {
+		c.holidays[dateOf(holiday.Date)] = holiday.Name
+	}
+	return c
+}
+
+// IsBusinessDay reports whether the day of t is neither a weekend day nor a holiday.
+func (c *BusinessCalendar) IsBusinessDay(t time.Time) bool {
+	if c.weekend[t.Weekday()] {
+		return false
+	}
+	_, holiday := c.holidays[dateOf(t)]
+	return !holiday
+}
+
+// Adjust moves t to a business day according to the convention, keeping its time of day.
+func (c *BusinessCalendar) Adjust(t time.Time, convention BusinessDayConvention) time.Time {
+	switch convention {
+	case ConventionFollowing:
+		return c.step(t, 1)
+	case ConventionPreceding:
+		return c.step(t, -1)
+	case ConventionModifiedFollowing:
+		if following := c.step(t, 1); following.Month() == t.Month() {
+			return following
+		}
+		return c.step(t, -1)
+	}
+	return t
+}
+
+func (c *BusinessCalendar) step(t time.Time, days int) time.Time {
+	for i := 0; i < maxAdjustDays && !c.IsBusinessDay(t); i++ {
+		t = t.AddDate(0, 0, days)
+	}
+	return t
+}
+
+// ArrearsCutoff returns the time before which an unpaid installment is in arrears on asOf, once its due date
+// is adjusted by the convention. It is asOf itself on a business day with nothing to adjust, so installments due
+// on a weekend or holiday only fall into arrears when the borrower had a business day to pay them.
+func (c *BusinessCalendar) ArrearsCutoff(asOf time.Time, convention BusinessDayConvention) time.Time {
+	today := dateOf(asOf)
+	// the adjustment keeps the order of dates, so the installments in arrears are the ones due before the
+	// first day whose adjusted date is after today
+	cutoff := today.AddDate(0, 0, 1)
+	for i := 0; i < maxAdjustDays && !c.Adjust(cutoff, convention).After(today); i++ {
+		cutoff = cutoff.AddDate(0, 0, 1)
+	}
+	for i := 0; i < maxAdjustDays && c.Adjust(cutoff.AddDate(0, 0, -1), convention).After(today); i++ {
+		cutoff = cutoff.AddDate(0, 0, -1)
+	}
+	if cutoff.Equal(today.AddDate(0, 0, 1)) {
+		return asOf
+	}
+	return time.Date(cutoff.Year(), cutoff.Month(), cutoff.Day(), 0, 0, 0, 0, asOf.Location())
+}
+
+// dateOf returns the day of t as midnight UTC, the key holidays are stored under.
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
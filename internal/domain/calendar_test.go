@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func day(s string) time.Time {
+	d, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func TestBusinessCalendarAdjust(t *testing.T) {
+	// 2024-05-31 is a Friday holiday, 2024-06-01 and 2024-06-02 are a weekend
+	calendar := NewBusinessCalendar([]Holiday{
+		{Region: "ID", Date: day("2024-05-31"), Name: "Month End Holiday"},
+		{Region: "ID", Date: day("2024-08-16"), Name: "Bridge Holiday"},
+	})
+
+	tests := map[string]struct {
+		date       string
+		convention BusinessDayConvention
+		want       string
+	}{
+		"business day":                        {"2024-05-29", ConventionFollowing, "2024-05-29"},
+		"none":                                {"2024-06-01", ConventionNone, "2024-06-01"},
+		"following weekend":                   {"2024-06-01", ConventionFollowing, "2024-06-03"},
+		"following holiday and weekend":       {"2024-08-16", ConventionFollowing, "2024-08-19"},
+		"preceding weekend":                   {"2024-06-02", ConventionPreceding, "2024-05-30"},
+		"modified following in the month":     {"2024-08-16", ConventionModifiedFollowing, "2024-08-19"},
+		"modified following across month end": {"2024-05-31", ConventionModifiedFollowing, "2024-05-30"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, calendar.Adjust(day(tt.date), tt.convention).Format(time.DateOnly))
+		})
+	}
+}
+
+func TestBusinessCalendarArrearsCutoff(t *testing.T) {
+	calendar := NewBusinessCalendar([]Holiday{{Region: "ID", Date: day("2024-05-31"), Name: "Month End Holiday"}})
+	at := func(s string) time.Time { return day(s).Add(10 * time.Hour) }
+
+	// on a business day nothing changes
+	assert.Equal(t, at("2024-05-29"), calendar.ArrearsCutoff(at("2024-05-29"), ConventionFollowing))
+	// on Saturday the installments due since Thursday, the last business day, are not in arrears yet
+	assert.Equal(t, day("2024-05-31"), calendar.ArrearsCutoff(at("2024-06-01"), ConventionFollowing))
+	// after the weekend the installments due on the holiday and the weekend are in arrears on Monday
+	assert.Equal(t, at("2024-06-03"), calendar.ArrearsCutoff(at("2024-06-03"), ConventionFollowing))
+	// the holiday installment moves back to Thursday, so it is in arrears during the weekend
+	assert.Equal(t, day("2024-06-01"), calendar.ArrearsCutoff(at("2024-06-01"), ConventionModifiedFollowing))
+	// on Saturday the installments due over the weekend should already have been paid on Thursday
+	assert.Equal(t, day("2024-06-03"), calendar.ArrearsCutoff(at("2024-06-01"), ConventionPreceding))
+	assert.Equal(t, at("2024-06-01"), calendar.ArrearsCutoff(at("2024-06-01"), ConventionNone))
+}
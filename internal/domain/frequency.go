@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// RepaymentFrequency is how often the installments of a loan fall due.
+type RepaymentFrequency string
+
+const (
+	FrequencyDaily    RepaymentFrequency = "daily"
+	FrequencyWeekly   RepaymentFrequency = "weekly"
+	FrequencyBiWeekly RepaymentFrequency = "bi_weekly"
+	// FrequencySemiMonthly falls due twice a month, on the day of the start date and fifteen days later.
+	FrequencySemiMonthly RepaymentFrequency = "semi_monthly"
+	FrequencyMonthly     RepaymentFrequency = "monthly"
+)
+
+// ParseRepaymentFrequency returns the frequency named s, weekly when s is empty.
+func ParseRepaymentFrequency(s string) (RepaymentFrequency, error) {
+	switch frequency := RepaymentFrequency(s); frequency {
+	case "":
+		return FrequencyWeekly, nil
+	case FrequencyDaily, FrequencyWeekly, FrequencyBiWeekly, FrequencySemiMonthly, FrequencyMonthly:
+		return frequency, nil
+	}
+	return "", fmt.Errorf("%w: unknown repayment frequency %q", ErrInvalidLoanProduct, s)
+}
+
+// PeriodsPerYear converts the yearly rate of declining balance methods to a rate per installment.
+func (f RepaymentFrequency) PeriodsPerYear() int64 {
+	switch f {
+	case FrequencyDaily:
+		return 365
+	case FrequencyBiWeekly:
+		return 26
+	case FrequencySemiMonthly:
+		return 24
+	case FrequencyMonthly:
+		return 12
+	}
+	return 52
+}
+
+// DueDate returns when the given installment period of a schedule starting on start falls due, period 1 being the first.
+// Monthly dates keep the day of month of start, moved back to the last day of shorter months, and a schedule
+// starting on the last day of a month stays on month ends. Semi-monthly dates alternate between fifteen days
+// after start and the day of start, following the same rule.
+func (f RepaymentFrequency) DueDate(start time.Time, period int) time.Time {
+	switch f {
+	case FrequencyDaily:
+		return start.AddDate(0, 0, period)
+	case FrequencyBiWeekly:
+		return start.AddDate(0, 0, 14*period)
+	case FrequencySemiMonthly:
+		if period%2 == 1 {
+			return addMonths(start.AddDate(0, 0, 15), period/2)
+		}
+		return addMonths(start, period/2)
+	case FrequencyMonthly:
+		return addMonths(start, period)
+	}
+	return start.AddDate(0, 0, 7*period)
+}
+
+// addMonths moves t by months without overflowing into the following month like time.AddDate does,
+// the 31st of January plus one month is the last day of February rather than early March.
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	target := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := daysInMonth(target); day > last || day == daysInMonth(t) {
+		day = last
+	}
+	return target.AddDate(0, 0, day-1)
+}
+
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
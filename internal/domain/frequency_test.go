@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepaymentFrequencyDueDate(t *testing.T) {
+	date := func(s string) time.Time {
+		d, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := map[string]struct {
+		frequency RepaymentFrequency
+		start     string
+		want      []string
+	}{
+		"daily":                       {FrequencyDaily, "2024-02-27", []string{"2024-02-28", "2024-02-29", "2024-03-01"}},
+		"weekly":                      {FrequencyWeekly, "2024-05-01", []string{"2024-05-08", "2024-05-15", "2024-05-22"}},
+		"bi-weekly":                   {FrequencyBiWeekly, "2024-05-01", []string{"2024-05-15", "2024-05-29", "2024-06-12"}},
+		"monthly":                     {FrequencyMonthly, "2024-01-15", []string{"2024-02-15", "2024-03-15", "2024-04-15"}},
+		"monthly past short months":   {FrequencyMonthly, "2024-01-30", []string{"2024-02-29", "2024-03-30", "2024-04-30", "2024-05-30"}},
+		"monthly from month end":      {FrequencyMonthly, "2024-01-31", []string{"2024-02-29", "2024-03-31", "2024-04-30", "2024-05-31"}},
+		"monthly from short month":    {FrequencyMonthly, "2023-02-28", []string{"2023-03-31", "2023-04-30", "2023-05-31"}},
+		"monthly over a year":         {FrequencyMonthly, "2024-11-30", []string{"2024-12-31", "2025-01-31", "2025-02-28"}},
+		"semi-monthly":                {FrequencySemiMonthly, "2024-01-01", []string{"2024-01-16", "2024-02-01", "2024-02-16", "2024-03-01"}},
+		"semi-monthly past month end": {FrequencySemiMonthly, "2024-01-15", []string{"2024-01-30", "2024-02-15", "2024-02-29", "2024-03-15", "2024-03-30"}},
+		"semi-monthly from month end": {FrequencySemiMonthly, "2024-01-31", []string{"2024-02-15", "2024-02-29", "2024-03-15", "2024-03-31"}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for i, want := range tt.want {
+				got := tt.frequency.DueDate(date(tt.start), i+1)
+				assert.Equal(t, want, got.Format(time.DateOnly), "period %d", i+1)
+			}
+		})
+	}
+}
+
+func TestParseRepaymentFrequency(t *testing.T) {
+	frequency, err := ParseRepaymentFrequency("")
+	assert.NoError(t, err)
+	assert.Equal(t, FrequencyWeekly, frequency)
+
+	frequency, err = ParseRepaymentFrequency("semi_monthly")
+	assert.NoError(t, err)
+	assert.Equal(t, FrequencySemiMonthly, frequency)
+	assert.EqualValues(t, 24, frequency.PeriodsPerYear())
+
+	_, err = ParseRepaymentFrequency("quarterly")
+	assert.ErrorIs(t, err, ErrInvalidLoanProduct)
+}
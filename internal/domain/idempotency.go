@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"context"
+)
+
+// IdempotencyRecord is the request fingerprint and, once the request completed, the response stored for a key.
+type IdempotencyRecord struct {
+	Key            string
+	RequestHash    string
+	ResponseStatus int
+	ResponseBody   []byte
+	Completed      bool
+}
+
+type IdempotencyRepository interface {
+	// Reserve claims key for a new request. When the key was already used it returns the existing record and false,
+	// unless it is a reservation for the same request that outlived its lease, which is claimed again.
+	Reserve(ctx context.Context, key, requestHash string) (*IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, key string, status int, body []byte) error
+	// Release frees a reservation whose request did not complete, so the client can retry with the same key.
+	Release(ctx context.Context, key string) error
+}
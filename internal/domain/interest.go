@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InterestMethod is how the interest of a loan is computed and spread over its installments.
+type InterestMethod string
+
+const (
+	// InterestFlat charges the rate once on the principal for the whole term, every installment is the same.
+	InterestFlat InterestMethod = "flat"
+	// InterestAnnuity charges the yearly rate on the declining balance with equal installments.
+	InterestAnnuity InterestMethod = "annuity"
+	// InterestEqualPrincipal charges the yearly rate on the declining balance and repays the same principal every period,
+	// so installments decrease over the term.
+	InterestEqualPrincipal InterestMethod = "equal_principal"
+)
+
+func ParseInterestMethod(s string) (InterestMethod, error) {
+	switch method := InterestMethod(strings.ToLower(strings.TrimSpace(s))); method {
+	case "":
+		return InterestFlat, nil
+	case InterestFlat, InterestAnnuity, InterestEqualPrincipal:
+		return method, nil
+	}
+	return InterestFlat, fmt.Errorf("%w: unknown interest method %q", ErrInvalidLoan, s)
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+// JobStatus is the state of one run of a background job.
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// JobRun is what every run of a background job over the loans records.
+// A run is failed when the loans could not be listed or at least one loan could not be processed.
+type JobRun struct {
+	Status       JobStatus
+	AsOf         time.Time
+	StartedAt    time.Time
+	FinishedAt   *time.Time
+	LoansChecked int
+	Failures     int
+	Error        string
+}
+
+// Job returns the part of a run shared by every job.
+func (r *JobRun) Job() *JobRun {
+	return r
+}
+
+// DelinquencyRun summarises one run of the job that refreshes delinquent weeks and statuses.
+type DelinquencyRun struct {
+	JobRun
+	LoansUpdated  int
+	StatusChanges int
+}
+
+// AccrualRun summarises one run of the job that earns the interest accrued on loans.
+type AccrualRun struct {
+	JobRun
+	LoansAccrued    int
+	InterestAccrued Money
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+// LateFee is charged once for a billing schedule period that was not paid in time.
+// Payments settle unpaid late fees before any installment.
+type LateFee struct {
+	ID                uint
+	LoanID            uint
+	BillingScheduleID uint
+	Period            uint
+	Amount            Money
+	PaidAmount        Money
+	ChargedAt         pgtype.Timestamp
+}
+
+// Remaining returns the part of the fee that has not been paid yet.
+func (f LateFee) Remaining() Money {
+	return f.Amount.Sub(f.PaidAmount)
+}
+
+// Outstanding splits what is owed on a loan between its installments and its unpaid late fees,
+// Components breaks the unpaid installments down into principal, interest and installment fees.
+type Outstanding struct {
+	Installments Money
+	Components   Components
+	LateFees     Money
+	Total        Money
+}
@@ -0,0 +1,179 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrUnbalancedEntry = errors.New("journal entry does not balance")
+
+// LedgerAccount is the code of an account of the chart the loan book posts to.
+type LedgerAccount string
+
+const (
+	AccountCash               LedgerAccount = "cash"
+	AccountLoansReceivable    LedgerAccount = "loans_receivable"
+	AccountInterestReceivable LedgerAccount = "interest_receivable"
+	// AccountFeesReceivable holds the installment, origination and late fees owed.
+	AccountFeesReceivable LedgerAccount = "fees_receivable"
+	// AccountUnearnedIncome holds the scheduled interest and installment fees until they are earned.
+	AccountUnearnedIncome  LedgerAccount = "unearned_income"
+	AccountInterestIncome  LedgerAccount = "interest_income"
+	AccountFeeIncome       LedgerAccount = "fee_income"
+	AccountWriteOffExpense LedgerAccount = "write_off_expense"
+	// AccountRecoveryIncome holds what was collected on loans after they were written off.
+	AccountRecoveryIncome LedgerAccount = "recovery_income"
+)
+
+// ReceivableAccounts are the accounts holding what borrowers owe on a loan.
+var ReceivableAccounts = []LedgerAccount{AccountLoansReceivable, AccountInterestReceivable, AccountFeesReceivable}
+
+// JournalEntryKind is the money movement a journal entry records.
+type JournalEntryKind string
+
+const (
+	JournalDisbursement JournalEntryKind = "disbursement"
+	JournalCollection   JournalEntryKind = "collection"
+	// JournalAccrual moves interest and installment fees from unearned to income once they are earned.
+	JournalAccrual        JournalEntryKind = "accrual"
+	JournalRebate         JournalEntryKind = "rebate"
+	JournalFeeCharge      JournalEntryKind = "fee_charge"
+	JournalRestructure    JournalEntryKind = "restructure"
+	JournalPaymentHoliday JournalEntryKind = "payment_holiday"
+	JournalReversal       JournalEntryKind = "reversal"
+	JournalWriteOff       JournalEntryKind = "write_off"
+	JournalRecovery       JournalEntryKind = "recovery"
+)
+
+// JournalEntry is one money movement of a loan, its postings debit and credit the same total.
+// PaymentID is set for the entries recorded by a payment, ReversesEntryID for the entry undoing another one.
+type JournalEntry struct {
+	ID              uint
+	LoanID          uint
+	PaymentID       uint
+	Kind            JournalEntryKind
+	Description     string
+	PostedAt        pgtype.Timestamp
+	ReversesEntryID uint
+	Postings        []Posting
+}
+
+// Posting is the debit or the credit of one account by a journal entry.
+type Posting struct {
+	Account LedgerAccount
+	Debit   Money
+	Credit  Money
+}
+
+// Debit adds a debit of amount to account, a negative amount credits it and zero is skipped.
+func (e *JournalEntry) Debit(account LedgerAccount, amount Money) *JournalEntry {
+	switch amount.Sign() {
+	case 1:
+		e.Postings = append(e.Postings, Posting{Account: account, Debit: amount})
+	case -1:
+		e.Postings = append(e.Postings, Posting{Account: account, Credit: amount.Neg()})
+	}
+	return e
+}
+
+// Credit adds a credit of amount to account, a negative amount debits it and zero is skipped.
+func (e *JournalEntry) Credit(account LedgerAccount, amount Money) *JournalEntry {
+	return e.Debit(account, amount.Neg())
+}
+
+// Totals returns the sum of the debits and of the credits of the entry.
+func (e *JournalEntry) Totals() (debit, credit Money) {
+	for _, posting := range e.Postings {
+		debit = debit.Add(posting.Debit)
+		credit = credit.Add(posting.Credit)
+	}
+	return debit, credit
+}
+
+// Validate rejects entries without postings and entries whose debits and credits differ.
+func (e *JournalEntry) Validate() error {
+	if len(e.Postings) == 0 {
+		return fmt.Errorf("%w: %s entry has no postings", ErrUnbalancedEntry, e.Kind)
+	}
+	if debit, credit := e.Totals(); !debit.Equal(credit) {
+		return fmt.Errorf("%w: %s entry debits %s and credits %s", ErrUnbalancedEntry, e.Kind, debit, credit)
+	}
+	return nil
+}
+
+// Reversal returns the entry undoing e, every debit of e becomes a credit and the other way round.
+func (e *JournalEntry) Reversal(description string) *JournalEntry {
+	reversal := &JournalEntry{
+		LoanID:          e.LoanID,
+		PaymentID:       e.PaymentID,
+		Kind:            JournalReversal,
+		Description:     description,
+		ReversesEntryID: e.ID,
+	}
+	for _, posting := range e.Postings {
+		reversal.Postings = append(reversal.Postings, Posting{Account: posting.Account, Debit: posting.Credit, Credit: posting.Debit})
+	}
+	return reversal
+}
+
+// LedgerBalances returns the debits less the credits posted to every account by entries.
+func LedgerBalances(entries []JournalEntry) map[LedgerAccount]Money {
+	balances := make(map[LedgerAccount]Money)
+	for _, entry := range entries {
+		for _, posting := range entry.Postings {
+			balances[posting.Account] = balances[posting.Account].Add(posting.Debit).Sub(posting.Credit)
+		}
+	}
+	return balances
+}
+
+// AccountType decides on which side an account's balance normally is.
+type AccountType string
+
+const (
+	AccountAsset     AccountType = "asset"
+	AccountLiability AccountType = "liability"
+	AccountIncome    AccountType = "income"
+	AccountExpense   AccountType = "expense"
+)
+
+// DebitNormal reports whether debits increase accounts of the type.
+func (t AccountType) DebitNormal() bool {
+	return t == AccountAsset || t == AccountExpense
+}
+
+// TrialBalanceAccount is what was posted to one account up to the date of a trial balance.
+// Balance is the difference on the account's normal side, debits less credits for assets and expenses.
+type TrialBalanceAccount struct {
+	Code    LedgerAccount
+	Number  string
+	Name    string
+	Type    AccountType
+	Debit   Money
+	Credit  Money
+	Balance Money
+}
+
+// TrialBalance lists every account of the ledger as of AsOf, it is balanced when the debits equal the credits.
+type TrialBalance struct {
+	AsOf        time.Time
+	Accounts    []TrialBalanceAccount
+	TotalDebit  Money
+	TotalCredit Money
+	Balanced    bool
+}
+
+type LedgerRepository interface {
+	// GetTrialBalance sums the postings of every account made up to asOf, accounts without postings included.
+	GetTrialBalance(ctx context.Context, asOf time.Time) ([]TrialBalanceAccount, error)
+	// GetInterestAccrualTotals sums the interest accrued on all loans by day from the day of from to the day of to.
+	GetInterestAccrualTotals(ctx context.Context, from, to time.Time) ([]InterestAccrualTotal, error)
+	// GetWriteOffs returns the loans written off from from up to but excluding to, oldest first.
+	GetWriteOffs(ctx context.Context, from, to time.Time) ([]LoanWriteOff, error)
+	// GetRecoveries returns the recoveries collected on all loans from from up to but excluding to, oldest first.
+	GetRecoveries(ctx context.Context, from, to time.Time) ([]Recovery, error)
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalEntryValidate(t *testing.T) {
+	entry := &JournalEntry{Kind: JournalCollection}
+	assert.ErrorIs(t, entry.Validate(), ErrUnbalancedEntry)
+
+	entry.Debit(AccountCash, NewMoneyFromUnits(100)).Credit(AccountLoansReceivable, NewMoneyFromUnits(90))
+	assert.ErrorIs(t, entry.Validate(), ErrUnbalancedEntry)
+
+	// a negative credit debits the account, zero amounts are not posted
+	entry.Credit(AccountInterestReceivable, NewMoneyFromUnits(-10)).Credit(AccountFeesReceivable, Money{})
+	assert.Len(t, entry.Postings, 3)
+	assert.Equal(t, Posting{Account: AccountInterestReceivable, Debit: NewMoneyFromUnits(10)}, entry.Postings[2])
+	assert.ErrorIs(t, entry.Validate(), ErrUnbalancedEntry)
+
+	entry.Credit(AccountInterestIncome, NewMoneyFromUnits(20))
+	assert.NoError(t, entry.Validate())
+}
+
+func TestJournalEntryReversal(t *testing.T) {
+	entry := &JournalEntry{ID: 4, LoanID: 1, PaymentID: 7, Kind: JournalCollection}
+	entry.Debit(AccountCash, NewMoneyFromUnits(100)).Credit(AccountLoansReceivable, NewMoneyFromUnits(100))
+
+	reversal := entry.Reversal("cheque bounced")
+
+	assert.NoError(t, reversal.Validate())
+	assert.Equal(t, JournalReversal, reversal.Kind)
+	assert.Equal(t, uint(4), reversal.ReversesEntryID)
+	balances := LedgerBalances([]JournalEntry{*entry, *reversal})
+	assert.True(t, balances[AccountCash].IsZero())
+	assert.True(t, balances[AccountLoansReceivable].IsZero())
+}
@@ -2,54 +2,211 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+var (
+	ErrLoanNotFound = errors.New("loan not found")
+	ErrInvalidLoan  = errors.New("invalid loan")
+)
+
+// DelinquentAfterWeeks is the number of overdue installments that makes a loan delinquent.
+const DelinquentAfterWeeks = 2
+
+// Loan is repaid in TenorPeriods installments falling due every RepaymentFrequency.
 type Loan struct {
-	ID                uint
-	Amount            pgtype.Numeric
-	InterestRate      pgtype.Numeric
-	DurationWeeks     int
-	Outstanding       pgtype.Numeric
-	DelinquentWeeks   int
-	InstallmentAmount pgtype.Numeric
+	ID                 uint
+	Amount             Money
+	InterestRate       pgtype.Numeric
+	InterestMethod     InterestMethod
+	TenorPeriods       int
+	RepaymentFrequency RepaymentFrequency
+	Outstanding        Money
+	DelinquentWeeks    int
+	InstallmentAmount  Money
+	Status             LoanStatus
+	ClosedAt           pgtype.Timestamp
+	// ProductID is zero for loans booked before the product catalogue existed.
+	ProductID uint
+	// DisbursementDate is when the schedule starts, interest of the first installment runs from it.
+	DisbursementDate pgtype.Date
+	// AccruedInterest is the interest earned on the current installments as of the last accrual run.
+	AccruedInterest Money
 }
 
 type LoanRepository interface {
+	// WithinTransaction runs fn as one unit of work, the repository passed to fn joins its transaction.
+	WithinTransaction(ctx context.Context, fn func(repo LoanRepository) error) error
 	GetLoanByID(ctx context.Context, loanID uint) (*Loan, error)
-	UpdateLoan(ctx context.Context, loan *Loan, schedule *BillingSchedule) error
+	// GetLoanByIDForUpdate locks the loan row until the surrounding transaction ends.
+	GetLoanByIDForUpdate(ctx context.Context, loanID uint) (*Loan, error)
+	// GetLoanBorrower returns the borrower of the loan, ErrLoanNotFound when there is no such loan.
+	GetLoanBorrower(ctx context.Context, loanID uint) (*LoanBorrower, error)
+	UpdateLoan(ctx context.Context, loan *Loan, schedules []BillingSchedule, payment *Payment) error
+	// TransitionLoanStatus moves the loan from transition.From to transition.To and appends it to the status history,
+	// it fails with ErrInvalidTransition when the loan is no longer in transition.From.
+	TransitionLoanStatus(ctx context.Context, transition *LoanStatusTransition) error
+	GetLoanStatusHistory(ctx context.Context, loanID uint) ([]LoanStatusTransition, error)
 	GetLoansWithBorrower(ctx context.Context, limit, offset uint) ([]LoanWithBorrower, error)
-	CreateLoan(ctx context.Context, borrowerID uint, loan *Loan) (uint, error)
+	GetLoansByBorrowerID(ctx context.Context, borrowerID uint) ([]Loan, error)
+	// ListLoanIDsByStatus pages through the loans in any of the given statuses by ascending ID, starting after afterID.
+	ListLoanIDsByStatus(ctx context.Context, statuses []LoanStatus, afterID uint, limit int) ([]uint, error)
+	UpdateDelinquentWeeks(ctx context.Context, loanID uint, weeks int) error
+	// CreateLoan stores the loan together with its billing schedule. It returns ErrBorrowerNotFound when the borrower
+	// does not exist or is deleted, and keeps the borrower from being deleted until the loan is stored.
+	CreateLoan(ctx context.Context, borrowerID uint, loan *Loan, schedules []BillingSchedule) (uint, error)
 	CreateBillingSchedule(ctx context.Context, schedule *BillingSchedule) error
 	UpdateBillingSchedule(ctx context.Context, schedule *BillingSchedule) error
 	GetBillingSchedule(ctx context.Context, loanId uint) (*BillingSchedule, error)
-	IsDelinquent(ctx context.Context, loanID uint) (*CheckDelinquentAmount, error)
-	UpdateRepaymentSchedule(ctx context.Context, loan *Loan) error
+	// GetUnpaidBillingSchedules returns the unpaid rows of the current schedule version by period.
+	GetUnpaidBillingSchedules(ctx context.Context, loanID uint) ([]BillingSchedule, error)
+	// GetBillingSchedules returns every schedule row of the loan including superseded ones, by version and period.
+	GetBillingSchedules(ctx context.Context, loanID uint) ([]BillingSchedule, error)
+	// RestructureLoan supersedes the unpaid schedule rows with restructure.Schedule, stores the new terms of the
+	// loan and records the restructure.
+	RestructureLoan(ctx context.Context, loan *Loan, restructure *LoanRestructure) error
+	// DeferBillingSchedules stores the new due dates and interest of holiday.Schedule, the new outstanding of the
+	// loan and records the payment holiday.
+	DeferBillingSchedules(ctx context.Context, loan *Loan, holiday *PaymentHoliday) error
+	// IsDelinquent sums the unpaid schedule rows that were due before asOf.
+	IsDelinquent(ctx context.Context, loanID uint, asOf time.Time) (*CheckDelinquentAmount, error)
+	GetPaymentsByLoanID(ctx context.Context, loanID uint) ([]Payment, error)
+	// ReversePayment stores the schedule rows restored by the reversal of payment, the new outstanding of the
+	// loan and marks the payment reversed, ErrPaymentAlreadyReversed when it already was.
+	ReversePayment(ctx context.Context, loan *Loan, schedules []BillingSchedule, payment *Payment) error
+	// GetLateFees returns every late fee charged on the loan, paid or not, by period.
+	GetLateFees(ctx context.Context, loanID uint) ([]LateFee, error)
+	CreateLateFee(ctx context.Context, fee *LateFee) error
+	// UpdateLateFee stores the paid amount of the fee.
+	UpdateLateFee(ctx context.Context, fee *LateFee) error
+	// PostJournalEntry records entry and its postings, they must balance.
+	PostJournalEntry(ctx context.Context, entry *JournalEntry) error
+	// GetJournalEntries returns every journal entry of the loan with its postings, oldest first.
+	GetJournalEntries(ctx context.Context, loanID uint) ([]JournalEntry, error)
+	// AccrueInterest stores the accrued interest of schedules and of the loan and adds accrual to the accruals of
+	// its day.
+	AccrueInterest(ctx context.Context, loan *Loan, schedules []BillingSchedule, accrual *InterestAccrual) error
+	// GetInterestAccruals returns the accruals of the loan by day.
+	GetInterestAccruals(ctx context.Context, loanID uint) ([]InterestAccrual, error)
+	// WriteOffLoan stores the outstanding of the loan, which the write-off cleared, and records writeOff.
+	WriteOffLoan(ctx context.Context, loan *Loan, writeOff *LoanWriteOff) error
+	// GetLoanWriteOff returns the write-off of the loan, ErrWriteOffNotFound when it was not written off.
+	GetLoanWriteOff(ctx context.Context, loanID uint) (*LoanWriteOff, error)
+	// RecordRecovery records recovery and stores the recovered amount of writeOff.
+	RecordRecovery(ctx context.Context, writeOff *LoanWriteOff, recovery *Recovery) error
+	// GetRecoveries returns the recoveries collected on the loan, oldest first.
+	GetRecoveries(ctx context.Context, loanID uint) ([]Recovery, error)
 }
 
 type LoanWithBorrower struct {
-	LoanID        uint
-	BorrowerID    uint
-	BorrowerName  string
-	Amount        pgtype.Numeric
-	InterestRate  pgtype.Numeric
-	DurationWeeks int
-	Outstanding   pgtype.Numeric
+	LoanID             uint
+	BorrowerID         uint
+	BorrowerName       string
+	Amount             Money
+	InterestRate       pgtype.Numeric
+	TenorPeriods       int
+	RepaymentFrequency RepaymentFrequency
+	Outstanding        Money
+	Status             LoanStatus
 }
 
+// BillingSchedule is one installment, Period numbers it from 1 in due date order.
+// Amount is the sum of its Principal, Interest and Fee.
+// A restructure supersedes the unpaid rows of the current Version with a new version, superseded rows have
+// SupersededAt set and are only kept for audit. Payment holidays move DueDate by DeferredPeriods periods in total,
+// OriginalDueDate is then the due date before the first of them. WaivedInterest is the part of the Interest an early
+// payoff rebated instead of collecting it. AccruedInterest is the part of the Interest earned by the accrual job so
+// far, whether it was paid or not.
 type BillingSchedule struct {
-	ID      uint
-	LoanID  uint
-	Week    uint
-	Amount  pgtype.Numeric
-	DueDate pgtype.Date
-	Paid    pgtype.Bool
+	ID              uint
+	LoanID          uint
+	Period          uint
+	Amount          Money
+	Principal       Money
+	Interest        Money
+	Fee             Money
+	DueDate         pgtype.Date
+	Paid            pgtype.Bool
+	PaidAmount      Money
+	Version         int
+	SupersededAt    pgtype.Timestamp
+	OriginalDueDate pgtype.Date
+	DeferredPeriods int
+	WaivedInterest  Money
+	AccruedInterest Money
+}
+
+// Remaining returns the part of the installment that has not been paid or waived yet.
+func (b BillingSchedule) Remaining() Money {
+	return b.Amount.Sub(b.PaidAmount).Sub(b.WaivedInterest)
+}
+
+// Components is an amount broken down into the principal, interest and fee it covers.
+type Components struct {
+	Principal Money
+	Interest  Money
+	Fee       Money
+}
+
+// Add returns the sum of both breakdowns.
+func (c Components) Add(other Components) Components {
+	return Components{
+		Principal: c.Principal.Add(other.Principal),
+		Interest:  c.Interest.Add(other.Interest),
+		Fee:       c.Fee.Add(other.Fee),
+	}
+}
+
+// Sub returns the difference of both breakdowns.
+func (c Components) Sub(other Components) Components {
+	return Components{
+		Principal: c.Principal.Sub(other.Principal),
+		Interest:  c.Interest.Sub(other.Interest),
+		Fee:       c.Fee.Sub(other.Fee),
+	}
+}
+
+// Split breaks the first paid of the installment into its components.
+// Payments settle the fee first, then the interest and the principal last.
+func (b BillingSchedule) Split(paid Money) Components {
+	take := func(part Money) Money {
+		if paid.Cmp(part) < 0 {
+			part = paid
+		}
+		if part.Sign() < 0 {
+			part = Money{}
+		}
+		paid = paid.Sub(part)
+		return part
+	}
+	fee := take(b.Fee)
+	interest := take(b.Interest)
+	return Components{Principal: take(b.Principal), Interest: interest, Fee: fee}
+}
+
+// PaidComponents returns the components already settled by the PaidAmount, the WaivedInterest was not paid.
+func (b BillingSchedule) PaidComponents() Components {
+	return b.settledComponents().Sub(Components{Interest: b.WaivedInterest})
+}
+
+// RemainingComponents returns the components still owed on the installment.
+func (b BillingSchedule) RemainingComponents() Components {
+	return b.Split(b.Amount).Sub(b.settledComponents())
+}
+
+// settledComponents returns the components settled by the PaidAmount and the WaivedInterest together.
+func (b BillingSchedule) settledComponents() Components {
+	return b.Split(b.PaidAmount.Add(b.WaivedInterest))
 }
 
+// CheckDelinquentAmount is the arrears of a loan, Amount includes the unpaid LateFees.
 type CheckDelinquentAmount struct {
 	LoanID       uint
 	TotalWeek    int
-	Amount       int64
+	Amount       Money
+	LateFees     Money
 	IsDelinquent bool
 }
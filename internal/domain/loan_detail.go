@@ -0,0 +1,48 @@
+package domain
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+// InstallmentState is where an installment of the current schedule stands today.
+type InstallmentState string
+
+const (
+	InstallmentPaid   InstallmentState = "paid"
+	InstallmentUnpaid InstallmentState = "unpaid"
+	// InstallmentOverdue is unpaid and was due before today, it counts towards the arrears.
+	InstallmentOverdue InstallmentState = "overdue"
+	// InstallmentWrittenOff was still unpaid when the loan was written off and is no longer owed.
+	InstallmentWrittenOff InstallmentState = "written_off"
+)
+
+// LoanBorrower is the borrower of a loan as shown with its details.
+type LoanBorrower struct {
+	ID    uint
+	Name  string
+	Email string
+	Phone string
+}
+
+// LoanInstallment is a row of the current schedule with what is left to pay on it and its State.
+type LoanInstallment struct {
+	BillingSchedule
+	Remaining Money
+	State     InstallmentState
+}
+
+// NextInstallment is the first unpaid installment that is not overdue yet, Amount is what is left to pay on it.
+type NextInstallment struct {
+	Period  uint
+	DueDate pgtype.Date
+	Amount  Money
+}
+
+// LoanDetail is everything needed to show a loan: its current schedule by due date, what is owed on it split into
+// principal, interest and fees, the next installment to fall due, nil when none is left, and its arrears.
+type LoanDetail struct {
+	Loan        Loan
+	Borrower    LoanBorrower
+	Schedule    []LoanInstallment
+	Outstanding Outstanding
+	NextDue     *NextInstallment
+	Arrears     CheckDelinquentAmount
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrLoanProductNotFound = errors.New("loan product not found")
+	ErrLoanProductTaken    = errors.New("loan product name already registered")
+	ErrInvalidLoanProduct  = errors.New("invalid loan product")
+)
+
+// LoanProduct defines the terms a loan can be booked with, so pricing is decided by the catalogue rather than the client.
+// InterestRate and OriginationFeeRate are fractions like Loan.InterestRate, the origination fee is charged on the
+// amount with the first installment and InstallmentFee on every installment.
+// TenorPeriods are counted in installments of the RepaymentFrequency.
+type LoanProduct struct {
+	ID                 uint
+	Name               string
+	MinAmount          Money
+	MaxAmount          Money
+	InterestRate       pgtype.Numeric
+	InterestMethod     InterestMethod
+	TenorPeriods       []int
+	RepaymentFrequency RepaymentFrequency
+	InstallmentFee     Money
+	OriginationFeeRate pgtype.Numeric
+	Active             bool
+	CreatedAt          pgtype.Timestamp
+	UpdatedAt          pgtype.Timestamp
+}
+
+// AllowsTenor reports whether the product offers loans repaid over the given number of periods.
+func (p LoanProduct) AllowsTenor(periods int) bool {
+	for _, tenor := range p.TenorPeriods {
+		if tenor == periods {
+			return true
+		}
+	}
+	return false
+}
+
+type LoanProductRepository interface {
+	CreateLoanProduct(ctx context.Context, product *LoanProduct) (*LoanProduct, error)
+	GetLoanProductByID(ctx context.Context, productID uint) (*LoanProduct, error)
+	ListLoanProducts(ctx context.Context) ([]LoanProduct, error)
+	// SetLoanProductActive opens or closes the product to new loans, loans already booked are not affected.
+	SetLoanProductActive(ctx context.Context, productID uint, active bool) error
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid loan status transition")
+	ErrLoanNotActive     = errors.New("loan is not active")
+)
+
+// LoanStatus is where a loan is in its lifecycle, it only changes through Transition.
+type LoanStatus string
+
+const (
+	LoanStatusPending    LoanStatus = "pending"
+	LoanStatusApproved   LoanStatus = "approved"
+	LoanStatusActive     LoanStatus = "active"
+	LoanStatusDelinquent LoanStatus = "delinquent"
+	LoanStatusPaidOff    LoanStatus = "paid_off"
+	LoanStatusWrittenOff LoanStatus = "written_off"
+	LoanStatusCancelled  LoanStatus = "cancelled"
+)
+
+// LoanEvent is something that happens to a loan and may move it to another status.
+type LoanEvent string
+
+const (
+	LoanEventApprove        LoanEvent = "approve"
+	LoanEventDisburse       LoanEvent = "disburse"
+	LoanEventMarkDelinquent LoanEvent = "mark_delinquent"
+	// LoanEventCure brings a delinquent loan back to active once its arrears are repaid.
+	LoanEventCure     LoanEvent = "cure"
+	LoanEventClose    LoanEvent = "close"
+	LoanEventWriteOff LoanEvent = "write_off"
+	LoanEventCancel   LoanEvent = "cancel"
+	// LoanEventReopen brings a paid off loan back to active when the payment that closed it is reversed.
+	LoanEventReopen LoanEvent = "reopen"
+)
+
+type loanTransition struct {
+	from []LoanStatus
+	to   LoanStatus
+}
+
+var loanTransitions = map[LoanEvent]loanTransition{
+	LoanEventApprove:        {from: []LoanStatus{LoanStatusPending}, to: LoanStatusApproved},
+	LoanEventDisburse:       {from: []LoanStatus{LoanStatusApproved}, to: LoanStatusActive},
+	LoanEventMarkDelinquent: {from: []LoanStatus{LoanStatusActive}, to: LoanStatusDelinquent},
+	LoanEventCure:           {from: []LoanStatus{LoanStatusDelinquent}, to: LoanStatusActive},
+	LoanEventClose:          {from: []LoanStatus{LoanStatusActive, LoanStatusDelinquent}, to: LoanStatusPaidOff},
+	LoanEventWriteOff:       {from: []LoanStatus{LoanStatusActive, LoanStatusDelinquent}, to: LoanStatusWrittenOff},
+	LoanEventCancel:         {from: []LoanStatus{LoanStatusPending, LoanStatusApproved}, to: LoanStatusCancelled},
+	LoanEventReopen:         {from: []LoanStatus{LoanStatusPaidOff}, to: LoanStatusActive},
+}
+
+// Transition returns the status the loan moves to when event happens, or ErrInvalidTransition
+// when the event is not allowed in the current status.
+func (s LoanStatus) Transition(event LoanEvent) (LoanStatus, error) {
+	transition, ok := loanTransitions[event]
+	if !ok {
+		return s, fmt.Errorf("%w: unknown event %q", ErrInvalidTransition, event)
+	}
+	for _, from := range transition.from {
+		if from == s {
+			return transition.to, nil
+		}
+	}
+	return s, fmt.Errorf("%w: cannot %s a loan that is %s", ErrInvalidTransition, event, s)
+}
+
+// AcceptsPayments reports whether payments can be taken, which is only the case once the loan is disbursed and until it is closed.
+func (s LoanStatus) AcceptsPayments() bool {
+	return s == LoanStatusActive || s == LoanStatusDelinquent
+}
+
+// ClosedLoanStatuses are the final statuses of a loan.
+var ClosedLoanStatuses = []LoanStatus{LoanStatusPaidOff, LoanStatusWrittenOff, LoanStatusCancelled}
+
+// IsClosed reports whether the loan reached a final status.
+func (s LoanStatus) IsClosed() bool {
+	return slices.Contains(ClosedLoanStatuses, s)
+}
+
+// LoanStatusTransition is one entry of a loan's status history.
+type LoanStatusTransition struct {
+	ID             uint
+	LoanID         uint
+	From           LoanStatus
+	To             LoanStatus
+	Event          LoanEvent
+	Reason         string
+	TransitionedAt pgtype.Timestamp
+}
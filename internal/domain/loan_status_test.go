@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoanStatusTransition(t *testing.T) {
+	tests := []struct {
+		from  LoanStatus
+		event LoanEvent
+		to    LoanStatus
+		ok    bool
+	}{
+		{LoanStatusPending, LoanEventApprove, LoanStatusApproved, true},
+		{LoanStatusApproved, LoanEventDisburse, LoanStatusActive, true},
+		{LoanStatusActive, LoanEventMarkDelinquent, LoanStatusDelinquent, true},
+		{LoanStatusDelinquent, LoanEventCure, LoanStatusActive, true},
+		{LoanStatusDelinquent, LoanEventClose, LoanStatusPaidOff, true},
+		{LoanStatusDelinquent, LoanEventWriteOff, LoanStatusWrittenOff, true},
+		{LoanStatusApproved, LoanEventCancel, LoanStatusCancelled, true},
+		{LoanStatusPaidOff, LoanEventReopen, LoanStatusActive, true},
+		{LoanStatusPending, LoanEventDisburse, LoanStatusPending, false},
+		{LoanStatusActive, LoanEventCancel, LoanStatusActive, false},
+		{LoanStatusPaidOff, LoanEventWriteOff, LoanStatusPaidOff, false},
+		{LoanStatusActive, LoanEventReopen, LoanStatusActive, false},
+		{LoanStatusWrittenOff, LoanEventReopen, LoanStatusWrittenOff, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"/"+string(tt.event), func(t *testing.T) {
+			to, err := tt.from.Transition(tt.event)
+			assert.Equal(t, tt.to, to)
+			if tt.ok {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidTransition)
+			}
+		})
+	}
+}
+
+func TestLoanStatusAcceptsPayments(t *testing.T) {
+	assert.True(t, LoanStatusActive.AcceptsPayments())
+	assert.True(t, LoanStatusDelinquent.AcceptsPayments())
+	assert.False(t, LoanStatusPending.AcceptsPayments())
+	assert.False(t, LoanStatusApproved.AcceptsPayments())
+	assert.False(t, LoanStatusPaidOff.AcceptsPayments())
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBillingScheduleSplit(t *testing.T) {
+	schedule := BillingSchedule{
+		Amount:     NewMoneyFromUnits(1150),
+		Principal:  NewMoneyFromUnits(1000),
+		Interest:   NewMoneyFromUnits(100),
+		Fee:        NewMoneyFromUnits(50),
+		PaidAmount: NewMoneyFromUnits(120),
+	}
+
+	paid := schedule.PaidComponents()
+	assert.Equal(t, "50.00", paid.Fee.String())
+	assert.Equal(t, "70.00", paid.Interest.String())
+	assert.True(t, paid.Principal.IsZero())
+
+	remaining := schedule.RemainingComponents()
+	assert.True(t, remaining.Fee.IsZero())
+	assert.Equal(t, "30.00", remaining.Interest.String())
+	assert.Equal(t, "1000.00", remaining.Principal.String())
+
+	all := schedule.Split(NewMoneyFromUnits(5000))
+	assert.Equal(t, "1000.00", all.Principal.String())
+	assert.Equal(t, "100.00", all.Interest.String())
+	assert.Equal(t, "50.00", all.Fee.String())
+}
+
+func TestBillingScheduleWaivedInterest(t *testing.T) {
+	schedule := BillingSchedule{
+		Amount:         NewMoneyFromUnits(1150),
+		Principal:      NewMoneyFromUnits(1000),
+		Interest:       NewMoneyFromUnits(100),
+		Fee:            NewMoneyFromUnits(50),
+		PaidAmount:     NewMoneyFromUnits(1090),
+		WaivedInterest: NewMoneyFromUnits(60),
+	}
+
+	assert.True(t, schedule.Remaining().IsZero())
+	remaining := schedule.RemainingComponents()
+	assert.True(t, remaining.Principal.IsZero())
+	assert.True(t, remaining.Interest.IsZero())
+	assert.True(t, remaining.Fee.IsZero())
+
+	paid := schedule.PaidComponents()
+	assert.Equal(t, "50.00", paid.Fee.String())
+	assert.Equal(t, "40.00", paid.Interest.String())
+	assert.Equal(t, "1000.00", paid.Principal.String())
+}
@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MoneyScale is the number of decimal places kept for every amount, matching the NUMERIC(15, 2) columns.
+const MoneyScale = 2
+
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// RoundingMode decides how a value that falls between two representable amounts is rounded.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds to the nearest amount, ties away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds to the nearest amount, ties to the even neighbour (banker's rounding).
+	RoundHalfEven
+	// RoundCeiling rounds towards positive infinity.
+	RoundCeiling
+	// RoundFloor rounds towards negative infinity.
+	RoundFloor
+)
+
+// ParseRoundingMode maps a configuration value such as "half_even" to a RoundingMode.
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "half_up":
+		return RoundHalfUp, nil
+	case "half_even", "bankers":
+		return RoundHalfEven, nil
+	case "ceiling":
+		return RoundCeiling, nil
+	case "floor":
+		return RoundFloor, nil
+	}
+	return RoundHalfUp, fmt.Errorf("unknown rounding mode %q", s)
+}
+
+var centsPerUnit = big.NewInt(100)
+
+// Money is an exact decimal amount stored as an integer number of cents.
+// The zero value is an amount of 0.00.
+type Money struct {
+	cents *big.Int
+}
+
+func NewMoneyFromCents(cents int64) Money {
+	return Money{cents: big.NewInt(cents)}
+}
+
+func NewMoneyFromUnits(units int64) Money {
+	return Money{cents: new(big.Int).Mul(big.NewInt(units), centsPerUnit)}
+}
+
+// NewMoneyFromString parses a decimal string such as "1500.25", it never goes through float64.
+func NewMoneyFromString(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "/eE") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(centsPerUnit))
+	if !r.IsInt() {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidMoney, s, MoneyScale)
+	}
+	return Money{cents: new(big.Int).Set(r.Num())}, nil
+}
+
+// MoneyFromNumeric converts a database numeric exactly, a NULL numeric is treated as zero.
+func MoneyFromNumeric(n pgtype.Numeric) (Money, error) {
+	if !n.Valid {
+		return Money{}, nil
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite || n.Int == nil {
+		return Money{}, fmt.Errorf("%w: numeric is not a finite number", ErrInvalidMoney)
+	}
+
+	shift := int64(n.Exp) + MoneyScale
+	if shift >= 0 {
+		return Money{cents: new(big.Int).Mul(n.Int, pow10(shift))}, nil
+	}
+	cents, rem := new(big.Int).QuoRem(n.Int, pow10(-shift), new(big.Int))
+	if rem.Sign() != 0 {
+		return Money{}, fmt.Errorf("%w: numeric has more than %d decimal places", ErrInvalidMoney, MoneyScale)
+	}
+	return Money{cents: cents}, nil
+}
+
+// Numeric returns the amount as a database numeric with MoneyScale decimal places.
+func (m Money) Numeric() pgtype.Numeric {
+	return pgtype.Numeric{Int: new(big.Int).Set(m.int()), Exp: -MoneyScale, Valid: true}
+}
+
+// Cents returns a copy of the amount in cents.
+func (m Money) Cents() *big.Int {
+	return new(big.Int).Set(m.int())
+}
+
+// Rat returns the amount as an exact rational number of units.
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(m.int(), centsPerUnit)
+}
+
+func (m Money) Add(o Money) Money {
+	return Money{cents: new(big.Int).Add(m.int(), o.int())}
+}
+
+func (m Money) Sub(o Money) Money {
+	return Money{cents: new(big.Int).Sub(m.int(), o.int())}
+}
+
+func (m Money) Neg() Money {
+	return Money{cents: new(big.Int).Neg(m.int())}
+}
+
+// MulInt multiplies the amount by an integer, the result is always exact.
+func (m Money) MulInt(n int64) Money {
+	return Money{cents: new(big.Int).Mul(m.int(), big.NewInt(n))}
+}
+
+// Mul multiplies the amount by an exact factor, such as an interest rate, and rounds the result to cents.
+func (m Money) Mul(factor *big.Rat, mode RoundingMode) Money {
+	r := new(big.Rat).Mul(new(big.Rat).SetInt(m.int()), factor)
+	return Money{cents: roundRat(r, mode)}
+}
+
+// Div divides the amount into n parts and rounds the result to cents, n must be positive.
+func (m Money) Div(n int64, mode RoundingMode) Money {
+	if n <= 0 {
+		panic("domain: Money.Div by a non-positive divisor")
+	}
+	return Money{cents: roundRat(new(big.Rat).SetFrac(m.int(), big.NewInt(n)), mode)}
+}
+
+// Round rounds the amount to the given number of decimal places, between 0 and MoneyScale.
+func (m Money) Round(places int32, mode RoundingMode) Money {
+	if places >= MoneyScale {
+		return Money{cents: new(big.Int).Set(m.int())}
+	}
+	if places < 0 {
+		places = 0
+	}
+	unit := pow10(int64(MoneyScale - places))
+	r := new(big.Rat).SetFrac(m.int(), unit)
+	return Money{cents: new(big.Int).Mul(roundRat(r, mode), unit)}
+}
+
+func (m Money) Cmp(o Money) int {
+	return m.int().Cmp(o.int())
+}
+
+func (m Money) Equal(o Money) bool {
+	return m.Cmp(o) == 0
+}
+
+func (m Money) Sign() int {
+	return m.int().Sign()
+}
+
+func (m Money) IsZero() bool {
+	return m.Sign() == 0
+}
+
+// String formats the amount with exactly MoneyScale decimal places, e.g. "1500.00".
+func (m Money) String() string {
+	cents := m.int()
+	sign := ""
+	if cents.Sign() < 0 {
+		sign = "-"
+	}
+	units, frac := new(big.Int).QuoRem(new(big.Int).Abs(cents), centsPerUnit, new(big.Int))
+	return fmt.Sprintf("%s%s.%02d", sign, units.String(), frac.Int64())
+}
+
+// MarshalJSON encodes the amount as a JSON string so clients never parse it as a float.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.String())
+}
+
+// UnmarshalJSON accepts a JSON string such as "1500.25", a bare JSON number is read from its literal text.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+	s := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidMoney, err)
+		}
+	}
+	parsed, err := NewMoneyFromString(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) int() *big.Int {
+	if m.cents == nil {
+		return new(big.Int)
+	}
+	return m.cents
+}
+
+// SumMoney adds up all given amounts.
+func SumMoney(amounts ...Money) Money {
+	total := Money{}
+	for _, amount := range amounts {
+		total = total.Add(amount)
+	}
+	return total
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+// roundRat rounds r to an integer according to mode.
+func roundRat(r *big.Rat, mode RoundingMode) *big.Int {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	sign := r.Sign()
+	away := func() *big.Int { return quo.Add(quo, big.NewInt(int64(sign))) }
+
+	switch mode {
+	case RoundCeiling:
+		if sign > 0 {
+			return away()
+		}
+		return quo
+	case RoundFloor:
+		if sign < 0 {
+			return away()
+		}
+		return quo
+	}
+
+	twiceRem := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2))
+	switch twiceRem.Cmp(r.Denom()) {
+	case 1:
+		return away()
+	case -1:
+		return quo
+	}
+	if mode == RoundHalfEven && quo.Bit(0) == 0 {
+		return quo
+	}
+	return away()
+}
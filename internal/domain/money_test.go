@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMoneyFromString(t *testing.T) {
+	m, err := NewMoneyFromString("183333.5")
+	assert.NoError(t, err)
+	assert.Equal(t, "183333.50", m.String())
+
+	_, err = NewMoneyFromString("0.001")
+	assert.ErrorIs(t, err, ErrInvalidMoney)
+
+	_, err = NewMoneyFromString("1e3")
+	assert.ErrorIs(t, err, ErrInvalidMoney)
+}
+
+func TestMoneyFromNumericIsExact(t *testing.T) {
+	// larger than an int64 can hold
+	intPart, _ := new(big.Int).SetString("92233720368547758070", 10)
+	m, err := MoneyFromNumeric(pgtype.Numeric{Int: intPart, Exp: -1, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "9223372036854775807.00", m.String())
+
+	m, err = MoneyFromNumeric(pgtype.Numeric{Int: big.NewInt(5500000), Exp: 0, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "5500000.00", m.String())
+
+	_, err = MoneyFromNumeric(pgtype.Numeric{Int: big.NewInt(1234), Exp: -3, Valid: true})
+	assert.ErrorIs(t, err, ErrInvalidMoney)
+}
+
+func TestMoneyNumericRoundTrip(t *testing.T) {
+	m := NewMoneyFromCents(-12345)
+	back, err := MoneyFromNumeric(m.Numeric())
+	assert.NoError(t, err)
+	assert.True(t, m.Equal(back))
+	assert.Equal(t, "-123.45", m.String())
+}
+
+func TestMoneyRounding(t *testing.T) {
+	half := big.NewRat(1, 2)
+	tests := []struct {
+		amount string
+		mode   RoundingMode
+		want   string
+	}{
+		{"0.05", RoundHalfUp, "0.03"},
+		{"0.05", RoundHalfEven, "0.02"},
+		{"0.07", RoundHalfEven, "0.04"},
+		{"-0.05", RoundHalfUp, "-0.03"},
+		{"0.05", RoundCeiling, "0.03"},
+		{"0.05", RoundFloor, "0.02"},
+	}
+	for _, tt := range tests {
+		m, _ := NewMoneyFromString(tt.amount)
+		assert.Equal(t, tt.want, m.Mul(half, tt.mode).String(), "%s with mode %d", tt.amount, tt.mode)
+	}
+
+	outstanding := NewMoneyFromUnits(5500000)
+	assert.Equal(t, "785714.29", outstanding.Div(7, RoundHalfUp).String())
+	assert.Equal(t, "785715.00", outstanding.Div(7, RoundCeiling).Round(0, RoundCeiling).String())
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var request struct {
+		Amount Money `json:"amount"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount": "183334.10"}`), &request))
+	assert.Equal(t, "183334.10", request.Amount.String())
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"amount": 183334.1}`), &request))
+	assert.Equal(t, "183334.10", request.Amount.String())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": "12.345"}`), &request))
+
+	encoded, err := json.Marshal(map[string]Money{"outstanding": NewMoneyFromCents(550000050)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"outstanding": "5500000.50"}`, string(encoded))
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrInvalidPayment         = errors.New("invalid payment")
+	ErrLoanFullyPaid          = errors.New("loan is already full paid")
+	ErrPaymentNotFound        = errors.New("payment not found")
+	ErrPaymentAlreadyReversed = errors.New("payment is already reversed")
+)
+
+// Payment is a single amount received for a loan, together with the schedule rows it settled.
+// Rebate is the unearned interest waived when the payment paid the loan off early.
+// A reversed payment keeps its allocations for audit, ReversedAt and ReversalReason record when and why
+// what it settled was owed again.
+type Payment struct {
+	ID             uint
+	LoanID         uint
+	Amount         Money
+	Rebate         Money
+	PaidAt         pgtype.Timestamp
+	ReversedAt     pgtype.Timestamp
+	ReversalReason string
+	Allocations    []PaymentAllocation
+}
+
+// PaymentAllocation is the part of a payment applied to one billing schedule period.
+// Remaining is what was still owed on that period after the allocation, zero when the period was settled.
+// When LateFeeID is set the allocation paid the late fee charged for that period rather than its installment.
+// Principal, Interest and Fee break Amount down, a late fee allocation is all Fee. InterestWaived is the interest of
+// the period an early payoff rebated on top of Amount.
+type PaymentAllocation struct {
+	BillingScheduleID uint
+	Period            uint
+	LateFeeID         uint
+	Amount            Money
+	Principal         Money
+	Interest          Money
+	Fee               Money
+	InterestWaived    Money
+	Remaining         Money
+}
+
+// PayoffQuote is the amount that settles a loan in full on AsOf.
+type PayoffQuote struct {
+	LoanID           uint
+	AsOf             pgtype.Date
+	Outstanding      Money
+	UnearnedInterest Money
+	Rebate           Money
+	LateFees         Money
+	PayoffAmount     Money
+	RebatePolicy     string
+}
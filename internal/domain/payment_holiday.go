@@ -0,0 +1,20 @@
+package domain
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+// PaymentHoliday defers the installments of a loan by Periods periods from FromPeriod, the first installment not
+// due yet. Nothing falls due between StartDate, the former due date of that installment, and EndDate, its new one.
+// CapitalisedInterest is the interest charged for the holiday, spread over the deferred installments.
+// Schedule holds the installments that were moved.
+type PaymentHoliday struct {
+	ID                  uint
+	LoanID              uint
+	FromPeriod          uint
+	Periods             int
+	StartDate           pgtype.Date
+	EndDate             pgtype.Date
+	CapitalisedInterest Money
+	Reason              string
+	CreatedAt           pgtype.Timestamp
+	Schedule            []BillingSchedule
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "github.com/jackc/pgx/v5/pgtype"
+
+// LoanRestructure replaces what is left of a loan's schedule with new terms. The unpaid principal, plus the
+// interest and fees of installments already due, is rescheduled as Principal over TenorPeriods installments of
+// RepaymentFrequency. Schedule holds the rows of ToVersion, the rows of FromVersion left unpaid are superseded.
+type LoanRestructure struct {
+	ID                 uint
+	LoanID             uint
+	FromVersion        int
+	ToVersion          int
+	OutstandingBefore  Money
+	Principal          Money
+	OutstandingAfter   Money
+	InterestRate       pgtype.Numeric
+	InterestMethod     InterestMethod
+	TenorPeriods       int
+	RepaymentFrequency RepaymentFrequency
+	Reason             string
+	RestructuredAt     pgtype.Timestamp
+	Schedule           []BillingSchedule
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	ErrWriteOffNotFound = errors.New("loan write-off not found")
+	ErrInvalidRecovery  = errors.New("invalid recovery")
+)
+
+// LoanWriteOff is what was left of a loan when it was written off: the principal, interest and installment fees of
+// its unpaid installments and its unpaid late fees, Amount sums them. The loan owes nothing afterwards, Recovered
+// sums the recoveries collected on it since. Automatic write-offs were made by the delinquency job.
+type LoanWriteOff struct {
+	ID           uint
+	LoanID       uint
+	Principal    Money
+	Interest     Money
+	Fee          Money
+	LateFees     Money
+	Amount       Money
+	Recovered    Money
+	Reason       string
+	Automatic    bool
+	WrittenOffAt pgtype.Timestamp
+}
+
+// Unrecovered returns the part of the amount written off that was not recovered yet.
+func (w LoanWriteOff) Unrecovered() Money {
+	return w.Amount.Sub(w.Recovered)
+}
+
+// Recovery is money collected on a written-off loan. It settles no installment and is income of its own.
+type Recovery struct {
+	ID          uint
+	LoanID      uint
+	Amount      Money
+	RecoveredAt pgtype.Timestamp
+}
+
+// WriteOffReport lists the loans written off and the recoveries collected from From to To, both included.
+// Recovered may include recoveries on loans written off before From.
+type WriteOffReport struct {
+	From       pgtype.Date
+	To         pgtype.Date
+	WriteOffs  []LoanWriteOff
+	Recoveries []Recovery
+	WrittenOff Money
+	Recovered  Money
+}
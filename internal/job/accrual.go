@@ -0,0 +1,22 @@
+package job
+
+import (
+	"billing-engine/internal/usecase"
+	"context"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ScheduleAccrual runs the interest accrual on the cron spec, e.g. "@daily", until the returned scheduler is
+// stopped. A run still in progress makes the next tick skip.
+func ScheduleAccrual(spec string, au usecase.AccrualUsecase) (*cron.Cron, error) {
+	return schedule("accrual job", spec, func(ctx context.Context) (string, error) {
+		run, err := au.Run(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s: %d loans checked, %d accrued, %s interest earned, %d failures",
+			run.Status, run.LoansChecked, run.LoansAccrued, run.InterestAccrued, run.Failures), nil
+	})
+}
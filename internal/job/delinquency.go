@@ -0,0 +1,22 @@
+package job
+
+import (
+	"billing-engine/internal/usecase"
+	"context"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ScheduleDelinquency runs the delinquency refresh on the cron spec, e.g. "0 1 * * *" or "@hourly",
+// until the returned scheduler is stopped. A run still in progress makes the next tick skip.
+func ScheduleDelinquency(spec string, du usecase.DelinquencyUsecase) (*cron.Cron, error) {
+	return schedule("delinquency job", spec, func(ctx context.Context) (string, error) {
+		run, err := du.Run(ctx)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s: %d loans checked, %d updated, %d status changes, %d failures",
+			run.Status, run.LoansChecked, run.LoansUpdated, run.StatusChanges, run.Failures), nil
+	})
+}
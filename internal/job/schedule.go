@@ -0,0 +1,28 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/robfig/cron/v3"
+)
+
+// schedule runs fn on the cron spec until the returned scheduler is stopped and logs the summary fn returns.
+// A run still in progress makes the next tick skip, fn returns an error then.
+func schedule(name, spec string, fn func(ctx context.Context) (string, error)) (*cron.Cron, error) {
+	scheduler := cron.New()
+	_, err := scheduler.AddFunc(spec, func() {
+		summary, err := fn(context.Background())
+		if err != nil {
+			log.Printf("%s: %v", name, err)
+			return
+		}
+		log.Printf("%s %s", name, summary)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s schedule %q: %w", name, spec, err)
+	}
+	scheduler.Start()
+	return scheduler, nil
+}
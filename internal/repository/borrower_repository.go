@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// uniqueViolation is the postgres error code raised when a UNIQUE constraint is violated.
+const uniqueViolation = "23505"
+
+type borrowerRepository struct {
+	queries *billingengine.Queries
+	db      *pgxpool.Pool
+}
+
+func NewBorrowerRepository(db *pgxpool.Pool) domain.BorrowerRepository {
+	return &borrowerRepository{queries: billingengine.New(db), db: db}
+}
+
+func toDomainBorrower(b billingengine.Borrower) *domain.Borrower {
+	return &domain.Borrower{
+		ID:        uint(b.ID),
+		Name:      b.Name,
+		Email:     b.Email,
+		Phone:     b.Phone,
+		CreatedAt: b.Createdat,
+		UpdatedAt: b.Updatedat,
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+func (r *borrowerRepository) CreateBorrower(ctx context.Context, borrower *domain.Borrower) (*domain.Borrower, error) {
+	created, err := r.queries.CreateBorrower(ctx, billingengine.CreateBorrowerParams{
+		Name:  borrower.Name,
+		Email: borrower.Email,
+		Phone: borrower.Phone,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrBorrowerEmailTaken
+		}
+		return nil, fmt.Errorf("failed to create borrower: %w", err)
+	}
+	return toDomainBorrower(created), nil
+}
+
+func (r *borrowerRepository) GetBorrowerByID(ctx context.Context, borrowerID uint) (*domain.Borrower, error) {
+	borrower, err := r.queries.GetBorrowerByID(ctx, int32(borrowerID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBorrowerNotFound
+		}
+		return nil, fmt.Errorf("failed to get borrower by id: %w", err)
+	}
+	return toDomainBorrower(borrower), nil
+}
+
+func (r *borrowerRepository) ListBorrowers(ctx context.Context, limit, offset uint) ([]domain.Borrower, error) {
+	borrowers, err := r.queries.ListBorrowers(ctx, billingengine.ListBorrowersParams{
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list borrowers: %w", err)
+	}
+
+	result := make([]domain.Borrower, 0, len(borrowers))
+	for _, borrower := range borrowers {
+		result = append(result, *toDomainBorrower(borrower))
+	}
+	return result, nil
+}
+
+func (r *borrowerRepository) UpdateBorrower(ctx context.Context, borrowerID uint, update *domain.BorrowerUpdate) (*domain.Borrower, error) {
+	updated, err := r.queries.UpdateBorrower(ctx, billingengine.UpdateBorrowerParams{
+		Name:  optionalText(update.Name),
+		Email: optionalText(update.Email),
+		Phone: optionalText(update.Phone),
+		ID:    int32(borrowerID),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrBorrowerNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, domain.ErrBorrowerEmailTaken
+		}
+		return nil, fmt.Errorf("failed to update borrower: %w", err)
+	}
+	return toDomainBorrower(updated), nil
+}
+
+func (r *borrowerRepository) DeleteBorrower(ctx context.Context, borrowerID uint) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	q := r.queries.WithTx(tx)
+
+	// the lock makes loans being created for the borrower commit first, and later ones see it deleted
+	_, err = q.LockBorrowerForUpdate(ctx, int32(borrowerID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrBorrowerNotFound
+		}
+		return fmt.Errorf("failed to lock borrower: %w", err)
+	}
+	params := billingengine.CountOpenLoansByBorrowerIDParams{BorrowerID: int32(borrowerID)}
+	for _, status := range domain.ClosedLoanStatuses {
+		params.ClosedStatuses = append(params.ClosedStatuses, string(status))
+	}
+	open, err := q.CountOpenLoansByBorrowerID(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to count open loans: %w", err)
+	}
+	if open > 0 {
+		return fmt.Errorf("%w: %d loans are not closed", domain.ErrBorrowerHasOpenLoans, open)
+	}
+	if _, err := q.SoftDeleteBorrower(ctx, int32(borrowerID)); err != nil {
+		return fmt.Errorf("failed to delete borrower: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit DeleteBorrower transaction: %w", err)
+	}
+	return nil
+}
+
+func optionalText(s *string) pgtype.Text {
+	if s == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *s, Valid: true}
+}
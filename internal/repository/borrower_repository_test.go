@@ -0,0 +1,58 @@
+package repository_test
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"billing-engine/internal/repository"
+	"billing-engine/internal/usecase"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteBorrowerKeepsBorrowersWithOpenLoans(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	loanRepo := repository.NewLoanRepository(pool, clock.New())
+	borrowerRepo := repository.NewBorrowerRepository(pool)
+	borrower, err := borrowerRepo.CreateBorrower(ctx, &domain.Borrower{
+		Name:  "Indebted Borrower",
+		Email: fmt.Sprintf("indebted-%d@example.com", time.Now().UnixNano()),
+		Phone: "0800000000",
+	})
+	require.NoError(t, err)
+	_, err = usecase.NewLoanUsecase(loanRepo, repository.NewLoanProductRepository(pool)).CreateLoan(ctx, usecase.LoanApplication{
+		BorrowerID:   borrower.ID,
+		Amount:       domain.NewMoneyFromUnits(1000),
+		TenorPeriods: 10,
+	})
+	require.NoError(t, err)
+
+	err = borrowerRepo.DeleteBorrower(ctx, borrower.ID)
+
+	assert.ErrorIs(t, err, domain.ErrBorrowerHasOpenLoans)
+	_, err = borrowerRepo.GetBorrowerByID(ctx, borrower.ID)
+	assert.NoError(t, err)
+}
+
+func TestCreateLoanForDeletedBorrower(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	loanRepo := repository.NewLoanRepository(pool, clock.New())
+	borrowerRepo := repository.NewBorrowerRepository(pool)
+	borrower, err := borrowerRepo.CreateBorrower(ctx, &domain.Borrower{
+		Name:  "Deleted Borrower",
+		Email: fmt.Sprintf("deleted-%d@example.com", time.Now().UnixNano()),
+		Phone: "0800000000",
+	})
+	require.NoError(t, err)
+	require.NoError(t, borrowerRepo.DeleteBorrower(ctx, borrower.ID))
+
+	_, err = loanRepo.CreateLoan(ctx, borrower.ID, &domain.Loan{Amount: domain.NewMoneyFromUnits(1000)}, nil)
+
+	assert.ErrorIs(t, err, domain.ErrBorrowerNotFound)
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type holidayRepository struct {
+	queries *billingengine.Queries
+}
+
+// NewHolidayRepository returns a repository reading holidays from the holidays table.
+func NewHolidayRepository(db *pgxpool.Pool) domain.HolidayRepository {
+	return &holidayRepository{queries: billingengine.New(db)}
+}
+
+func (r *holidayRepository) ListHolidays(ctx context.Context, region string) ([]domain.Holiday, error) {
+	rows, err := r.queries.ListHolidaysByRegion(ctx, region)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list holidays: %w", err)
+	}
+
+	holidays := make([]domain.Holiday, 0, len(rows))
+	for _, row := range rows {
+		holidays = append(holidays, domain.Holiday{Region: row.Region, Date: row.Date.Time, Name: row.Name})
+	}
+	return holidays, nil
+}
+
+type holidayFileRepository struct {
+	path string
+}
+
+// NewHolidayFileRepository returns a repository reading holidays from a CSV file with region, date (YYYY-MM-DD)
+// and name columns. Blank lines and lines starting with # are skipped. The file is read on every call.
+func NewHolidayFileRepository(path string) domain.HolidayRepository {
+	return &holidayFileRepository{path: path}
+}
+
+func (r *holidayFileRepository) ListHolidays(ctx context.Context, region string) ([]domain.Holiday, error) {
+	file, err := os.Open(r.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open holiday file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	var holidays []domain.Holiday
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read holiday file: %w", err)
+		}
+		if !strings.EqualFold(strings.TrimSpace(record[0]), region) {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(record[1]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read holiday file: invalid date %q", record[1])
+		}
+		holidays = append(holidays, domain.Holiday{
+			Region: strings.TrimSpace(record[0]),
+			Date:   date,
+			Name:   strings.TrimSpace(record[2]),
+		})
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date.Before(holidays[j].Date) })
+	return holidays, nil
+}
@@ -0,0 +1,40 @@
+package repository_test
+
+import (
+	"billing-engine/internal/repository"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHolidayFileRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.csv")
+	require.NoError(t, os.WriteFile(path, []byte(`# region,date,name
+ID,2024-12-25,Christmas Day
+SG,2024-08-09,National Day
+
+ID, 2024-08-17, Independence Day
+`), 0o600))
+
+	holidays, err := repository.NewHolidayFileRepository(path).ListHolidays(context.Background(), "ID")
+
+	require.NoError(t, err)
+	require.Len(t, holidays, 2)
+	assert.Equal(t, "Independence Day", holidays[0].Name)
+	assert.Equal(t, time.Date(2024, time.August, 17, 0, 0, 0, 0, time.UTC), holidays[0].Date)
+	assert.Equal(t, "Christmas Day", holidays[1].Name)
+}
+
+func TestHolidayFileRepositoryRejectsInvalidDates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holidays.csv")
+	require.NoError(t, os.WriteFile(path, []byte("ID,17/08/2024,Independence Day\n"), 0o600))
+
+	_, err := repository.NewHolidayFileRepository(path).ListHolidays(context.Background(), "ID")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type idempotencyRepository struct {
+	queries *billingengine.Queries
+	lease   time.Duration
+}
+
+// NewIdempotencyRepository returns a repository where a reservation holds its key for lease. A request still running
+// after that is assumed to have died with its process, a retry with the same key and payload then takes it over.
+func NewIdempotencyRepository(db *pgxpool.Pool, lease time.Duration) domain.IdempotencyRepository {
+	return &idempotencyRepository{queries: billingengine.New(db), lease: lease}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, key, requestHash string) (*domain.IdempotencyRecord, bool, error) {
+	created, err := r.queries.CreateIdempotencyKey(ctx, billingengine.CreateIdempotencyKeyParams{
+		Key:         key,
+		RequestHash: requestHash,
+		Lease:       pgtype.Interval{Microseconds: r.lease.Microseconds(), Valid: true},
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if created == 1 {
+		return &domain.IdempotencyRecord{Key: key, RequestHash: requestHash}, true, nil
+	}
+
+	existing, err := r.queries.GetIdempotencyKey(ctx, key)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return &domain.IdempotencyRecord{
+		Key:            existing.Key,
+		RequestHash:    existing.RequestHash,
+		ResponseStatus: int(existing.ResponseStatus.Int32),
+		ResponseBody:   existing.ResponseBody,
+		Completed:      existing.Completedat.Valid,
+	}, false, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, key string, status int, body []byte) error {
+	err := r.queries.CompleteIdempotencyKey(ctx, billingengine.CompleteIdempotencyKeyParams{
+		Key:            key,
+		ResponseStatus: pgtype.Int4{Int32: int32(status), Valid: true},
+		ResponseBody:   body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, key string) error {
+	if err := r.queries.DeleteIdempotencyKey(ctx, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package repository_test
+
+import (
+	"billing-engine/internal/repository"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveTakesOverStaleReservation(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	repo := repository.NewIdempotencyRepository(pool, 5*time.Minute)
+	key := fmt.Sprintf("stale-%d", time.Now().UnixNano())
+
+	_, created, err := repo.Reserve(ctx, key, "hash")
+	require.NoError(t, err)
+	require.True(t, created)
+
+	// a retry while the first request may still be running is turned away
+	record, created, err := repo.Reserve(ctx, key, "hash")
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.False(t, record.Completed)
+
+	// the first request died without releasing the key
+	_, err = pool.Exec(ctx, "UPDATE idempotency_keys SET createdat = createdat - interval '1 hour' WHERE key = $1", key)
+	require.NoError(t, err)
+
+	_, created, err = repo.Reserve(ctx, key, "other hash")
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	_, created, err = repo.Reserve(ctx, key, "hash")
+	require.NoError(t, err)
+	assert.True(t, created)
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (r *loanRepository) AccrueInterest(ctx context.Context, loan *domain.Loan, schedules []domain.BillingSchedule, accrual *domain.InterestAccrual) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	for _, schedule := range schedules {
+		err = q.UpdateBillingScheduleAccruedInterest(ctx, billingengine.UpdateBillingScheduleAccruedInterestParams{
+			AccruedInterest: schedule.AccruedInterest.Numeric(),
+			ID:              int32(schedule.ID),
+		})
+		if err != nil {
+			log.Printf("failed to update accrued interest of billing schedule: %v", err)
+			return fmt.Errorf("failed to update accrued interest of billing schedule: %w", err)
+		}
+	}
+
+	err = q.UpdateLoanAccruedInterest(ctx, billingengine.UpdateLoanAccruedInterestParams{
+		AccruedInterest: loan.AccruedInterest.Numeric(),
+		ID:              int32(loan.ID),
+	})
+	if err != nil {
+		log.Printf("failed to update accrued interest of loan: %v", err)
+		return fmt.Errorf("failed to update accrued interest of loan: %w", err)
+	}
+
+	row, err := q.UpsertInterestAccrual(ctx, billingengine.UpsertInterestAccrualParams{
+		LoanID:        int32(accrual.LoanID),
+		AccrualDate:   accrual.AccrualDate,
+		Method:        string(accrual.Method),
+		Amount:        accrual.Amount.Numeric(),
+		AccruedToDate: accrual.AccruedToDate.Numeric(),
+	})
+	if err != nil {
+		log.Printf("failed to create interest accrual: %v", err)
+		return fmt.Errorf("failed to create interest accrual: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit AccrueInterest transaction: %w", err)
+	}
+	accrual.ID = uint(row.ID)
+	return nil
+}
+
+func (r *loanRepository) GetInterestAccruals(ctx context.Context, loanID uint) ([]domain.InterestAccrual, error) {
+	rows, err := r.queries.GetInterestAccrualsByLoanID(ctx, int32(loanID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interest accruals: %w", err)
+	}
+
+	conv := moneyConverter{}
+	accruals := make([]domain.InterestAccrual, 0, len(rows))
+	for _, row := range rows {
+		accruals = append(accruals, domain.InterestAccrual{
+			ID:            uint(row.ID),
+			LoanID:        uint(row.LoanID),
+			AccrualDate:   row.AccrualDate,
+			Method:        domain.AccrualMethod(row.Method),
+			Amount:        conv.from(row.Amount),
+			AccruedToDate: conv.from(row.AccruedToDate),
+		})
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert interest accrual amounts: %w", conv.err)
+	}
+	return accruals, nil
+}
+
+func (r *ledgerRepository) GetInterestAccrualTotals(ctx context.Context, from, to time.Time) ([]domain.InterestAccrualTotal, error) {
+	rows, err := r.queries.GetInterestAccrualTotals(ctx, billingengine.GetInterestAccrualTotalsParams{
+		FromDate: pgtype.Date{Time: from, Valid: true},
+		ToDate:   pgtype.Date{Time: to, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get interest accrual totals: %w", err)
+	}
+
+	conv := moneyConverter{}
+	totals := make([]domain.InterestAccrualTotal, 0, len(rows))
+	for _, row := range rows {
+		totals = append(totals, domain.InterestAccrualTotal{
+			AccrualDate: row.AccrualDate,
+			Loans:       int(row.Loans),
+			Amount:      conv.from(row.Amount),
+		})
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert interest accrual amounts: %w", conv.err)
+	}
+	return totals, nil
+}
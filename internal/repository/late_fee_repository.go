@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"fmt"
+	"log"
+)
+
+func (r *loanRepository) GetLateFees(ctx context.Context, loanID uint) ([]domain.LateFee, error) {
+	rows, err := r.queries.GetLateFeesByLoanID(ctx, int32(loanID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get late fees: %w", err)
+	}
+
+	conv := moneyConverter{}
+	result := make([]domain.LateFee, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, domain.LateFee{
+			ID:                uint(row.ID),
+			LoanID:            uint(row.LoanID),
+			BillingScheduleID: uint(row.BillingScheduleID),
+			Period:            uint(row.Period),
+			Amount:            conv.from(row.Amount),
+			PaidAmount:        conv.from(row.PaidAmount),
+			ChargedAt:         row.ChargedAt,
+		})
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert late fee amounts: %w", conv.err)
+	}
+	return result, nil
+}
+
+func (r *loanRepository) CreateLateFee(ctx context.Context, fee *domain.LateFee) error {
+	created, err := r.queries.CreateLateFee(ctx, billingengine.CreateLateFeeParams{
+		LoanID:            int32(fee.LoanID),
+		BillingScheduleID: int32(fee.BillingScheduleID),
+		Amount:            fee.Amount.Numeric(),
+		ChargedAt:         timestamp(r.clock.Now()),
+	})
+	if err != nil {
+		log.Printf("failed to create late fee: %v", err)
+		return fmt.Errorf("failed to create late fee: %w", err)
+	}
+	fee.ID = uint(created.ID)
+	fee.ChargedAt = created.ChargedAt
+	return nil
+}
+
+func (r *loanRepository) UpdateLateFee(ctx context.Context, fee *domain.LateFee) error {
+	err := r.queries.UpdateLateFeePaidAmount(ctx, billingengine.UpdateLateFeePaidAmountParams{
+		PaidAmount: fee.PaidAmount.Numeric(),
+		ID:         int32(fee.ID),
+	})
+	if err != nil {
+		log.Printf("failed to update late fee: %v", err)
+		return fmt.Errorf("failed to update late fee: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func (r *loanRepository) PostJournalEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	created, err := q.CreateJournalEntry(ctx, billingengine.CreateJournalEntryParams{
+		LoanID:          int32(entry.LoanID),
+		PaymentID:       pgtype.Int4{Int32: int32(entry.PaymentID), Valid: entry.PaymentID != 0},
+		Kind:            string(entry.Kind),
+		Description:     entry.Description,
+		PostedAt:        timestamp(r.clock.Now()),
+		ReversesEntryID: pgtype.Int4{Int32: int32(entry.ReversesEntryID), Valid: entry.ReversesEntryID != 0},
+	})
+	if err != nil {
+		log.Printf("failed to create journal entry: %v", err)
+		return fmt.Errorf("failed to create journal entry: %w", err)
+	}
+
+	for _, posting := range entry.Postings {
+		err = q.CreateJournalPosting(ctx, billingengine.CreateJournalPostingParams{
+			EntryID:     created.ID,
+			AccountCode: string(posting.Account),
+			Debit:       posting.Debit.Numeric(),
+			Credit:      posting.Credit.Numeric(),
+		})
+		if err != nil {
+			log.Printf("failed to create journal posting: %v", err)
+			return fmt.Errorf("failed to create journal posting: %w", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit PostJournalEntry transaction: %w", err)
+	}
+	entry.ID = uint(created.ID)
+	entry.PostedAt = created.PostedAt
+	return nil
+}
+
+func (r *loanRepository) GetJournalEntries(ctx context.Context, loanID uint) ([]domain.JournalEntry, error) {
+	entries, err := r.queries.GetJournalEntriesByLoanID(ctx, int32(loanID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal entries: %w", err)
+	}
+
+	postings, err := r.queries.GetJournalPostingsByLoanID(ctx, int32(loanID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal postings: %w", err)
+	}
+
+	conv := moneyConverter{}
+	postingsByEntry := make(map[int32][]domain.Posting)
+	for _, posting := range postings {
+		postingsByEntry[posting.EntryID] = append(postingsByEntry[posting.EntryID], domain.Posting{
+			Account: domain.LedgerAccount(posting.AccountCode),
+			Debit:   conv.from(posting.Debit),
+			Credit:  conv.from(posting.Credit),
+		})
+	}
+
+	result := make([]domain.JournalEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, domain.JournalEntry{
+			ID:              uint(entry.ID),
+			LoanID:          uint(entry.LoanID),
+			PaymentID:       uint(entry.PaymentID.Int32),
+			Kind:            domain.JournalEntryKind(entry.Kind),
+			Description:     entry.Description,
+			PostedAt:        entry.PostedAt,
+			ReversesEntryID: uint(entry.ReversesEntryID.Int32),
+			Postings:        postingsByEntry[entry.ID],
+		})
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert journal postings: %w", conv.err)
+	}
+	return result, nil
+}
+
+type ledgerRepository struct {
+	queries *billingengine.Queries
+}
+
+// NewLedgerRepository returns a repository reading the balances of the ledger accounts.
+func NewLedgerRepository(db *pgxpool.Pool) domain.LedgerRepository {
+	return &ledgerRepository{queries: billingengine.New(db)}
+}
+
+func (r *ledgerRepository) GetTrialBalance(ctx context.Context, asOf time.Time) ([]domain.TrialBalanceAccount, error) {
+	rows, err := r.queries.GetTrialBalance(ctx, timestamp(asOf))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trial balance: %w", err)
+	}
+
+	conv := moneyConverter{}
+	accounts := make([]domain.TrialBalanceAccount, 0, len(rows))
+	for _, row := range rows {
+		accounts = append(accounts, domain.TrialBalanceAccount{
+			Code:   domain.LedgerAccount(row.Code),
+			Number: row.Number,
+			Name:   row.Name,
+			Type:   domain.AccountType(row.Type),
+			Debit:  conv.from(row.Debit),
+			Credit: conv.from(row.Credit),
+		})
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert trial balance amounts: %w", conv.err)
+	}
+	return accounts, nil
+}
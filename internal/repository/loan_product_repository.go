@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type loanProductRepository struct {
+	queries *billingengine.Queries
+	db      *pgxpool.Pool
+}
+
+func NewLoanProductRepository(db *pgxpool.Pool) domain.LoanProductRepository {
+	return &loanProductRepository{queries: billingengine.New(db), db: db}
+}
+
+func toDomainLoanProduct(p billingengine.LoanProduct) (*domain.LoanProduct, error) {
+	conv := moneyConverter{}
+	product := &domain.LoanProduct{
+		ID:                 uint(p.ID),
+		Name:               p.Name,
+		MinAmount:          conv.from(p.MinAmount),
+		MaxAmount:          conv.from(p.MaxAmount),
+		InterestRate:       p.InterestRate,
+		InterestMethod:     domain.InterestMethod(p.InterestMethod),
+		TenorPeriods:       make([]int, 0, len(p.TenorPeriods)),
+		RepaymentFrequency: domain.RepaymentFrequency(p.RepaymentFrequency),
+		InstallmentFee:     conv.from(p.InstallmentFee),
+		OriginationFeeRate: p.OriginationFeeRate,
+		Active:             p.Active,
+		CreatedAt:          p.Createdat,
+		UpdatedAt:          p.Updatedat,
+	}
+	for _, weeks := range p.TenorPeriods {
+		product.TenorPeriods = append(product.TenorPeriods, int(weeks))
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert loan product amounts: %w", conv.err)
+	}
+	return product, nil
+}
+
+func (r *loanProductRepository) CreateLoanProduct(ctx context.Context, product *domain.LoanProduct) (*domain.LoanProduct, error) {
+	params := billingengine.CreateLoanProductParams{
+		Name:               product.Name,
+		MinAmount:          product.MinAmount.Numeric(),
+		MaxAmount:          product.MaxAmount.Numeric(),
+		InterestRate:       product.InterestRate,
+		InterestMethod:     string(product.InterestMethod),
+		RepaymentFrequency: string(product.RepaymentFrequency),
+		InstallmentFee:     product.InstallmentFee.Numeric(),
+		OriginationFeeRate: product.OriginationFeeRate,
+	}
+	for _, weeks := range product.TenorPeriods {
+		params.TenorPeriods = append(params.TenorPeriods, int32(weeks))
+	}
+
+	created, err := r.queries.CreateLoanProduct(ctx, params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrLoanProductTaken
+		}
+		return nil, fmt.Errorf("failed to create loan product: %w", err)
+	}
+	return toDomainLoanProduct(created)
+}
+
+func (r *loanProductRepository) GetLoanProductByID(ctx context.Context, productID uint) (*domain.LoanProduct, error) {
+	product, err := r.queries.GetLoanProductByID(ctx, int32(productID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLoanProductNotFound
+		}
+		return nil, fmt.Errorf("failed to get loan product by id: %w", err)
+	}
+	return toDomainLoanProduct(product)
+}
+
+func (r *loanProductRepository) ListLoanProducts(ctx context.Context) ([]domain.LoanProduct, error) {
+	products, err := r.queries.ListLoanProducts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loan products: %w", err)
+	}
+
+	result := make([]domain.LoanProduct, 0, len(products))
+	for _, product := range products {
+		converted, err := toDomainLoanProduct(product)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *converted)
+	}
+	return result, nil
+}
+
+func (r *loanProductRepository) SetLoanProductActive(ctx context.Context, productID uint, active bool) error {
+	affected, err := r.queries.SetLoanProductActive(ctx, billingengine.SetLoanProductActiveParams{
+		Active: active,
+		ID:     int32(productID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update loan product: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrLoanProductNotFound
+	}
+	return nil
+}
@@ -1,83 +1,156 @@
 package repository
 
 import (
+	"billing-engine/internal/clock"
 	"billing-engine/internal/domain"
 	"billing-engine/sql/billingengine"
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"log"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type loanRepository struct {
-	queries *billingengine.Queries
-	db      *pgxpool.Pool
+// txBeginner is implemented by the pool and by an open transaction, where Begin starts a savepoint.
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
-type noRowsError struct {
-	sqlNoRow string
-	noRows   string
-}
-
-func (e noRowsError) ErrSqlNoRow() string {
-	e.sqlNoRow = "sql: no rows in result set"
-	return e.sqlNoRow
-}
-
-func (e noRowsError) ErrNoRow() string {
-	e.noRows = "no rows in result set"
-	return e.noRows
+type loanRepository struct {
+	queries *billingengine.Queries
+	db      txBeginner
+	clock   clock.Clock
 }
 
-func NewLoanRepository(db *pgxpool.Pool) domain.LoanRepository {
-	return &loanRepository{queries: billingengine.New(db), db: db}
+// NewLoanRepository returns a repository that stamps due dates, payments and closures with the time from clk.
+func NewLoanRepository(db *pgxpool.Pool, clk clock.Clock) domain.LoanRepository {
+	return &loanRepository{queries: billingengine.New(db), db: db, clock: clk}
 }
 
-func (r *loanRepository) IsDelinquent(ctx context.Context, loanID uint) (*domain.CheckDelinquentAmount, error) {
-	check, err := r.queries.CheckDelinquentAmount(ctx, int32(loanID))
-	ernorow := noRowsError{}
+func (r *loanRepository) IsDelinquent(ctx context.Context, loanID uint, asOf time.Time) (*domain.CheckDelinquentAmount, error) {
+	check, err := r.queries.CheckDelinquentAmount(ctx, billingengine.CheckDelinquentAmountParams{
+		LoanID: int32(loanID),
+		AsOf:   timestamp(asOf),
+	})
 	if err != nil {
-		if err.Error() == ernorow.ErrSqlNoRow() || err.Error() == ernorow.ErrNoRow() {
-			return &domain.CheckDelinquentAmount{
-				LoanID:       uint(loanID),
-				IsDelinquent: false,
-				TotalWeek:    0,
-				Amount:       0,
-			}, nil
-		} else {
+		if !errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("loan repo: failed to check delinquent amount: %w", err)
 		}
+		// nothing is overdue on the loan, unless there is no such loan
+		if _, err := r.queries.GetLoanByID(ctx, int32(loanID)); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, domain.ErrLoanNotFound
+			}
+			return nil, fmt.Errorf("loan repo: failed to get loan: %w", err)
+		}
+		return &domain.CheckDelinquentAmount{LoanID: loanID}, nil
+	}
+	amount, err := domain.MoneyFromNumeric(check.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("loan repo: failed to convert delinquent amount: %w", err)
 	}
 	return &domain.CheckDelinquentAmount{
 		LoanID:       uint(check.LoanID),
 		TotalWeek:    int(check.TotalWeek),
-		Amount:       check.Sum,
-		IsDelinquent: check.TotalWeek >= 2,
+		Amount:       amount,
+		IsDelinquent: check.TotalWeek >= domain.DelinquentAfterWeeks,
 	}, nil
 }
 
+// WithinTransaction runs fn as one unit of work, every call made through the repository passed to fn
+// shares a single transaction that is committed when fn returns nil and rolled back otherwise.
+func (r *loanRepository) WithinTransaction(ctx context.Context, fn func(repo domain.LoanRepository) error) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = fn(&loanRepository{queries: r.queries.WithTx(tx), db: tx, clock: r.clock})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 func (r *loanRepository) GetLoanByID(ctx context.Context, loanID uint) (*domain.Loan, error) {
 	loan, err := r.queries.GetLoanByID(ctx, int32(loanID))
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLoanNotFound
+		}
 		log.Printf("failed to get loan by id: %v", err)
 		return nil, err
 	}
-	return &domain.Loan{
-		ID:                uint(loan.ID),
-		Amount:            loan.Amount,
-		InterestRate:      loan.InterestRate,
-		DurationWeeks:     int(loan.DurationWeeks),
-		Outstanding:       loan.Outstanding,
-		DelinquentWeeks:   int(loan.DelinquentWeeks),
-		InstallmentAmount: loan.InstallmentAmount,
+	return toDomainLoan(loan)
+}
+
+// GetLoanByIDForUpdate reads the loan and locks its row until the surrounding transaction ends,
+// it only serialises writers when called inside WithinTransaction.
+func (r *loanRepository) GetLoanByIDForUpdate(ctx context.Context, loanID uint) (*domain.Loan, error) {
+	loan, err := r.queries.GetLoanByIDForUpdate(ctx, int32(loanID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLoanNotFound
+		}
+		log.Printf("failed to lock loan by id: %v", err)
+		return nil, err
+	}
+	return toDomainLoan(billingengine.GetLoanByIDRow(loan))
+}
+
+func (r *loanRepository) GetLoanBorrower(ctx context.Context, loanID uint) (*domain.LoanBorrower, error) {
+	borrower, err := r.queries.GetBorrowerByLoanID(ctx, int32(loanID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrLoanNotFound
+		}
+		log.Printf("failed to get borrower by loan id: %v", err)
+		return nil, err
+	}
+	return &domain.LoanBorrower{
+		ID:    uint(borrower.ID),
+		Name:  borrower.Name,
+		Email: borrower.Email,
+		Phone: borrower.Phone,
 	}, nil
 }
 
-func (r *loanRepository) UpdateLoan(ctx context.Context, loan *domain.Loan, schedule *domain.BillingSchedule) error {
+func toDomainLoan(loan billingengine.GetLoanByIDRow) (*domain.Loan, error) {
+	conv := moneyConverter{}
+	result := &domain.Loan{
+		ID:                 uint(loan.ID),
+		Amount:             conv.from(loan.Amount),
+		InterestRate:       loan.InterestRate,
+		InterestMethod:     domain.InterestMethod(loan.InterestMethod),
+		TenorPeriods:       int(loan.TenorPeriods),
+		RepaymentFrequency: domain.RepaymentFrequency(loan.RepaymentFrequency),
+		Outstanding:        conv.from(loan.Outstanding),
+		DelinquentWeeks:    int(loan.DelinquentWeeks),
+		InstallmentAmount:  conv.from(loan.InstallmentAmount),
+		Status:             domain.LoanStatus(loan.Status),
+		ClosedAt:           loan.Closedat,
+		ProductID:          uint(loan.ProductID.Int32),
+		DisbursementDate:   loan.DisbursementDate,
+		AccruedInterest:    conv.from(loan.AccruedInterest),
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert loan amounts: %w", conv.err)
+	}
+	return result, nil
+}
+
+func (r *loanRepository) UpdateLoan(ctx context.Context, loan *domain.Loan, schedules []domain.BillingSchedule, payment *domain.Payment) error {
 
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -85,36 +158,50 @@ func (r *loanRepository) UpdateLoan(ctx context.Context, loan *domain.Loan, sche
 	}
 	defer tx.Rollback(ctx)
 
+	err = r.applyPayment(ctx, r.queries.WithTx(tx), loan, schedules, payment)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit UpdateLoan transaction: %w", err)
+	}
+	return nil
+}
+
+// applyPayment writes the new loan balance, the schedule rows the payment touched and the payment itself.
+func (r *loanRepository) applyPayment(ctx context.Context, q *billingengine.Queries, loan *domain.Loan, schedules []domain.BillingSchedule, payment *domain.Payment) error {
 	params := billingengine.UpdateLoanParams{
-		Amount:          loan.Amount,
+		Amount:          loan.Amount.Numeric(),
 		InterestRate:    loan.InterestRate,
-		DurationWeeks:   int32(loan.DurationWeeks),
-		Outstanding:     loan.Outstanding,
+		TenorPeriods:    int32(loan.TenorPeriods),
+		Outstanding:     loan.Outstanding.Numeric(),
 		DelinquentWeeks: int32(loan.DelinquentWeeks),
 		ID:              int32(loan.ID),
 	}
 
-	err = r.queries.WithTx(tx).UpdateLoan(ctx, params)
+	err := q.UpdateLoan(ctx, params)
 	if err != nil {
 		log.Printf("failed to update loan: %v", err)
 		return fmt.Errorf("failed to update loan: %w", err)
 	}
 
-	err = r.queries.WithTx(tx).UpdateBillingSchedule(ctx, billingengine.UpdateBillingScheduleParams{
-		Paid:   schedule.Paid,
-		LoanID: int32(schedule.LoanID),
-		Week:   int32(schedule.Week),
-	})
-	if err != nil {
-		log.Printf("failed to update billing schedule: %v", err)
-		return fmt.Errorf("failed to update billing schedule: %w", err)
+	for _, schedule := range schedules {
+		err = q.UpdateBillingSchedule(ctx, billingengine.UpdateBillingScheduleParams{
+			Paid:           schedule.Paid,
+			PaidAmount:     schedule.PaidAmount.Numeric(),
+			WaivedInterest: schedule.WaivedInterest.Numeric(),
+			LoanID:         int32(schedule.LoanID),
+			Period:         int32(schedule.Period),
+		})
+		if err != nil {
+			log.Printf("failed to update billing schedule: %v", err)
+			return fmt.Errorf("failed to update billing schedule: %w", err)
+		}
 	}
 
-	err = tx.Commit(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to commit UpdateLoan transaction: %w", err)
-	}
-	return nil
+	return r.createPayment(ctx, q, payment)
 }
 
 func (r *loanRepository) GetLoansWithBorrower(ctx context.Context, limit, offset uint) ([]domain.LoanWithBorrower, error) {
@@ -128,21 +215,89 @@ func (r *loanRepository) GetLoansWithBorrower(ctx context.Context, limit, offset
 	}
 
 	var result []domain.LoanWithBorrower
+	conv := moneyConverter{}
 	for _, loan := range loans {
 		result = append(result, domain.LoanWithBorrower{
-			LoanID:        uint(loan.LoanID),
-			BorrowerID:    uint(loan.BorrowerID),
-			BorrowerName:  loan.BorrowerName,
-			Amount:        loan.Amount,
-			InterestRate:  loan.InterestRate,
-			DurationWeeks: int(loan.DurationWeeks),
-			Outstanding:   loan.Outstanding,
+			LoanID:             uint(loan.LoanID),
+			BorrowerID:         uint(loan.BorrowerID),
+			BorrowerName:       loan.BorrowerName,
+			Amount:             conv.from(loan.Amount),
+			InterestRate:       loan.InterestRate,
+			TenorPeriods:       int(loan.TenorPeriods),
+			RepaymentFrequency: domain.RepaymentFrequency(loan.RepaymentFrequency),
+			Outstanding:        conv.from(loan.Outstanding),
+			Status:             domain.LoanStatus(loan.Status),
+		})
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert loan amounts: %w", conv.err)
+	}
+	return result, nil
+}
+
+func (r *loanRepository) GetLoansByBorrowerID(ctx context.Context, borrowerID uint) ([]domain.Loan, error) {
+	loans, err := r.queries.GetLoansByBorrowerID(ctx, int32(borrowerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans by borrower id: %w", err)
+	}
+
+	result := make([]domain.Loan, 0, len(loans))
+	conv := moneyConverter{}
+	for _, loan := range loans {
+		result = append(result, domain.Loan{
+			ID:                 uint(loan.ID),
+			Amount:             conv.from(loan.Amount),
+			InterestRate:       loan.InterestRate,
+			InterestMethod:     domain.InterestMethod(loan.InterestMethod),
+			TenorPeriods:       int(loan.TenorPeriods),
+			RepaymentFrequency: domain.RepaymentFrequency(loan.RepaymentFrequency),
+			Outstanding:        conv.from(loan.Outstanding),
+			DelinquentWeeks:    int(loan.DelinquentWeeks),
+			Status:             domain.LoanStatus(loan.Status),
+			ProductID:          uint(loan.ProductID.Int32),
+			DisbursementDate:   loan.DisbursementDate,
+			AccruedInterest:    conv.from(loan.AccruedInterest),
 		})
 	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert loan amounts: %w", conv.err)
+	}
 	return result, nil
 }
 
-func (r *loanRepository) CreateLoan(ctx context.Context, borrowerID uint, loan *domain.Loan) (uint, error) {
+func (r *loanRepository) ListLoanIDsByStatus(ctx context.Context, statuses []domain.LoanStatus, afterID uint, limit int) ([]uint, error) {
+	params := billingengine.ListLoanIDsByStatusParams{
+		AfterID:   int32(afterID),
+		BatchSize: int32(limit),
+	}
+	for _, status := range statuses {
+		params.Statuses = append(params.Statuses, string(status))
+	}
+	ids, err := r.queries.ListLoanIDsByStatus(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list loans by status: %w", err)
+	}
+
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, uint(id))
+	}
+	return result, nil
+}
+
+func (r *loanRepository) UpdateDelinquentWeeks(ctx context.Context, loanID uint, weeks int) error {
+	err := r.queries.UpdateLoanDelinquentWeeks(ctx, billingengine.UpdateLoanDelinquentWeeksParams{
+		DelinquentWeeks: int32(weeks),
+		ID:              int32(loanID),
+	})
+	if err != nil {
+		log.Printf("failed to update delinquent weeks: %v", err)
+		return fmt.Errorf("failed to update delinquent weeks: %w", err)
+	}
+	return nil
+}
+
+func (r *loanRepository) CreateLoan(ctx context.Context, borrowerID uint, loan *domain.Loan, schedules []domain.BillingSchedule) (uint, error) {
 
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -150,30 +305,33 @@ func (r *loanRepository) CreateLoan(ctx context.Context, borrowerID uint, loan *
 	}
 	defer tx.Rollback(ctx)
 
+	// the borrower stays locked until the loan is committed, a concurrent delete waits and then sees the loan
+	_, err = r.queries.WithTx(tx).LockBorrowerForShare(ctx, int32(borrowerID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrBorrowerNotFound
+		}
+		return 0, fmt.Errorf("failed to lock borrower: %w", err)
+	}
+
 	loanID, err := r.queries.WithTx(tx).CreateLoan(ctx, billingengine.CreateLoanParams{
-		BorrowerID:        int32(borrowerID),
-		Amount:            loan.Amount,
-		InterestRate:      loan.InterestRate,
-		DurationWeeks:     int32(loan.DurationWeeks),
-		Outstanding:       loan.Outstanding,
-		DelinquentWeeks:   int32(0),
-		InstallmentAmount: loan.InstallmentAmount,
+		BorrowerID:         int32(borrowerID),
+		Amount:             loan.Amount.Numeric(),
+		InterestRate:       loan.InterestRate,
+		TenorPeriods:       int32(loan.TenorPeriods),
+		Outstanding:        loan.Outstanding.Numeric(),
+		DelinquentWeeks:    int32(0),
+		InstallmentAmount:  loan.InstallmentAmount.Numeric(),
+		InterestMethod:     string(loan.InterestMethod),
+		ProductID:          pgtype.Int4{Int32: int32(loan.ProductID), Valid: loan.ProductID != 0},
+		RepaymentFrequency: string(loan.RepaymentFrequency),
+		DisbursementDate:   loan.DisbursementDate,
 	})
 	if err != nil {
 		log.Printf("failed to create loan: %v", err)
 		return 0, fmt.Errorf("failed to create loan: %w", err)
 	}
-	var billingSchedules billingengine.CreateBillingSchedulesParams
-	for week := 1; week <= loan.DurationWeeks; week++ {
-		dueDate := time.Now().AddDate(0, 0, 7*week)
-		billingSchedules.Column1 = append(billingSchedules.Column1, int32(loanID))
-		billingSchedules.Column2 = append(billingSchedules.Column2, int32(week))
-		billingSchedules.Column3 = append(billingSchedules.Column3, loan.InstallmentAmount)
-		billingSchedules.Column4 = append(billingSchedules.Column4, pgtype.Date{Time: dueDate, Valid: true})
-		billingSchedules.Column5 = append(billingSchedules.Column5, false)
-	}
-
-	err = r.queries.WithTx(tx).CreateBillingSchedules(ctx, billingSchedules)
+	err = r.queries.WithTx(tx).CreateBillingSchedules(ctx, billingSchedulesParams(loanID, 1, schedules))
 	if err != nil {
 		log.Printf("failed to create billing schedules: %v", err)
 		return 0, fmt.Errorf("failed to create billing schedules: %w", err)
@@ -187,12 +345,29 @@ func (r *loanRepository) CreateLoan(ctx context.Context, borrowerID uint, loan *
 	return uint(loanID), nil
 }
 
+// billingSchedulesParams lays the unpaid schedule rows of the given version out as the columns CreateBillingSchedules inserts.
+func billingSchedulesParams(loanID int32, version int, schedules []domain.BillingSchedule) billingengine.CreateBillingSchedulesParams {
+	var params billingengine.CreateBillingSchedulesParams
+	for _, schedule := range schedules {
+		params.Column1 = append(params.Column1, loanID)
+		params.Column2 = append(params.Column2, int32(schedule.Period))
+		params.Column3 = append(params.Column3, schedule.Amount.Numeric())
+		params.Column4 = append(params.Column4, schedule.DueDate)
+		params.Column5 = append(params.Column5, false)
+		params.Column6 = append(params.Column6, schedule.Principal.Numeric())
+		params.Column7 = append(params.Column7, schedule.Interest.Numeric())
+		params.Column8 = append(params.Column8, schedule.Fee.Numeric())
+		params.Column9 = append(params.Column9, int32(version))
+	}
+	return params
+}
+
 func (r *loanRepository) CreateBillingSchedule(ctx context.Context, schedule *domain.BillingSchedule) error {
 
 	err := r.queries.CreateBillingSchedule(ctx, billingengine.CreateBillingScheduleParams{
 		LoanID:  int32(schedule.LoanID),
-		Week:    int32(schedule.Week),
-		Amount:  schedule.Amount,
+		Period:  int32(schedule.Period),
+		Amount:  schedule.Amount.Numeric(),
 		DueDate: schedule.DueDate,
 		Paid:    schedule.Paid,
 	})
@@ -210,21 +385,74 @@ func (r *loanRepository) GetBillingSchedule(ctx context.Context, loanID uint) (*
 	if err != nil {
 		return nil, fmt.Errorf("failed to get billing schedule: %w", err)
 	}
-	return &domain.BillingSchedule{
-		ID:      uint(billSchedule.ID),
-		LoanID:  uint(billSchedule.LoanID),
-		Week:    uint(billSchedule.Week),
-		Amount:  billSchedule.Amount,
-		DueDate: billSchedule.DueDate,
-		Paid:    billSchedule.Paid,
-	}, nil
+	return toDomainBillingSchedule(billSchedule)
+}
+
+func (r *loanRepository) GetUnpaidBillingSchedules(ctx context.Context, loanID uint) ([]domain.BillingSchedule, error) {
+	rows, err := r.queries.GetUnpaidBillingSchedules(ctx, int32(loanID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unpaid billing schedules: %w", err)
+	}
+	return toDomainBillingSchedules(rows)
+}
+
+func (r *loanRepository) GetBillingSchedules(ctx context.Context, loanID uint) ([]domain.BillingSchedule, error) {
+	rows, err := r.queries.GetBillingSchedulesByLoanID(ctx, int32(loanID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get billing schedules: %w", err)
+	}
+	return toDomainBillingSchedules(rows)
+}
+
+func toDomainBillingSchedules(rows []billingengine.BillingSchedule) ([]domain.BillingSchedule, error) {
+	result := make([]domain.BillingSchedule, 0, len(rows))
+	for _, row := range rows {
+		schedule, err := toDomainBillingSchedule(row)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *schedule)
+	}
+	return result, nil
+}
+
+func timestamp(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t, Valid: true}
+}
+
+func toDomainBillingSchedule(row billingengine.BillingSchedule) (*domain.BillingSchedule, error) {
+	conv := moneyConverter{}
+	schedule := &domain.BillingSchedule{
+		ID:              uint(row.ID),
+		LoanID:          uint(row.LoanID),
+		Period:          uint(row.Period),
+		Amount:          conv.from(row.Amount),
+		Principal:       conv.from(row.PrincipalAmount),
+		Interest:        conv.from(row.InterestAmount),
+		Fee:             conv.from(row.FeeAmount),
+		DueDate:         row.DueDate,
+		Paid:            row.Paid,
+		PaidAmount:      conv.from(row.PaidAmount),
+		Version:         int(row.Version),
+		SupersededAt:    row.SupersededAt,
+		OriginalDueDate: row.OriginalDueDate,
+		DeferredPeriods: int(row.DeferredPeriods),
+		WaivedInterest:  conv.from(row.WaivedInterest),
+		AccruedInterest: conv.from(row.AccruedInterest),
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert billing schedule amounts: %w", conv.err)
+	}
+	return schedule, nil
 }
 
 func (r *loanRepository) UpdateBillingSchedule(ctx context.Context, schedule *domain.BillingSchedule) error {
 	err := r.queries.UpdateBillingSchedule(ctx, billingengine.UpdateBillingScheduleParams{
-		Paid:   schedule.Paid,
-		LoanID: int32(schedule.LoanID),
-		Week:   int32(schedule.Week),
+		Paid:           schedule.Paid,
+		PaidAmount:     schedule.PaidAmount.Numeric(),
+		WaivedInterest: schedule.WaivedInterest.Numeric(),
+		LoanID:         int32(schedule.LoanID),
+		Period:         int32(schedule.Period),
 	})
 	if err != nil {
 		log.Printf("failed to update billing schedule: %v", err)
@@ -233,36 +461,83 @@ func (r *loanRepository) UpdateBillingSchedule(ctx context.Context, schedule *do
 	return nil
 }
 
-func (r *loanRepository) UpdateRepaymentSchedule(ctx context.Context, loan *domain.Loan) error {
-	tx, err := r.db.Begin(ctx)
+// createPayment records the payment and its allocations, it must run inside the transaction that settles the schedule rows.
+func (r *loanRepository) createPayment(ctx context.Context, q *billingengine.Queries, payment *domain.Payment) error {
+	created, err := q.CreatePayment(ctx, billingengine.CreatePaymentParams{
+		LoanID:       int32(payment.LoanID),
+		Amount:       payment.Amount.Numeric(),
+		RebateAmount: payment.Rebate.Numeric(),
+		PaidAt:       timestamp(r.clock.Now()),
+	})
 	if err != nil {
-		return fmt.Errorf("failed to begin UpdateRepaymentSchedule transaction: %w", err)
+		log.Printf("failed to create payment: %v", err)
+		return fmt.Errorf("failed to create payment: %w", err)
 	}
-	defer tx.Rollback(ctx)
-
-	params := billingengine.UpdateLoanParams{
-		Amount:        loan.Amount,
-		InterestRate:  loan.InterestRate,
-		DurationWeeks: int32(loan.DurationWeeks),
-		Outstanding:   loan.Outstanding,
-		ID:            int32(loan.ID),
+	payment.ID = uint(created.ID)
+	payment.PaidAt = created.PaidAt
+
+	for _, allocation := range payment.Allocations {
+		err = q.CreatePaymentAllocation(ctx, billingengine.CreatePaymentAllocationParams{
+			PaymentID:         created.ID,
+			BillingScheduleID: int32(allocation.BillingScheduleID),
+			Amount:            allocation.Amount.Numeric(),
+			RemainingAmount:   allocation.Remaining.Numeric(),
+			LateFeeID:         pgtype.Int4{Int32: int32(allocation.LateFeeID), Valid: allocation.LateFeeID != 0},
+			PrincipalAmount:   allocation.Principal.Numeric(),
+			InterestAmount:    allocation.Interest.Numeric(),
+			FeeAmount:         allocation.Fee.Numeric(),
+			InterestWaived:    allocation.InterestWaived.Numeric(),
+		})
+		if err != nil {
+			log.Printf("failed to create payment allocation: %v", err)
+			return fmt.Errorf("failed to create payment allocation: %w", err)
+		}
 	}
+	return nil
+}
 
-	err = r.queries.WithTx(tx).UpdateLoan(ctx, params)
+func (r *loanRepository) GetPaymentsByLoanID(ctx context.Context, loanID uint) ([]domain.Payment, error) {
+	payments, err := r.queries.GetPaymentsByLoanID(ctx, int32(loanID))
 	if err != nil {
-		log.Printf("failed to update loan: %v", err)
-		return fmt.Errorf("failed to update loan: %w", err)
+		return nil, fmt.Errorf("failed to get payments: %w", err)
 	}
 
-	err = r.queries.WithTx(tx).UpdateRepaymentSchedule(ctx, int32(loan.ID))
+	allocations, err := r.queries.GetPaymentAllocationsByLoanID(ctx, int32(loanID))
 	if err != nil {
-		return fmt.Errorf("failed to update repayment schedule: %w", err)
+		return nil, fmt.Errorf("failed to get payment allocations: %w", err)
 	}
 
-	err = tx.Commit(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to commit UpdateRepaymentSchedule transaction: %w", err)
+	conv := moneyConverter{}
+	allocationsByPayment := make(map[int32][]domain.PaymentAllocation)
+	for _, allocation := range allocations {
+		allocationsByPayment[allocation.PaymentID] = append(allocationsByPayment[allocation.PaymentID], domain.PaymentAllocation{
+			BillingScheduleID: uint(allocation.BillingScheduleID),
+			Period:            uint(allocation.Period),
+			Amount:            conv.from(allocation.Amount),
+			Principal:         conv.from(allocation.PrincipalAmount),
+			Interest:          conv.from(allocation.InterestAmount),
+			Fee:               conv.from(allocation.FeeAmount),
+			InterestWaived:    conv.from(allocation.InterestWaived),
+			Remaining:         conv.from(allocation.RemainingAmount),
+			LateFeeID:         uint(allocation.LateFeeID.Int32),
+		})
 	}
 
-	return nil
+	result := make([]domain.Payment, 0, len(payments))
+	for _, payment := range payments {
+		result = append(result, domain.Payment{
+			ID:             uint(payment.ID),
+			LoanID:         uint(payment.LoanID),
+			Amount:         conv.from(payment.Amount),
+			Rebate:         conv.from(payment.RebateAmount),
+			PaidAt:         payment.PaidAt,
+			ReversedAt:     payment.ReversedAt,
+			ReversalReason: payment.ReversalReason,
+			Allocations:    allocationsByPayment[payment.ID],
+		})
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert payment amounts: %w", conv.err)
+	}
+	return result, nil
 }
@@ -0,0 +1,262 @@
+package repository_test
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"billing-engine/internal/repository"
+	"billing-engine/internal/usecase"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPool connects to TEST_DATABASE_URL, a database migrated with dbmate, and skips the test when it is not set.
+func testPool(t *testing.T) *pgxpool.Pool {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	pool, err := pgxpool.New(context.Background(), url)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// createTestLoan creates a borrower and a disbursed, interest free loan of 100 per week.
+func createTestLoan(t *testing.T, pool *pgxpool.Pool, loanUsecase usecase.LoanUsecase, weeks int) uint {
+	ctx := context.Background()
+	borrower, err := repository.NewBorrowerRepository(pool).CreateBorrower(ctx, &domain.Borrower{
+		Name:  "Test Borrower",
+		Email: fmt.Sprintf("borrower-%d@example.com", time.Now().UnixNano()),
+		Phone: "0800000000",
+	})
+	require.NoError(t, err)
+	product, err := usecase.NewLoanProductUsecase(repository.NewLoanProductRepository(pool)).CreateLoanProduct(ctx, usecase.LoanProductTerms{
+		Name:         fmt.Sprintf("Interest Free %d", time.Now().UnixNano()),
+		MinAmount:    domain.NewMoneyFromUnits(100),
+		MaxAmount:    domain.NewMoneyFromUnits(int64(100 * weeks)),
+		TenorPeriods: []int{weeks},
+	})
+	require.NoError(t, err)
+
+	loanID, err := loanUsecase.CreateLoan(ctx, usecase.LoanApplication{
+		BorrowerID:   borrower.ID,
+		ProductID:    product.ID,
+		Amount:       domain.NewMoneyFromUnits(int64(100 * weeks)),
+		TenorPeriods: weeks,
+	})
+	require.NoError(t, err)
+	for _, event := range []domain.LoanEvent{domain.LoanEventApprove, domain.LoanEventDisburse} {
+		_, err = loanUsecase.TransitionLoan(ctx, loanID, event, "")
+		require.NoError(t, err)
+	}
+	return loanID
+}
+
+func TestConcurrentPaymentsAreNotLost(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	loanRepo := repository.NewLoanRepository(pool, clock.New())
+	loanUsecase := usecase.NewLoanUsecase(loanRepo, repository.NewLoanProductRepository(pool))
+
+	const weeks = 10
+	loanID := createTestLoan(t, pool, loanUsecase, weeks)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, weeks)
+	for i := 0; i < weeks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(100))
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	outstanding, err := loanUsecase.GetOutstanding(ctx, loanID)
+	require.NoError(t, err)
+	assert.True(t, outstanding.Total.IsZero(), "outstanding is %s", outstanding.Total)
+
+	payments, err := loanRepo.GetPaymentsByLoanID(ctx, loanID)
+	require.NoError(t, err)
+	assert.Len(t, payments, weeks)
+
+	history, err := loanRepo.GetLoanStatusHistory(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusPaidOff, history[len(history)-1].To)
+}
+
+func TestDelinquencyFollowsTheClock(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC))
+	loanRepo := repository.NewLoanRepository(pool, clk)
+	loanUsecase := usecase.NewLoanUsecase(loanRepo, repository.NewLoanProductRepository(pool), usecase.WithClock(clk))
+	loanID := createTestLoan(t, pool, loanUsecase, 10)
+
+	clk.AdvanceDays(8)
+	check, err := loanUsecase.IsDelinquent(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, check.TotalWeek)
+	assert.False(t, check.IsDelinquent)
+
+	// week 3: the installments of weeks 1 and 2 are overdue
+	clk.AdvanceDays(7)
+	check, err = loanUsecase.IsDelinquent(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, check.TotalWeek)
+	assert.Equal(t, "200.00", check.Amount.String())
+	assert.True(t, check.IsDelinquent)
+}
+
+func TestRestructureSupersedesUnpaidSchedule(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	loanRepo := repository.NewLoanRepository(pool, clock.New())
+	loanUsecase := usecase.NewLoanUsecase(loanRepo, repository.NewLoanProductRepository(pool))
+	loanID := createTestLoan(t, pool, loanUsecase, 10)
+	_, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(200))
+	require.NoError(t, err)
+
+	restructure, err := loanUsecase.RestructureLoan(ctx, loanID, usecase.LoanRestructuring{TenorPeriods: 4, Reason: "hardship"})
+	require.NoError(t, err)
+	assert.Equal(t, "800.00", restructure.OutstandingAfter.String())
+
+	schedule, err := loanUsecase.GetSchedule(ctx, loanID)
+	require.NoError(t, err)
+	require.Len(t, schedule, 14)
+	for _, installment := range schedule[:10] {
+		assert.Equal(t, 1, installment.Version)
+		assert.Equal(t, !installment.Paid.Bool, installment.SupersededAt.Valid, "period %d", installment.Period)
+	}
+	for i, installment := range schedule[10:] {
+		assert.Equal(t, 2, installment.Version)
+		assert.Equal(t, uint(3+i), installment.Period)
+		assert.Equal(t, "200.00", installment.Amount.String())
+	}
+
+	// payments only settle the new schedule
+	payment, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(200))
+	require.NoError(t, err)
+	assert.Equal(t, schedule[10].ID, payment.Allocations[0].BillingScheduleID)
+	outstanding, err := loanUsecase.GetOutstanding(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, "600.00", outstanding.Total.String())
+}
+
+func TestPaymentHolidayIsNotInArrears(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC))
+	loanRepo := repository.NewLoanRepository(pool, clk)
+	loanUsecase := usecase.NewLoanUsecase(loanRepo, repository.NewLoanProductRepository(pool), usecase.WithClock(clk))
+	loanID := createTestLoan(t, pool, loanUsecase, 10)
+
+	holiday, err := loanUsecase.GrantPaymentHoliday(ctx, loanID, usecase.PaymentHolidayRequest{Periods: 2})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), holiday.FromPeriod)
+
+	// week 3: the installments of weeks 1 and 2 were deferred and are not due yet
+	clk.AdvanceDays(15)
+	check, err := loanUsecase.IsDelinquent(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, check.TotalWeek)
+
+	clk.AdvanceDays(7)
+	check, err = loanUsecase.IsDelinquent(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, check.TotalWeek)
+}
+
+func TestReversePaymentReopensLoan(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC))
+	loanRepo := repository.NewLoanRepository(pool, clk)
+	loanUsecase := usecase.NewLoanUsecase(loanRepo, repository.NewLoanProductRepository(pool), usecase.WithClock(clk))
+	loanID := createTestLoan(t, pool, loanUsecase, 2)
+
+	payment, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(200))
+	require.NoError(t, err)
+	loan, err := loanRepo.GetLoanByID(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusPaidOff, loan.Status)
+
+	// two weeks later both installments are overdue again
+	clk.AdvanceDays(21)
+	reversed, err := loanUsecase.ReversePayment(ctx, loanID, payment.ID, "cheque bounced")
+	require.NoError(t, err)
+	assert.True(t, reversed.ReversedAt.Valid)
+	_, err = loanUsecase.ReversePayment(ctx, loanID, payment.ID, "cheque bounced")
+	assert.ErrorIs(t, err, domain.ErrPaymentAlreadyReversed)
+
+	loan, err = loanRepo.GetLoanByID(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusDelinquent, loan.Status)
+	assert.Equal(t, "200.00", loan.Outstanding.String())
+	assert.Equal(t, 2, loan.DelinquentWeeks)
+	unpaid, err := loanRepo.GetUnpaidBillingSchedules(ctx, loanID)
+	require.NoError(t, err)
+	assert.Len(t, unpaid, 2)
+	payments, err := loanUsecase.GetPayments(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, "cheque bounced", payments[0].ReversalReason)
+}
+
+func TestWriteOffLoanTakesRecoveries(t *testing.T) {
+	pool := testPool(t)
+	ctx := context.Background()
+	clk := clock.NewFake(time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC))
+	loanRepo := repository.NewLoanRepository(pool, clk)
+	loanUsecase := usecase.NewLoanUsecase(loanRepo, repository.NewLoanProductRepository(pool), usecase.WithClock(clk))
+	loanID := createTestLoan(t, pool, loanUsecase, 4)
+
+	_, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(100))
+	require.NoError(t, err)
+	writeOff, err := loanUsecase.WriteOffLoan(ctx, loanID, "uncollectable")
+	require.NoError(t, err)
+	assert.Equal(t, "300.00", writeOff.Amount.String())
+
+	loan, err := loanRepo.GetLoanByID(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusWrittenOff, loan.Status)
+	assert.True(t, loan.Outstanding.IsZero())
+	_, err = loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(100))
+	assert.ErrorIs(t, err, domain.ErrLoanNotActive)
+
+	_, err = loanUsecase.RecordRecovery(ctx, loanID, domain.NewMoneyFromUnits(120))
+	require.NoError(t, err)
+	_, err = loanUsecase.RecordRecovery(ctx, loanID, domain.NewMoneyFromUnits(200))
+	assert.ErrorIs(t, err, domain.ErrInvalidRecovery)
+
+	stored, err := loanUsecase.GetWriteOff(ctx, loanID)
+	require.NoError(t, err)
+	assert.Equal(t, "120.00", stored.Recovered.String())
+	recoveries, err := loanUsecase.GetRecoveries(ctx, loanID)
+	require.NoError(t, err)
+	assert.Len(t, recoveries, 1)
+	payments, err := loanUsecase.GetPayments(ctx, loanID)
+	require.NoError(t, err)
+	assert.Len(t, payments, 1)
+}
+
+func TestIsDelinquentOfUnknownLoan(t *testing.T) {
+	pool := testPool(t)
+	loanRepo := repository.NewLoanRepository(pool, clock.New())
+
+	_, err := loanRepo.IsDelinquent(context.Background(), 0, time.Now())
+
+	assert.ErrorIs(t, err, domain.ErrLoanNotFound)
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"fmt"
+	"log"
+)
+
+func (r *loanRepository) RestructureLoan(ctx context.Context, loan *domain.Loan, restructure *domain.LoanRestructure) error {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := timestamp(r.clock.Now())
+	q := r.queries.WithTx(tx)
+	superseded, err := q.SupersedeBillingSchedules(ctx, billingengine.SupersedeBillingSchedulesParams{
+		SupersededAt: now,
+		LoanID:       int32(loan.ID),
+	})
+	if err != nil {
+		log.Printf("failed to supersede billing schedules: %v", err)
+		return fmt.Errorf("failed to supersede billing schedules: %w", err)
+	}
+	if superseded == 0 {
+		return domain.ErrLoanFullyPaid
+	}
+
+	err = q.CreateBillingSchedules(ctx, billingSchedulesParams(int32(loan.ID), restructure.ToVersion, restructure.Schedule))
+	if err != nil {
+		log.Printf("failed to create billing schedules: %v", err)
+		return fmt.Errorf("failed to create billing schedules: %w", err)
+	}
+
+	err = q.UpdateLoanTerms(ctx, billingengine.UpdateLoanTermsParams{
+		InterestRate:       loan.InterestRate,
+		InterestMethod:     string(loan.InterestMethod),
+		TenorPeriods:       int32(loan.TenorPeriods),
+		RepaymentFrequency: string(loan.RepaymentFrequency),
+		Outstanding:        loan.Outstanding.Numeric(),
+		InstallmentAmount:  loan.InstallmentAmount.Numeric(),
+		DelinquentWeeks:    int32(loan.DelinquentWeeks),
+		ID:                 int32(loan.ID),
+	})
+	if err != nil {
+		log.Printf("failed to update loan terms: %v", err)
+		return fmt.Errorf("failed to update loan terms: %w", err)
+	}
+
+	created, err := q.CreateLoanRestructure(ctx, billingengine.CreateLoanRestructureParams{
+		LoanID:             int32(loan.ID),
+		FromVersion:        int32(restructure.FromVersion),
+		ToVersion:          int32(restructure.ToVersion),
+		OutstandingBefore:  restructure.OutstandingBefore.Numeric(),
+		Principal:          restructure.Principal.Numeric(),
+		OutstandingAfter:   restructure.OutstandingAfter.Numeric(),
+		InterestRate:       restructure.InterestRate,
+		InterestMethod:     string(restructure.InterestMethod),
+		TenorPeriods:       int32(restructure.TenorPeriods),
+		RepaymentFrequency: string(restructure.RepaymentFrequency),
+		Reason:             restructure.Reason,
+		RestructuredAt:     now,
+	})
+	if err != nil {
+		log.Printf("failed to create loan restructure: %v", err)
+		return fmt.Errorf("failed to create loan restructure: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit RestructureLoan transaction: %w", err)
+	}
+	restructure.ID = uint(created.ID)
+	restructure.RestructuredAt = created.RestructuredAt
+	for i := range restructure.Schedule {
+		restructure.Schedule[i].LoanID = loan.ID
+		restructure.Schedule[i].Version = restructure.ToVersion
+	}
+	return nil
+}
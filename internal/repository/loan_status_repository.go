@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (r *loanRepository) TransitionLoanStatus(ctx context.Context, transition *domain.LoanStatusTransition) error {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	now := r.clock.Now()
+	var closedAt pgtype.Timestamp
+	if transition.To.IsClosed() {
+		closedAt = timestamp(now)
+	}
+
+	q := r.queries.WithTx(tx)
+	updated, err := q.UpdateLoanStatus(ctx, billingengine.UpdateLoanStatusParams{
+		ToStatus:   string(transition.To),
+		ClosedAt:   closedAt,
+		ID:         int32(transition.LoanID),
+		FromStatus: string(transition.From),
+	})
+	if err != nil {
+		log.Printf("failed to update loan status: %v", err)
+		return fmt.Errorf("failed to update loan status: %w", err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("%w: loan %d is no longer %s", domain.ErrInvalidTransition, transition.LoanID, transition.From)
+	}
+
+	created, err := q.CreateLoanStatusHistory(ctx, billingengine.CreateLoanStatusHistoryParams{
+		LoanID:         int32(transition.LoanID),
+		FromStatus:     string(transition.From),
+		ToStatus:       string(transition.To),
+		Event:          string(transition.Event),
+		Reason:         transition.Reason,
+		TransitionedAt: timestamp(now),
+	})
+	if err != nil {
+		log.Printf("failed to create loan status history: %v", err)
+		return fmt.Errorf("failed to create loan status history: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit TransitionLoanStatus transaction: %w", err)
+	}
+	transition.ID = uint(created.ID)
+	transition.TransitionedAt = created.TransitionedAt
+	return nil
+}
+
+func (r *loanRepository) GetLoanStatusHistory(ctx context.Context, loanID uint) ([]domain.LoanStatusTransition, error) {
+	rows, err := r.queries.GetLoanStatusHistory(ctx, int32(loanID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan status history: %w", err)
+	}
+
+	result := make([]domain.LoanStatusTransition, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, domain.LoanStatusTransition{
+			ID:             uint(row.ID),
+			LoanID:         uint(row.LoanID),
+			From:           domain.LoanStatus(row.FromStatus),
+			To:             domain.LoanStatus(row.ToStatus),
+			Event:          domain.LoanEvent(row.Event),
+			Reason:         row.Reason,
+			TransitionedAt: row.TransitionedAt,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// moneyConverter converts a row's numeric columns to domain.Money, keeping the first conversion error
+// so a whole row can be mapped before checking err once.
+type moneyConverter struct {
+	err error
+}
+
+func (c *moneyConverter) from(n pgtype.Numeric) domain.Money {
+	if c.err != nil {
+		return domain.Money{}
+	}
+	m, err := domain.MoneyFromNumeric(n)
+	if err != nil {
+		c.err = err
+	}
+	return m
+}
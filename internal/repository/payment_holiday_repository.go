@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"fmt"
+	"log"
+)
+
+func (r *loanRepository) DeferBillingSchedules(ctx context.Context, loan *domain.Loan, holiday *domain.PaymentHoliday) error {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	for _, schedule := range holiday.Schedule {
+		err = q.DeferBillingSchedule(ctx, billingengine.DeferBillingScheduleParams{
+			DueDate:         schedule.DueDate,
+			DeferredPeriods: int32(holiday.Periods),
+			InterestAmount:  schedule.Interest.Numeric(),
+			Amount:          schedule.Amount.Numeric(),
+			ID:              int32(schedule.ID),
+		})
+		if err != nil {
+			log.Printf("failed to defer billing schedule: %v", err)
+			return fmt.Errorf("failed to defer billing schedule: %w", err)
+		}
+	}
+
+	err = q.UpdateLoan(ctx, billingengine.UpdateLoanParams{
+		Amount:          loan.Amount.Numeric(),
+		InterestRate:    loan.InterestRate,
+		TenorPeriods:    int32(loan.TenorPeriods),
+		Outstanding:     loan.Outstanding.Numeric(),
+		DelinquentWeeks: int32(loan.DelinquentWeeks),
+		ID:              int32(loan.ID),
+	})
+	if err != nil {
+		log.Printf("failed to update loan: %v", err)
+		return fmt.Errorf("failed to update loan: %w", err)
+	}
+
+	created, err := q.CreatePaymentHoliday(ctx, billingengine.CreatePaymentHolidayParams{
+		LoanID:              int32(loan.ID),
+		FromPeriod:          int32(holiday.FromPeriod),
+		Periods:             int32(holiday.Periods),
+		StartDate:           holiday.StartDate,
+		EndDate:             holiday.EndDate,
+		CapitalisedInterest: holiday.CapitalisedInterest.Numeric(),
+		Reason:              holiday.Reason,
+	})
+	if err != nil {
+		log.Printf("failed to create payment holiday: %v", err)
+		return fmt.Errorf("failed to create payment holiday: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit DeferBillingSchedules transaction: %w", err)
+	}
+	holiday.ID = uint(created.ID)
+	holiday.CreatedAt = created.Createdat
+	return nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"fmt"
+	"log"
+)
+
+func (r *loanRepository) ReversePayment(ctx context.Context, loan *domain.Loan, schedules []domain.BillingSchedule, payment *domain.Payment) error {
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	err = q.UpdateLoan(ctx, billingengine.UpdateLoanParams{
+		Amount:          loan.Amount.Numeric(),
+		InterestRate:    loan.InterestRate,
+		TenorPeriods:    int32(loan.TenorPeriods),
+		Outstanding:     loan.Outstanding.Numeric(),
+		DelinquentWeeks: int32(loan.DelinquentWeeks),
+		ID:              int32(loan.ID),
+	})
+	if err != nil {
+		log.Printf("failed to update loan: %v", err)
+		return fmt.Errorf("failed to update loan: %w", err)
+	}
+
+	for _, schedule := range schedules {
+		err = q.UpdateBillingSchedule(ctx, billingengine.UpdateBillingScheduleParams{
+			Paid:           schedule.Paid,
+			PaidAmount:     schedule.PaidAmount.Numeric(),
+			WaivedInterest: schedule.WaivedInterest.Numeric(),
+			LoanID:         int32(schedule.LoanID),
+			Period:         int32(schedule.Period),
+		})
+		if err != nil {
+			log.Printf("failed to update billing schedule: %v", err)
+			return fmt.Errorf("failed to update billing schedule: %w", err)
+		}
+	}
+
+	reversedAt := timestamp(r.clock.Now())
+	reversed, err := q.ReversePayment(ctx, billingengine.ReversePaymentParams{
+		ReversedAt:     reversedAt,
+		ReversalReason: payment.ReversalReason,
+		ID:             int32(payment.ID),
+		LoanID:         int32(loan.ID),
+	})
+	if err != nil {
+		log.Printf("failed to reverse payment: %v", err)
+		return fmt.Errorf("failed to reverse payment: %w", err)
+	}
+	if reversed == 0 {
+		return fmt.Errorf("%w: payment %d", domain.ErrPaymentAlreadyReversed, payment.ID)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit ReversePayment transaction: %w", err)
+	}
+	payment.ReversedAt = reversedAt
+	return nil
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/sql/billingengine"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *loanRepository) WriteOffLoan(ctx context.Context, loan *domain.Loan, writeOff *domain.LoanWriteOff) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	err = q.UpdateLoan(ctx, billingengine.UpdateLoanParams{
+		Amount:          loan.Amount.Numeric(),
+		InterestRate:    loan.InterestRate,
+		TenorPeriods:    int32(loan.TenorPeriods),
+		Outstanding:     loan.Outstanding.Numeric(),
+		DelinquentWeeks: int32(loan.DelinquentWeeks),
+		ID:              int32(loan.ID),
+	})
+	if err != nil {
+		log.Printf("failed to update loan: %v", err)
+		return fmt.Errorf("failed to update loan: %w", err)
+	}
+
+	created, err := q.CreateLoanWriteOff(ctx, billingengine.CreateLoanWriteOffParams{
+		LoanID:       int32(writeOff.LoanID),
+		Principal:    writeOff.Principal.Numeric(),
+		Interest:     writeOff.Interest.Numeric(),
+		Fee:          writeOff.Fee.Numeric(),
+		LateFees:     writeOff.LateFees.Numeric(),
+		Amount:       writeOff.Amount.Numeric(),
+		Reason:       writeOff.Reason,
+		Automatic:    writeOff.Automatic,
+		WrittenOffAt: timestamp(r.clock.Now()),
+	})
+	if err != nil {
+		log.Printf("failed to create loan write-off: %v", err)
+		return fmt.Errorf("failed to create loan write-off: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit WriteOffLoan transaction: %w", err)
+	}
+	writeOff.ID = uint(created.ID)
+	writeOff.WrittenOffAt = created.WrittenOffAt
+	return nil
+}
+
+func (r *loanRepository) GetLoanWriteOff(ctx context.Context, loanID uint) (*domain.LoanWriteOff, error) {
+	row, err := r.queries.GetLoanWriteOffByLoanID(ctx, int32(loanID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrWriteOffNotFound
+		}
+		log.Printf("failed to get loan write-off: %v", err)
+		return nil, err
+	}
+	return toDomainWriteOff(row)
+}
+
+func (r *loanRepository) RecordRecovery(ctx context.Context, writeOff *domain.LoanWriteOff, recovery *domain.Recovery) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	q := r.queries.WithTx(tx)
+	created, err := q.CreateRecovery(ctx, billingengine.CreateRecoveryParams{
+		LoanID:      int32(recovery.LoanID),
+		Amount:      recovery.Amount.Numeric(),
+		RecoveredAt: timestamp(r.clock.Now()),
+	})
+	if err != nil {
+		log.Printf("failed to create recovery: %v", err)
+		return fmt.Errorf("failed to create recovery: %w", err)
+	}
+
+	err = q.UpdateLoanWriteOffRecoveredAmount(ctx, billingengine.UpdateLoanWriteOffRecoveredAmountParams{
+		RecoveredAmount: writeOff.Recovered.Numeric(),
+		ID:              int32(writeOff.ID),
+	})
+	if err != nil {
+		log.Printf("failed to update recovered amount of loan write-off: %v", err)
+		return fmt.Errorf("failed to update recovered amount of loan write-off: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to commit RecordRecovery transaction: %w", err)
+	}
+	recovery.ID = uint(created.ID)
+	recovery.RecoveredAt = created.RecoveredAt
+	return nil
+}
+
+func (r *loanRepository) GetRecoveries(ctx context.Context, loanID uint) ([]domain.Recovery, error) {
+	rows, err := r.queries.GetRecoveriesByLoanID(ctx, int32(loanID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recoveries: %w", err)
+	}
+
+	recoveries := make([]domain.Recovery, 0, len(rows))
+	for _, row := range rows {
+		recovery, err := toDomainRecovery(billingengine.GetRecoveriesBetweenRow(row))
+		if err != nil {
+			return nil, err
+		}
+		recoveries = append(recoveries, *recovery)
+	}
+	return recoveries, nil
+}
+
+func (r *ledgerRepository) GetWriteOffs(ctx context.Context, from, to time.Time) ([]domain.LoanWriteOff, error) {
+	rows, err := r.queries.GetLoanWriteOffsBetween(ctx, billingengine.GetLoanWriteOffsBetweenParams{
+		FromTime: timestamp(from),
+		ToTime:   timestamp(to),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan write-offs: %w", err)
+	}
+
+	writeOffs := make([]domain.LoanWriteOff, 0, len(rows))
+	for _, row := range rows {
+		writeOff, err := toDomainWriteOff(billingengine.GetLoanWriteOffByLoanIDRow(row))
+		if err != nil {
+			return nil, err
+		}
+		writeOffs = append(writeOffs, *writeOff)
+	}
+	return writeOffs, nil
+}
+
+func (r *ledgerRepository) GetRecoveries(ctx context.Context, from, to time.Time) ([]domain.Recovery, error) {
+	rows, err := r.queries.GetRecoveriesBetween(ctx, billingengine.GetRecoveriesBetweenParams{
+		FromTime: timestamp(from),
+		ToTime:   timestamp(to),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recoveries: %w", err)
+	}
+
+	recoveries := make([]domain.Recovery, 0, len(rows))
+	for _, row := range rows {
+		recovery, err := toDomainRecovery(row)
+		if err != nil {
+			return nil, err
+		}
+		recoveries = append(recoveries, *recovery)
+	}
+	return recoveries, nil
+}
+
+func toDomainWriteOff(row billingengine.GetLoanWriteOffByLoanIDRow) (*domain.LoanWriteOff, error) {
+	conv := moneyConverter{}
+	writeOff := &domain.LoanWriteOff{
+		ID:           uint(row.ID),
+		LoanID:       uint(row.LoanID),
+		Principal:    conv.from(row.Principal),
+		Interest:     conv.from(row.Interest),
+		Fee:          conv.from(row.Fee),
+		LateFees:     conv.from(row.LateFees),
+		Amount:       conv.from(row.Amount),
+		Recovered:    conv.from(row.RecoveredAmount),
+		Reason:       row.Reason,
+		Automatic:    row.Automatic,
+		WrittenOffAt: row.WrittenOffAt,
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert loan write-off amounts: %w", conv.err)
+	}
+	return writeOff, nil
+}
+
+func toDomainRecovery(row billingengine.GetRecoveriesBetweenRow) (*domain.Recovery, error) {
+	conv := moneyConverter{}
+	recovery := &domain.Recovery{
+		ID:          uint(row.ID),
+		LoanID:      uint(row.LoanID),
+		Amount:      conv.from(row.Amount),
+		RecoveredAt: row.RecoveredAt,
+	}
+	if conv.err != nil {
+		return nil, fmt.Errorf("failed to convert recovery amount: %w", conv.err)
+	}
+	return recovery, nil
+}
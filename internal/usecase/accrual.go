@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (lu *loanUsecase) AccrueInterest(ctx context.Context, loanID uint, asOf time.Time) (*domain.InterestAccrual, error) {
+	var accrual *domain.InterestAccrual
+	err := lu.loanRepo.WithinTransaction(ctx, func(repo domain.LoanRepository) error {
+		loan, err := repo.GetLoanByIDForUpdate(ctx, loanID)
+		if err != nil {
+			return err
+		}
+		// closed and written off loans earn no more interest
+		if !loan.Status.AcceptsPayments() {
+			return nil
+		}
+		accrual, err = lu.accrueInterest(ctx, repo, loan, asOf)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return accrual, nil
+}
+
+func (lu *loanUsecase) GetInterestAccruals(ctx context.Context, loanID uint) ([]domain.InterestAccrual, error) {
+	if _, err := lu.loanRepo.GetLoanByID(ctx, loanID); err != nil {
+		return nil, err
+	}
+	return lu.loanRepo.GetInterestAccruals(ctx, loanID)
+}
+
+// accrueInterest brings the accrued interest of every current installment of the loan up to asOf and earns the
+// difference, repo must be the unit of work holding the loan row lock. Running it twice for one day earns nothing
+// the second time.
+func (lu *loanUsecase) accrueInterest(ctx context.Context, repo domain.LoanRepository, loan *domain.Loan, asOf time.Time) (*domain.InterestAccrual, error) {
+	all, err := repo.GetBillingSchedules(ctx, loan.ID)
+	if err != nil {
+		return nil, fmt.Errorf("loan usecase: failed to get billing schedules: %w", err)
+	}
+	schedules := currentSchedules(all)
+	accrued := lu.accruedInterest(loan, schedules, asOf)
+
+	amount := domain.Money{}
+	total := domain.Money{}
+	var changed []domain.BillingSchedule
+	for i := range schedules {
+		total = total.Add(accrued[i])
+		if delta := accrued[i].Sub(schedules[i].AccruedInterest); !delta.IsZero() {
+			amount = amount.Add(delta)
+			schedules[i].AccruedInterest = accrued[i]
+			changed = append(changed, schedules[i])
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	loan.AccruedInterest = total
+	accrual := &domain.InterestAccrual{
+		LoanID:        loan.ID,
+		AccrualDate:   pgtype.Date{Time: time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC), Valid: true},
+		Method:        lu.accrual,
+		Amount:        amount,
+		AccruedToDate: total,
+	}
+	if err := repo.AccrueInterest(ctx, loan, changed, accrual); err != nil {
+		return nil, err
+	}
+	if err := postJournalEntries(ctx, repo, accrualEntry(accrual)); err != nil {
+		return nil, err
+	}
+	return accrual, nil
+}
+
+// currentSchedules keeps the rows of schedules a restructure did not supersede, by due date.
+func currentSchedules(schedules []domain.BillingSchedule) []domain.BillingSchedule {
+	current := make([]domain.BillingSchedule, 0, len(schedules))
+	for _, schedule := range schedules {
+		if !schedule.SupersededAt.Valid {
+			current = append(current, schedule)
+		}
+	}
+	sort.SliceStable(current, func(i, j int) bool { return current[i].DueDate.Time.Before(current[j].DueDate.Time) })
+	return current
+}
+
+// accruedInterest returns the interest earned by asOf on every one of schedules, which are the current rows of
+// the loan by due date. The interest of a row is earned evenly from the due date of the row before it, or the
+// disbursement date for the first one, up to its own due date.
+func (lu *loanUsecase) accruedInterest(loan *domain.Loan, schedules []domain.BillingSchedule, asOf time.Time) []domain.Money {
+	earned := make([]domain.Money, len(schedules))
+	for i, schedule := range schedules {
+		earned[i] = schedule.Interest
+	}
+	if lu.accrual == domain.AccrualEffectiveInterest {
+		earned = effectiveInterest(schedules, lu.rounding)
+	}
+
+	accrued := make([]domain.Money, len(schedules))
+	for i, schedule := range schedules {
+		start := loan.RepaymentFrequency.DueDate(schedule.DueDate.Time, -1)
+		switch {
+		case i > 0:
+			start = schedules[i-1].DueDate.Time
+		case loan.DisbursementDate.Valid:
+			start = loan.DisbursementDate.Time
+		}
+
+		days, elapsed := daysBetween(start, schedule.DueDate.Time), daysBetween(start, asOf)
+		switch {
+		case elapsed >= days:
+			accrued[i] = earned[i]
+		case elapsed > 0:
+			accrued[i] = earned[i].Mul(big.NewRat(int64(elapsed), int64(days)), lu.rounding)
+		}
+	}
+	return accrued
+}
+
+// effectiveInterest spreads the interest of schedules over their periods at the constant rate per period that
+// discounts their principal and interest to their principal, charged on the balance still owed. The last period
+// takes the rounding difference so the total interest is unchanged.
+func effectiveInterest(schedules []domain.BillingSchedule, mode domain.RoundingMode) []domain.Money {
+	earned := make([]domain.Money, len(schedules))
+	principal, interest := domain.Money{}, domain.Money{}
+	flows := make([]float64, len(schedules))
+	for i, schedule := range schedules {
+		earned[i] = schedule.Interest
+		principal = principal.Add(schedule.Principal)
+		interest = interest.Add(schedule.Interest)
+		flows[i], _ = schedule.Principal.Add(schedule.Interest).Rat().Float64()
+	}
+	if principal.Sign() <= 0 || interest.Sign() <= 0 {
+		return earned
+	}
+
+	present, _ := principal.Rat().Float64()
+	rate := new(big.Rat).SetFloat64(internalRate(flows, present))
+	balance, total := principal, domain.Money{}
+	for i, schedule := range schedules {
+		if i == len(schedules)-1 {
+			earned[i] = interest.Sub(total)
+			break
+		}
+		earned[i] = balance.Mul(rate, mode)
+		total = total.Add(earned[i])
+		balance = balance.Add(earned[i]).Sub(schedule.Principal.Add(schedule.Interest))
+	}
+	return earned
+}
+
+// internalRate finds the rate per period at which flows, one per period, discount to present. flows must add up
+// to more than present, the rate is then positive.
+func internalRate(flows []float64, present float64) float64 {
+	discounted := func(rate float64) float64 {
+		value, factor := 0.0, 1.0
+		for _, flow := range flows {
+			factor /= 1 + rate
+			value += flow * factor
+		}
+		return value
+	}
+
+	low, high := 0.0, 1.0
+	for i := 0; i < 64 && discounted(high) > present; i++ {
+		low, high = high, high*2
+	}
+	for i := 0; i < 100; i++ {
+		mid := (low + high) / 2
+		if discounted(mid) > present {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// accrualFixture is a weekly loan disbursed on the 1st of June 2024 with two installments of 700 interest each.
+func accrualFixture() (*domain.Loan, []domain.BillingSchedule) {
+	loan := &domain.Loan{
+		ID:                 1,
+		Status:             domain.LoanStatusActive,
+		RepaymentFrequency: domain.FrequencyWeekly,
+		DisbursementDate:   pgtype.Date{Time: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+	}
+	schedules := []domain.BillingSchedule{
+		{ID: 11, LoanID: 1, Period: 1, Principal: domain.NewMoneyFromUnits(10000), Interest: domain.NewMoneyFromUnits(700), DueDate: pgtype.Date{Time: time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC), Valid: true}},
+		{ID: 12, LoanID: 1, Period: 2, Principal: domain.NewMoneyFromUnits(10000), Interest: domain.NewMoneyFromUnits(700), DueDate: pgtype.Date{Time: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), Valid: true}},
+	}
+	return loan, schedules
+}
+
+func TestAccrueInterestEarnsTheDaysSinceTheLastRun(t *testing.T) {
+	asOf := time.Date(2024, 6, 11, 0, 30, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(asOf)))
+	ctx := context.Background()
+	loan, schedules := accrualFixture()
+	schedules[0].AccruedInterest = domain.NewMoneyFromUnits(400)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("AccrueInterest", ctx, loan, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	accrual, err := loanUsecase.AccrueInterest(ctx, 1, asOf)
+
+	assert.NoError(t, err)
+	// the first week is fully earned, three of the seven days of the second one
+	assert.Equal(t, "600.00", accrual.Amount.String())
+	assert.Equal(t, "1000.00", accrual.AccruedToDate.String())
+	assert.Equal(t, "1000.00", loan.AccruedInterest.String())
+	assert.Equal(t, domain.AccrualStraightLine, accrual.Method)
+	assert.Equal(t, time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC), accrual.AccrualDate.Time)
+
+	stored := mockRepo.lastCall("AccrueInterest").Get(2).([]domain.BillingSchedule)
+	assert.Len(t, stored, 2)
+	assert.Equal(t, "700.00", stored[0].AccruedInterest.String())
+	assert.Equal(t, "300.00", stored[1].AccruedInterest.String())
+
+	entries := mockRepo.postedEntries()
+	assert.Len(t, entries, 1)
+	balances := domain.LedgerBalances(entries)
+	assert.Equal(t, "600.00", balances[domain.AccountUnearnedIncome].String())
+	assert.Equal(t, "-600.00", balances[domain.AccountInterestIncome].String())
+}
+
+func TestAccrueInterestTwiceOnOneDayEarnsNothing(t *testing.T) {
+	asOf := time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(asOf)))
+	ctx := context.Background()
+	loan, schedules := accrualFixture()
+	schedules[0].AccruedInterest = domain.NewMoneyFromUnits(700)
+	schedules[1].AccruedInterest = domain.NewMoneyFromUnits(300)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+
+	accrual, err := loanUsecase.AccrueInterest(ctx, 1, asOf.Add(20*time.Hour))
+
+	assert.NoError(t, err)
+	assert.Nil(t, accrual)
+	mockRepo.AssertNotCalled(t, "AccrueInterest", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "PostJournalEntry", mock.Anything, mock.Anything)
+}
+
+func TestAccrueInterestSkipsClosedLoans(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loan, _ := accrualFixture()
+	loan.Status = domain.LoanStatusWrittenOff
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+
+	accrual, err := loanUsecase.AccrueInterest(ctx, 1, time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC))
+
+	assert.NoError(t, err)
+	assert.Nil(t, accrual)
+	mockRepo.AssertNotCalled(t, "GetBillingSchedules", mock.Anything, mock.Anything)
+}
+
+func TestEffectiveInterestFrontLoadsFlatInterest(t *testing.T) {
+	schedules := FlatInterest{}.Schedule(ScheduleTerms{
+		Principal: domain.NewMoneyFromUnits(1000),
+		Rate:      big.NewRat(1, 10),
+		Periods:   4,
+		Frequency: domain.FrequencyWeekly,
+	}).Installments
+
+	earned := effectiveInterest(schedules, domain.RoundHalfUp)
+
+	assert.Len(t, earned, 4)
+	assert.Equal(t, "100.00", domain.SumMoney(earned...).String())
+	for i := 1; i < len(earned); i++ {
+		assert.True(t, earned[i].Cmp(earned[i-1]) < 0, "period %d earns %s after %s", i+1, earned[i], earned[i-1])
+	}
+	// 275 a week repays 1000 at about 3.924% a week
+	assert.Equal(t, "39.24", earned[0].String())
+}
+
+func TestAccrualRunAccruesActiveLoans(t *testing.T) {
+	now := time.Date(2024, 6, 11, 0, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(now)
+	mockRepo := new(MockLoanRepository)
+	accrualUsecase := NewAccrualUsecase(mockRepo, NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clk)), clk, 10)
+	ctx := context.Background()
+	loan, schedules := accrualFixture()
+	statuses := []domain.LoanStatus{domain.LoanStatusActive, domain.LoanStatusDelinquent}
+
+	mockRepo.On("ListLoanIDsByStatus", ctx, statuses, uint(0), 10).Return([]uint{1}, nil)
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("AccrueInterest", ctx, loan, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	run, err := accrualUsecase.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.JobSucceeded, run.Status)
+	assert.Equal(t, 1, run.LoansChecked)
+	assert.Equal(t, 1, run.LoansAccrued)
+	assert.Equal(t, "1000.00", run.InterestAccrued.String())
+	assert.Equal(t, run, accrualUsecase.LastRun())
+}
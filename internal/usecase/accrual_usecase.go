@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"time"
+)
+
+type AccrualUsecase interface {
+	// Run earns the interest accrued up to today on every active or delinquent loan.
+	Run(ctx context.Context) (*domain.AccrualRun, error)
+	// LastRun returns the latest run, which may still be in progress, or nil before the first run.
+	LastRun() *domain.AccrualRun
+}
+
+type accrualUsecase struct {
+	*loanJob[domain.AccrualRun, *domain.AccrualRun]
+	loans LoanUsecase
+}
+
+// NewAccrualUsecase lists the loans to accrue from lr and accrues each of them through lu.
+func NewAccrualUsecase(lr domain.LoanRepository, lu LoanUsecase, clk clock.Clock, batchSize int) AccrualUsecase {
+	au := &accrualUsecase{loans: lu}
+	au.loanJob = newLoanJob[domain.AccrualRun]("accrual job", lr, clk, batchSize, au.accrue)
+	return au
+}
+
+func (au *accrualUsecase) accrue(ctx context.Context, loanID uint, asOf time.Time) (func(run *domain.AccrualRun), error) {
+	accrual, err := au.loans.AccrueInterest(ctx, loanID, asOf)
+	return func(run *domain.AccrualRun) {
+		if accrual != nil {
+			run.LoansAccrued++
+			run.InterestAccrued = run.InterestAccrued.Add(accrual.Amount)
+		}
+	}, err
+}
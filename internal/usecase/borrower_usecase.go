@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+type BorrowerUsecase interface {
+	CreateBorrower(ctx context.Context, name, email, phone string) (*domain.Borrower, error)
+	GetBorrower(ctx context.Context, borrowerID uint) (*domain.Borrower, error)
+	ListBorrowers(ctx context.Context, limit, offset uint) ([]domain.Borrower, error)
+	UpdateBorrower(ctx context.Context, borrowerID uint, update *domain.BorrowerUpdate) (*domain.Borrower, error)
+	DeleteBorrower(ctx context.Context, borrowerID uint) error
+	GetBorrowerLoans(ctx context.Context, borrowerID uint) ([]domain.Loan, error)
+}
+
+type borrowerUsecase struct {
+	borrowerRepo domain.BorrowerRepository
+	loanRepo     domain.LoanRepository
+}
+
+func NewBorrowerUsecase(br domain.BorrowerRepository, lr domain.LoanRepository) BorrowerUsecase {
+	return &borrowerUsecase{borrowerRepo: br, loanRepo: lr}
+}
+
+func (bu *borrowerUsecase) CreateBorrower(ctx context.Context, name, email, phone string) (*domain.Borrower, error) {
+	borrower := &domain.Borrower{
+		Name:  strings.TrimSpace(name),
+		Email: strings.TrimSpace(email),
+		Phone: strings.TrimSpace(phone),
+	}
+	if borrower.Name == "" || borrower.Email == "" || borrower.Phone == "" {
+		return nil, fmt.Errorf("%w: name, email and phone are required", domain.ErrInvalidBorrower)
+	}
+	if err := validateEmail(borrower.Email); err != nil {
+		return nil, err
+	}
+	return bu.borrowerRepo.CreateBorrower(ctx, borrower)
+}
+
+func (bu *borrowerUsecase) GetBorrower(ctx context.Context, borrowerID uint) (*domain.Borrower, error) {
+	return bu.borrowerRepo.GetBorrowerByID(ctx, borrowerID)
+}
+
+func (bu *borrowerUsecase) ListBorrowers(ctx context.Context, limit, offset uint) ([]domain.Borrower, error) {
+	return bu.borrowerRepo.ListBorrowers(ctx, limit, offset)
+}
+
+func (bu *borrowerUsecase) UpdateBorrower(ctx context.Context, borrowerID uint, update *domain.BorrowerUpdate) (*domain.Borrower, error) {
+	for field, value := range map[string]*string{"name": update.Name, "email": update.Email, "phone": update.Phone} {
+		if value == nil {
+			continue
+		}
+		*value = strings.TrimSpace(*value)
+		if *value == "" {
+			return nil, fmt.Errorf("%w: %s must not be empty", domain.ErrInvalidBorrower, field)
+		}
+	}
+	if update.Email != nil {
+		if err := validateEmail(*update.Email); err != nil {
+			return nil, err
+		}
+	}
+	return bu.borrowerRepo.UpdateBorrower(ctx, borrowerID, update)
+}
+
+func (bu *borrowerUsecase) DeleteBorrower(ctx context.Context, borrowerID uint) error {
+	return bu.borrowerRepo.DeleteBorrower(ctx, borrowerID)
+}
+
+func (bu *borrowerUsecase) GetBorrowerLoans(ctx context.Context, borrowerID uint) ([]domain.Loan, error) {
+	if _, err := bu.borrowerRepo.GetBorrowerByID(ctx, borrowerID); err != nil {
+		return nil, err
+	}
+	return bu.loanRepo.GetLoansByBorrowerID(ctx, borrowerID)
+}
+
+func validateEmail(email string) error {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return fmt.Errorf("%w: invalid email %q", domain.ErrInvalidBorrower, email)
+	}
+	return nil
+}
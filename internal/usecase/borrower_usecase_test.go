@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mocking the BorrowerRepository
+type MockBorrowerRepository struct {
+	mock.Mock
+}
+
+func (m *MockBorrowerRepository) CreateBorrower(ctx context.Context, borrower *domain.Borrower) (*domain.Borrower, error) {
+	args := m.Called(ctx, borrower)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Borrower), args.Error(1)
+}
+
+func (m *MockBorrowerRepository) GetBorrowerByID(ctx context.Context, borrowerID uint) (*domain.Borrower, error) {
+	args := m.Called(ctx, borrowerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Borrower), args.Error(1)
+}
+
+func (m *MockBorrowerRepository) ListBorrowers(ctx context.Context, limit uint, offset uint) ([]domain.Borrower, error) {
+	args := m.Called(ctx, limit, offset)
+	return args.Get(0).([]domain.Borrower), args.Error(1)
+}
+
+func (m *MockBorrowerRepository) UpdateBorrower(ctx context.Context, borrowerID uint, update *domain.BorrowerUpdate) (*domain.Borrower, error) {
+	args := m.Called(ctx, borrowerID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Borrower), args.Error(1)
+}
+
+func (m *MockBorrowerRepository) DeleteBorrower(ctx context.Context, borrowerID uint) error {
+	args := m.Called(ctx, borrowerID)
+	return args.Error(0)
+}
+
+func TestCreateBorrowerRequiresAllFields(t *testing.T) {
+	mockRepo := new(MockBorrowerRepository)
+	borrowerUsecase := NewBorrowerUsecase(mockRepo, new(MockLoanRepository))
+
+	_, err := borrowerUsecase.CreateBorrower(context.Background(), "Jane Doe", "", "0812345678")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidBorrower)
+	mockRepo.AssertNotCalled(t, "CreateBorrower")
+}
+
+func TestCreateBorrowerPropagatesEmailConflict(t *testing.T) {
+	mockRepo := new(MockBorrowerRepository)
+	borrowerUsecase := NewBorrowerUsecase(mockRepo, new(MockLoanRepository))
+	ctx := context.Background()
+
+	mockRepo.On("CreateBorrower", ctx, &domain.Borrower{Name: "Jane Doe", Email: "jane@example.com", Phone: "0812345678"}).
+		Return(nil, domain.ErrBorrowerEmailTaken)
+
+	_, err := borrowerUsecase.CreateBorrower(ctx, " Jane Doe ", "jane@example.com", "0812345678")
+
+	assert.ErrorIs(t, err, domain.ErrBorrowerEmailTaken)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateBorrowerRejectsInvalidEmail(t *testing.T) {
+	mockRepo := new(MockBorrowerRepository)
+	borrowerUsecase := NewBorrowerUsecase(mockRepo, new(MockLoanRepository))
+	email := "not-an-email"
+
+	_, err := borrowerUsecase.UpdateBorrower(context.Background(), 1, &domain.BorrowerUpdate{Email: &email})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidBorrower)
+	mockRepo.AssertNotCalled(t, "UpdateBorrower")
+}
+
+func TestGetBorrowerLoansUnknownBorrower(t *testing.T) {
+	mockRepo := new(MockBorrowerRepository)
+	mockLoanRepo := new(MockLoanRepository)
+	borrowerUsecase := NewBorrowerUsecase(mockRepo, mockLoanRepo)
+	ctx := context.Background()
+
+	mockRepo.On("GetBorrowerByID", ctx, uint(7)).Return(nil, domain.ErrBorrowerNotFound)
+
+	_, err := borrowerUsecase.GetBorrowerLoans(ctx, 7)
+
+	assert.ErrorIs(t, err, domain.ErrBorrowerNotFound)
+	mockLoanRepo.AssertNotCalled(t, "GetLoansByBorrowerID")
+}
+
+func TestGetBorrowerLoans(t *testing.T) {
+	mockRepo := new(MockBorrowerRepository)
+	mockLoanRepo := new(MockLoanRepository)
+	borrowerUsecase := NewBorrowerUsecase(mockRepo, mockLoanRepo)
+	ctx := context.Background()
+	loans := []domain.Loan{{ID: 3}, {ID: 4}}
+
+	mockRepo.On("GetBorrowerByID", ctx, uint(2)).Return(&domain.Borrower{ID: 2}, nil)
+	mockLoanRepo.On("GetLoansByBorrowerID", ctx, uint(2)).Return(loans, nil)
+
+	result, err := borrowerUsecase.GetBorrowerLoans(ctx, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, loans, result)
+	mockRepo.AssertExpectations(t)
+	mockLoanRepo.AssertExpectations(t)
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import "time"
+
+// adjustDueDate moves a due date off the weekends and holidays of the calendar, it is kept without a calendar.
+func (lu *loanUsecase) adjustDueDate(dueDate time.Time) time.Time {
+	if lu.calendar == nil {
+		return dueDate
+	}
+	return lu.calendar.Adjust(dueDate, lu.convention)
+}
+
+// arrearsCutoff is the time before which unpaid installments are in arrears on asOf. Installments stored before
+// the calendar or a holiday was added may still fall on closed days, so their dates are adjusted here as well.
+func (lu *loanUsecase) arrearsCutoff(asOf time.Time) time.Time {
+	if lu.calendar == nil {
+		return asOf
+	}
+	return lu.calendar.ArrearsCutoff(asOf, lu.convention)
+}
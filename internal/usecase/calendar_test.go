@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// testCalendar is closed on weekends and on Friday 2024-05-31.
+var testCalendar = domain.NewBusinessCalendar([]domain.Holiday{
+	{Region: "ID", Date: time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC), Name: "Month End Holiday"},
+})
+
+func TestCreateLoanMovesDueDatesToBusinessDays(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	productRepo := new(MockLoanProductRepository)
+	// a Friday, so weekly installments fall due on Fridays
+	start := time.Date(2024, time.May, 24, 9, 0, 0, 0, time.UTC)
+	loanUsecase := NewLoanUsecase(mockRepo, productRepo,
+		WithClock(clock.NewFake(start)),
+		WithBusinessCalendar(testCalendar, domain.ConventionFollowing),
+	)
+	ctx := context.Background()
+
+	var schedules []domain.BillingSchedule
+	productRepo.On("GetLoanProductByID", ctx, uint(3)).Return(testProduct(), nil)
+	mockRepo.On("CreateLoan", ctx, uint(2), mock.AnythingOfType("*domain.Loan"), mock.Anything).
+		Run(func(args mock.Arguments) { schedules = args.Get(3).([]domain.BillingSchedule) }).
+		Return(uint(42), nil)
+
+	_, err := loanUsecase.CreateLoan(ctx, LoanApplication{
+		BorrowerID:   2,
+		ProductID:    3,
+		Amount:       domain.NewMoneyFromUnits(1000000),
+		TenorPeriods: 10,
+	})
+
+	assert.NoError(t, err)
+	// the holiday moves to the following Monday, the other weeks stay on Fridays
+	assert.Equal(t, "2024-06-03", schedules[0].DueDate.Time.Format(time.DateOnly))
+	assert.Equal(t, "2024-06-07", schedules[1].DueDate.Time.Format(time.DateOnly))
+}
+
+func TestIsDelinquentSkipsDaysTheBorrowerCouldNotPay(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	// Sunday, the last business day was Thursday 2024-05-30
+	now := time.Date(2024, time.June, 2, 9, 0, 0, 0, time.UTC)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository),
+		WithClock(clock.NewFake(now)),
+		WithBusinessCalendar(testCalendar, domain.ConventionFollowing),
+	)
+	ctx := context.Background()
+	loanID := uint(1)
+
+	mockRepo.On("GetLoanByID", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusActive}, nil)
+	// installments due on the holiday or the weekend are not in arrears before Monday
+	cutoff := time.Date(2024, time.May, 31, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("IsDelinquent", ctx, loanID, cutoff).Return(&domain.CheckDelinquentAmount{LoanID: loanID, TotalWeek: 1}, nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
+
+	check, err := loanUsecase.IsDelinquent(ctx, loanID)
+
+	assert.NoError(t, err)
+	assert.False(t, check.IsDelinquent)
+	mockRepo.AssertExpectations(t)
+}
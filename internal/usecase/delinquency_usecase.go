@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"time"
+)
+
+type DelinquencyUsecase interface {
+	// Run recalculates the delinquent weeks and late fees of every active or delinquent loan and moves loans
+	// in and out of the delinquent status accordingly.
+	Run(ctx context.Context) (*domain.DelinquencyRun, error)
+	// LastRun returns the latest run, which may still be in progress, or nil before the first run.
+	LastRun() *domain.DelinquencyRun
+}
+
+type delinquencyUsecase struct {
+	*loanJob[domain.DelinquencyRun, *domain.DelinquencyRun]
+	loans LoanUsecase
+}
+
+// NewDelinquencyUsecase lists the loans to refresh from lr and refreshes each of them through lu.
+func NewDelinquencyUsecase(lr domain.LoanRepository, lu LoanUsecase, clk clock.Clock, batchSize int) DelinquencyUsecase {
+	du := &delinquencyUsecase{loans: lu}
+	du.loanJob = newLoanJob[domain.DelinquencyRun]("delinquency job", lr, clk, batchSize, du.refresh)
+	return du
+}
+
+func (du *delinquencyUsecase) refresh(ctx context.Context, loanID uint, asOf time.Time) (func(run *domain.DelinquencyRun), error) {
+	updated, changed, err := du.loans.RefreshDelinquency(ctx, loanID, asOf)
+	return func(run *domain.DelinquencyRun) {
+		if updated {
+			run.LoansUpdated++
+		}
+		if changed {
+			run.StatusChanges++
+		}
+	}, err
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDelinquencyRunRefreshesLoansInBatches(t *testing.T) {
+	now := time.Date(2024, 6, 3, 1, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(now)
+	mockRepo := new(MockLoanRepository)
+	delinquencyUsecase := NewDelinquencyUsecase(mockRepo, NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clk)), clk, 2)
+	ctx := context.Background()
+	statuses := []domain.LoanStatus{domain.LoanStatusActive, domain.LoanStatusDelinquent}
+
+	mockRepo.On("ListLoanIDsByStatus", ctx, statuses, uint(0), 2).Return([]uint{1, 2}, nil)
+	mockRepo.On("ListLoanIDsByStatus", ctx, statuses, uint(2), 2).Return([]uint{3}, nil)
+
+	// loan 1 falls two weeks behind, loan 2 caught up, loan 3 is one week behind
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(&domain.Loan{ID: 1, Status: domain.LoanStatusActive, DelinquentWeeks: 1}, nil)
+	mockRepo.On("IsDelinquent", ctx, uint(1), now).Return(&domain.CheckDelinquentAmount{LoanID: 1, TotalWeek: 2}, nil)
+	mockRepo.On("UpdateDelinquentWeeks", ctx, uint(1), 2).Return(nil)
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(2)).Return(&domain.Loan{ID: 2, Status: domain.LoanStatusDelinquent, DelinquentWeeks: 3}, nil)
+	mockRepo.On("IsDelinquent", ctx, uint(2), now).Return(&domain.CheckDelinquentAmount{LoanID: 2}, nil)
+	mockRepo.On("UpdateDelinquentWeeks", ctx, uint(2), 0).Return(nil)
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(3)).Return(&domain.Loan{ID: 3, Status: domain.LoanStatusActive, DelinquentWeeks: 1}, nil)
+	mockRepo.On("IsDelinquent", ctx, uint(3), now).Return(&domain.CheckDelinquentAmount{LoanID: 3, TotalWeek: 1}, nil)
+	mockRepo.On("GetLateFees", ctx, mock.Anything).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+
+	run, err := delinquencyUsecase.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.JobSucceeded, run.Status)
+	assert.Equal(t, 3, run.LoansChecked)
+	assert.Equal(t, 2, run.LoansUpdated)
+	assert.Equal(t, 2, run.StatusChanges)
+	assert.Equal(t, run, delinquencyUsecase.LastRun())
+
+	var events []domain.LoanEvent
+	for _, call := range mockRepo.Calls {
+		if call.Method == "TransitionLoanStatus" {
+			events = append(events, call.Arguments.Get(1).(*domain.LoanStatusTransition).Event)
+		}
+	}
+	assert.Equal(t, []domain.LoanEvent{domain.LoanEventMarkDelinquent, domain.LoanEventCure}, events)
+	mockRepo.AssertNotCalled(t, "UpdateDelinquentWeeks", ctx, uint(3), mock.Anything)
+}
+
+func TestDelinquencyRunRecordsFailures(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	delinquencyUsecase := NewDelinquencyUsecase(mockRepo, NewLoanUsecase(mockRepo, new(MockLoanProductRepository)), clock.New(), 10)
+	ctx := context.Background()
+
+	assert.Nil(t, delinquencyUsecase.LastRun())
+
+	mockRepo.On("ListLoanIDsByStatus", ctx, mock.Anything, uint(0), 10).Return([]uint{1}, nil)
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return((*domain.Loan)(nil), errors.New("connection reset"))
+
+	run, err := delinquencyUsecase.Run(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.JobFailed, run.Status)
+	assert.Equal(t, 1, run.Failures)
+	assert.Contains(t, run.Error, "connection reset")
+	assert.NotNil(t, run.FinishedAt)
+}
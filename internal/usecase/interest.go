@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// RemainderPolicy decides which installment absorbs the cents left over when a total does not split evenly.
+type RemainderPolicy int
+
+const (
+	// RemainderFinal adds the remainder to the last installment.
+	RemainderFinal RemainderPolicy = iota
+	// RemainderFirst adds the remainder to the first installment.
+	RemainderFirst
+)
+
+func ParseRemainderPolicy(s string) (RemainderPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "final":
+		return RemainderFinal, nil
+	case "first":
+		return RemainderFirst, nil
+	}
+	return RemainderFinal, fmt.Errorf("unknown rounding remainder policy %q", s)
+}
+
+func (p RemainderPolicy) String() string {
+	if p == RemainderFirst {
+		return "first"
+	}
+	return "final"
+}
+
+// ScheduleTerms is what an InterestCalculator needs to build a schedule of Periods installments due every Frequency.
+// Rate is a fraction: for the whole term with flat interest, per year with the declining balance methods.
+type ScheduleTerms struct {
+	Principal domain.Money
+	Rate      *big.Rat
+	Periods   int
+	Frequency domain.RepaymentFrequency
+	Rounding  domain.RoundingMode
+	Remainder RemainderPolicy
+}
+
+// remainderPeriod is the period that absorbs the rounding remainder.
+func (t ScheduleTerms) remainderPeriod() int {
+	if t.Remainder == RemainderFirst {
+		return 1
+	}
+	return t.Periods
+}
+
+// periodicRate is the yearly rate of the declining balance methods charged on every installment.
+func (t ScheduleTerms) periodicRate() *big.Rat {
+	return new(big.Rat).Quo(t.Rate, big.NewRat(t.Frequency.PeriodsPerYear(), 1))
+}
+
+// InterestCalculator works out the installments of a loan and the interest it charges.
+// The installments always add up to the principal plus the interest, the rounding remainder going to the
+// period chosen by the terms' RemainderPolicy.
+type InterestCalculator interface {
+	Schedule(terms ScheduleTerms) InterestSchedule
+}
+
+// InterestSchedule is the outcome of an InterestCalculator, Installments only have their period and amounts set.
+type InterestSchedule struct {
+	Interest     domain.Money
+	Installments []domain.BillingSchedule
+}
+
+func defaultInterestCalculators() map[domain.InterestMethod]InterestCalculator {
+	return map[domain.InterestMethod]InterestCalculator{
+		domain.InterestFlat:           FlatInterest{},
+		domain.InterestAnnuity:        AnnuityInterest{},
+		domain.InterestEqualPrincipal: EqualPrincipalInterest{},
+	}
+}
+
+// FlatInterest charges rate once on the principal and splits the principal and the interest into equal installments.
+type FlatInterest struct{}
+
+func (FlatInterest) Schedule(terms ScheduleTerms) InterestSchedule {
+	interest := terms.Principal.Mul(terms.Rate, terms.Rounding)
+	principalParts := splitEvenly(terms.Principal, terms.Periods, terms.remainderPeriod())
+	interestParts := splitEvenly(interest, terms.Periods, terms.remainderPeriod())
+
+	installments := make([]domain.BillingSchedule, 0, terms.Periods)
+	for period := 1; period <= terms.Periods; period++ {
+		installments = append(installments, installmentOf(period, principalParts[period-1], interestParts[period-1]))
+	}
+	return InterestSchedule{Interest: interest, Installments: installments}
+}
+
+// AnnuityInterest charges the periodic rate on the remaining principal with equal installments,
+// so the interest part shrinks and the principal part grows over the term.
+type AnnuityInterest struct{}
+
+func (AnnuityInterest) Schedule(terms ScheduleTerms) InterestSchedule {
+	periodic := terms.periodicRate()
+	if periodic.Sign() == 0 {
+		return EqualPrincipalInterest{}.Schedule(terms)
+	}
+
+	// installment = principal * r / (1 - (1 + r)^-n)
+	growth := new(big.Rat).SetInt64(1)
+	onePlusRate := new(big.Rat).Add(big.NewRat(1, 1), periodic)
+	for i := 0; i < terms.Periods; i++ {
+		growth.Mul(growth, onePlusRate)
+	}
+	factor := new(big.Rat).Mul(periodic, growth)
+	factor.Quo(factor, new(big.Rat).Sub(growth, big.NewRat(1, 1)))
+	installment := terms.Principal.Mul(factor, terms.Rounding)
+
+	level := func(period int, balance, interest domain.Money) domain.Money {
+		return installment.Sub(interest)
+	}
+	schedule := amortize(terms, periodic, level)
+	if terms.Remainder != RemainderFirst || terms.Periods == 1 {
+		return schedule
+	}
+
+	// pay the present value of what the level installments left over in the first period, so the final period
+	// only differs from the others by the cents rounded away along the way
+	remainder := schedule.Installments[terms.Periods-1].Amount.Sub(installment)
+	discount := new(big.Rat).Quo(growth, onePlusRate)
+	extra := remainder.Mul(discount.Inv(discount), terms.Rounding)
+	return amortize(terms, periodic, func(period int, balance, interest domain.Money) domain.Money {
+		if period == 1 {
+			return installment.Add(extra).Sub(interest)
+		}
+		return installment.Sub(interest)
+	})
+}
+
+// EqualPrincipalInterest repays the same principal every period plus the periodic rate on the remaining principal,
+// so installments decrease over the term.
+type EqualPrincipalInterest struct{}
+
+func (EqualPrincipalInterest) Schedule(terms ScheduleTerms) InterestSchedule {
+	parts := splitEvenly(terms.Principal, terms.Periods, terms.remainderPeriod())
+	return amortize(terms, terms.periodicRate(), func(period int, balance, interest domain.Money) domain.Money {
+		return parts[period-1]
+	})
+}
+
+// prorateFirstPeriod scales the interest of the first installment by the length of a broken first period,
+// a factor of 3/2 charges a period and a half of interest. The principal parts are unchanged.
+func prorateFirstPeriod(schedule *InterestSchedule, factor *big.Rat, mode domain.RoundingMode) {
+	first := &schedule.Installments[0]
+	interest := first.Interest.Mul(factor, mode)
+	delta := interest.Sub(first.Interest)
+	first.Interest = interest
+	first.Amount = first.Amount.Add(delta)
+	schedule.Interest = schedule.Interest.Add(delta)
+}
+
+// amortize builds a declining balance schedule where principalPart decides how much of the balance each period repays,
+// the last period repays whatever is left so the principal parts always add up to the principal.
+func amortize(terms ScheduleTerms, rate *big.Rat, principalPart func(period int, balance, interest domain.Money) domain.Money) InterestSchedule {
+	installments := make([]domain.BillingSchedule, 0, terms.Periods)
+	total := domain.Money{}
+	balance := terms.Principal
+	for period := 1; period <= terms.Periods; period++ {
+		interest := balance.Mul(rate, terms.Rounding)
+		part := principalPart(period, balance, interest)
+		if part.Sign() < 0 {
+			part = domain.Money{}
+		}
+		if period == terms.Periods || part.Cmp(balance) > 0 {
+			part = balance
+		}
+		balance = balance.Sub(part)
+		total = total.Add(interest)
+		installments = append(installments, installmentOf(period, part, interest))
+	}
+	return InterestSchedule{Interest: total, Installments: installments}
+}
+
+// splitEvenly divides amount into periods parts rounded down to the cent,
+// the cents rounded away are added to the part of remainderPeriod.
+func splitEvenly(amount domain.Money, periods, remainderPeriod int) []domain.Money {
+	part := amount.Div(int64(periods), domain.RoundFloor)
+	parts := make([]domain.Money, periods)
+	for i := range parts {
+		parts[i] = part
+	}
+	parts[remainderPeriod-1] = amount.Sub(part.Mul(big.NewRat(int64(periods-1), 1), domain.RoundFloor))
+	return parts
+}
+
+func installmentOf(period int, principal, interest domain.Money) domain.BillingSchedule {
+	return domain.BillingSchedule{
+		Period:    uint(period),
+		Amount:    principal.Add(interest),
+		Principal: principal,
+		Interest:  interest,
+	}
+}
+
+func (lu *loanUsecase) interestCalculator(method domain.InterestMethod) (InterestCalculator, error) {
+	calculator, ok := lu.calculators[method]
+	if !ok {
+		return nil, fmt.Errorf("%w: no interest calculator for %q", domain.ErrInvalidLoan, method)
+	}
+	return calculator, nil
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"math/big"
+	"testing"
+	"testing/quick"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func sumInstallments(installments []domain.BillingSchedule) (amount, principal, interest domain.Money) {
+	for _, installment := range installments {
+		amount = amount.Add(installment.Amount)
+		principal = principal.Add(installment.Principal)
+		interest = interest.Add(installment.Interest)
+	}
+	return amount, principal, interest
+}
+
+func TestParseRemainderPolicy(t *testing.T) {
+	policy, err := ParseRemainderPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, RemainderFinal, policy)
+
+	policy, err = ParseRemainderPolicy("First")
+	assert.NoError(t, err)
+	assert.Equal(t, RemainderFirst, policy)
+
+	_, err = ParseRemainderPolicy("middle")
+	assert.Error(t, err)
+}
+
+func TestFlatInterestRemainder(t *testing.T) {
+	terms := ScheduleTerms{
+		Principal: domain.NewMoneyFromUnits(5000000),
+		Rate:      big.NewRat(1, 10),
+		Periods:   7,
+		Rounding:  domain.RoundHalfUp,
+	}
+
+	schedule := FlatInterest{}.Schedule(terms)
+	amount, _, _ := sumInstallments(schedule.Installments)
+	assert.Equal(t, "5500000.00", amount.String())
+	assert.Equal(t, "785714.28", schedule.Installments[0].Amount.String())
+	assert.Equal(t, "785714.32", schedule.Installments[6].Amount.String())
+
+	terms.Remainder = RemainderFirst
+	schedule = FlatInterest{}.Schedule(terms)
+	assert.Equal(t, "785714.32", schedule.Installments[0].Amount.String())
+	assert.Equal(t, "785714.28", schedule.Installments[6].Amount.String())
+}
+
+func TestAnnuityInterest(t *testing.T) {
+	principal := domain.NewMoneyFromUnits(5000000)
+	// 26% a year is 0.5% a week
+	terms := ScheduleTerms{Principal: principal, Rate: big.NewRat(26, 100), Periods: 10, Rounding: domain.RoundHalfUp}
+
+	schedule := AnnuityInterest{}.Schedule(terms)
+	assert.Len(t, schedule.Installments, 10)
+	_, repaid, interest := sumInstallments(schedule.Installments)
+	assert.Equal(t, principal.String(), repaid.String())
+	assert.Equal(t, schedule.Interest.String(), interest.String())
+	assert.Equal(t, "25000.00", schedule.Installments[0].Interest.String())
+	for _, installment := range schedule.Installments[:9] {
+		assert.Equal(t, "513852.86", installment.Amount.String())
+	}
+	// the last installment absorbs the cents rounded away from the others
+	assert.Equal(t, "513852.90", schedule.Installments[9].Amount.String())
+
+	terms.Remainder = RemainderFirst
+	schedule = AnnuityInterest{}.Schedule(terms)
+	_, repaid, _ = sumInstallments(schedule.Installments)
+	assert.Equal(t, principal.String(), repaid.String())
+	assert.Equal(t, "513852.90", schedule.Installments[0].Amount.String())
+	for _, installment := range schedule.Installments[1:9] {
+		assert.Equal(t, "513852.86", installment.Amount.String())
+	}
+	// the extra cents paid in the first week accrue a little less interest
+	assert.Equal(t, "513852.85", schedule.Installments[9].Amount.String())
+}
+
+func TestEqualPrincipalInterest(t *testing.T) {
+	principal := domain.NewMoneyFromUnits(1000000)
+	terms := ScheduleTerms{Principal: principal, Rate: big.NewRat(52, 100), Periods: 3, Rounding: domain.RoundHalfUp}
+
+	schedule := EqualPrincipalInterest{}.Schedule(terms)
+	_, repaid, interest := sumInstallments(schedule.Installments)
+	assert.Equal(t, principal.String(), repaid.String())
+	// 1% a week on 1,000,000, 666,666.67 and 333,333.34
+	assert.Equal(t, "20000.00", interest.String())
+	assert.Equal(t, "333333.33", schedule.Installments[0].Principal.String())
+	assert.Equal(t, "333333.34", schedule.Installments[2].Principal.String())
+	for i := 1; i < len(schedule.Installments); i++ {
+		assert.Equal(t, -1, schedule.Installments[i].Interest.Cmp(schedule.Installments[i-1].Interest))
+	}
+}
+
+// TestSchedulesSumToLoanTotal checks on random loans that every calculator, whatever the rounding and remainder
+// policies, repays exactly the principal plus the interest it reports, and that only the remainder week differs
+// from the regular installment.
+func TestSchedulesSumToLoanTotal(t *testing.T) {
+	modes := []domain.RoundingMode{domain.RoundHalfUp, domain.RoundHalfEven}
+	policies := []RemainderPolicy{RemainderFinal, RemainderFirst}
+
+	for method, calculator := range defaultInterestCalculators() {
+		calculator := calculator
+		t.Run(string(method), func(t *testing.T) {
+			property := func(cents uint32, basisPoints uint16, weeks uint8, mode, policy uint8) bool {
+				terms := ScheduleTerms{
+					Principal: domain.NewMoneyFromCents(int64(cents) + 1),
+					Rate:      big.NewRat(int64(basisPoints%10000), 10000),
+					Periods:   int(weeks)%104 + 1,
+					Rounding:  modes[int(mode)%len(modes)],
+					Remainder: policies[int(policy)%len(policies)],
+				}
+				schedule := calculator.Schedule(terms)
+				if len(schedule.Installments) != terms.Periods {
+					return false
+				}
+
+				amount, principal, interest := sumInstallments(schedule.Installments)
+				if principal.Cmp(terms.Principal) != 0 || interest.Cmp(schedule.Interest) != 0 ||
+					amount.Cmp(terms.Principal.Add(schedule.Interest)) != 0 {
+					return false
+				}
+				for _, installment := range schedule.Installments {
+					if installment.Principal.Sign() < 0 || installment.Interest.Sign() < 0 ||
+						installment.Amount.Cmp(installment.Principal.Add(installment.Interest)) != 0 {
+						return false
+					}
+				}
+				if method != domain.InterestFlat {
+					return true
+				}
+
+				// flat installments are all the same but for the remainder week, which is never smaller
+				remainder := schedule.Installments[terms.remainderPeriod()-1]
+				for i, installment := range schedule.Installments {
+					if i == terms.remainderPeriod()-1 {
+						continue
+					}
+					if installment.Amount.Cmp(schedule.Installments[terms.Periods-terms.remainderPeriod()].Amount) != 0 ||
+						remainder.Amount.Cmp(installment.Amount) < 0 {
+						return false
+					}
+				}
+				return true
+			}
+			assert.NoError(t, quick.Check(property, &quick.Config{MaxCount: 500}))
+		})
+	}
+}
+
+func TestCreateLoanOutstandingMatchesSchedule(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	productRepo := new(MockLoanProductRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, productRepo, WithRemainderPolicy(RemainderFirst))
+	ctx := context.Background()
+	product := testProduct()
+	product.TenorPeriods = []int{7}
+	productRepo.On("GetLoanProductByID", ctx, uint(3)).Return(product, nil)
+
+	var created *domain.Loan
+	var schedules []domain.BillingSchedule
+	mockRepo.On("CreateLoan", ctx, uint(2), mock.AnythingOfType("*domain.Loan"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			created = args.Get(2).(*domain.Loan)
+			schedules = args.Get(3).([]domain.BillingSchedule)
+		}).
+		Return(uint(42), nil)
+
+	_, err := loanUsecase.CreateLoan(ctx, LoanApplication{
+		BorrowerID:   2,
+		ProductID:    3,
+		Amount:       domain.NewMoneyFromUnits(5000000),
+		TenorPeriods: 7,
+	})
+
+	assert.NoError(t, err)
+	amount, _, _ := sumInstallments(schedules)
+	assert.Equal(t, "5500000.00", created.Outstanding.String())
+	assert.Equal(t, created.Outstanding.String(), amount.String())
+	assert.Equal(t, "785714.32", schedules[0].Amount.String())
+	assert.Equal(t, "785714.28", created.InstallmentAmount.String())
+}
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// LateFeePolicy decides the fee charged on every installment that is not paid in time, the zero policy charges nothing.
+type LateFeePolicy struct {
+	// FlatPerInstallment is charged for every late installment.
+	FlatPerInstallment domain.Money
+	// PercentOfArrears is charged on what was still owed on the late installment, as a fraction: 1/20 is 5%.
+	PercentOfArrears *big.Rat
+	// GraceDays is how many days after its due date an installment can still be paid without a fee.
+	GraceDays int
+	// CapPerLoan limits the fees charged over the life of a loan, zero means no cap.
+	CapPerLoan domain.Money
+}
+
+// ParseLateFeePolicy builds a policy from its configuration, amounts are decimal strings and percent is in percent.
+func ParseLateFeePolicy(flat, percent string, graceDays int, capPerLoan string) (LateFeePolicy, error) {
+	policy := LateFeePolicy{GraceDays: graceDays}
+	if graceDays < 0 {
+		return policy, fmt.Errorf("late fee grace days must not be negative")
+	}
+
+	var err error
+	if policy.FlatPerInstallment, err = parseFeeAmount(flat); err != nil {
+		return policy, fmt.Errorf("invalid late fee flat amount: %w", err)
+	}
+	if policy.CapPerLoan, err = parseFeeAmount(capPerLoan); err != nil {
+		return policy, fmt.Errorf("invalid late fee cap: %w", err)
+	}
+
+	if percent = strings.TrimSpace(percent); percent != "" {
+		rate, ok := new(big.Rat).SetString(percent)
+		if !ok || rate.Sign() < 0 {
+			return policy, fmt.Errorf("invalid late fee percent %q", percent)
+		}
+		policy.PercentOfArrears = rate.Quo(rate, big.NewRat(100, 1))
+	}
+	return policy, nil
+}
+
+func parseFeeAmount(s string) (domain.Money, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return domain.Money{}, nil
+	}
+	amount, err := domain.NewMoneyFromString(s)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	if amount.Sign() < 0 {
+		return domain.Money{}, fmt.Errorf("%s must not be negative", s)
+	}
+	return amount, nil
+}
+
+func (p LateFeePolicy) enabled() bool {
+	return p.FlatPerInstallment.Sign() > 0 || (p.PercentOfArrears != nil && p.PercentOfArrears.Sign() > 0)
+}
+
+// isLate reports whether the grace period following dueDate ended before the day of asOf.
+func (p LateFeePolicy) isLate(dueDate, asOf time.Time) bool {
+	asOfDate := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	return asOfDate.After(dueDate.AddDate(0, 0, p.GraceDays))
+}
+
+// fee returns the fee for an installment that is late with remaining still owed on it.
+func (p LateFeePolicy) fee(remaining domain.Money, mode domain.RoundingMode) domain.Money {
+	fee := p.FlatPerInstallment
+	if p.PercentOfArrears != nil {
+		fee = fee.Add(remaining.Mul(p.PercentOfArrears, mode))
+	}
+	return fee
+}
+
+// lateFees returns the loan's unpaid late fees as of asOf: the ones already charged, plus the ones the policy
+// charges for unpaid schedules that became late since. The new fees are only stored when charge is set, so
+// read paths show the same amounts a payment at asOf would be asked for.
+func (lu *loanUsecase) lateFees(ctx context.Context, repo domain.LoanRepository, loan *domain.Loan, schedules []domain.BillingSchedule, asOf time.Time, charge bool) ([]domain.LateFee, error) {
+	charged, err := repo.GetLateFees(ctx, loan.ID)
+	if err != nil {
+		return nil, fmt.Errorf("loan usecase: failed to get late fees: %w", err)
+	}
+
+	var unpaid []domain.LateFee
+	chargedSchedules := make(map[uint]bool, len(charged))
+	total := domain.Money{}
+	for _, fee := range charged {
+		chargedSchedules[fee.BillingScheduleID] = true
+		total = total.Add(fee.Amount)
+		if fee.Remaining().Sign() > 0 {
+			unpaid = append(unpaid, fee)
+		}
+	}
+
+	if !lu.lateFee.enabled() || !loan.Status.AcceptsPayments() {
+		return unpaid, nil
+	}
+
+	for _, schedule := range schedules {
+		if chargedSchedules[schedule.ID] || schedule.Remaining().Sign() <= 0 || !lu.lateFee.isLate(lu.adjustDueDate(schedule.DueDate.Time), asOf) {
+			continue
+		}
+
+		amount := lu.lateFee.fee(schedule.Remaining(), lu.rounding)
+		if lu.lateFee.CapPerLoan.Sign() > 0 {
+			if room := lu.lateFee.CapPerLoan.Sub(total); amount.Cmp(room) > 0 {
+				amount = room
+			}
+		}
+		if amount.Sign() <= 0 {
+			continue
+		}
+
+		fee := domain.LateFee{LoanID: loan.ID, BillingScheduleID: schedule.ID, Period: schedule.Period, Amount: amount}
+		if charge {
+			if err := repo.CreateLateFee(ctx, &fee); err != nil {
+				return nil, err
+			}
+			if err := postJournalEntries(ctx, repo, lateFeeEntry(&fee)); err != nil {
+				return nil, err
+			}
+		} else {
+			fee.ChargedAt = pgtype.Timestamp{Time: asOf, Valid: true}
+		}
+		total = total.Add(amount)
+		unpaid = append(unpaid, fee)
+	}
+
+	sort.SliceStable(unpaid, func(i, j int) bool { return unpaid[i].Period < unpaid[j].Period })
+	return unpaid, nil
+}
+
+// unpaidLateFees sums what is still owed on the given fees.
+func unpaidLateFees(fees []domain.LateFee) domain.Money {
+	total := domain.Money{}
+	for _, fee := range fees {
+		total = total.Add(fee.Remaining())
+	}
+	return total
+}
+
+// allocateLateFees applies amount to the unpaid fees, oldest period first. It returns the fees that received money,
+// their allocations and the part of amount left for the installments.
+func allocateLateFees(fees []domain.LateFee, amount domain.Money) ([]domain.LateFee, []domain.PaymentAllocation, domain.Money) {
+	var updated []domain.LateFee
+	var allocations []domain.PaymentAllocation
+
+	left := amount
+	for _, fee := range fees {
+		if left.Sign() <= 0 {
+			break
+		}
+		remaining := fee.Remaining()
+		if remaining.Sign() <= 0 {
+			continue
+		}
+
+		applied := remaining
+		if left.Cmp(remaining) < 0 {
+			applied = left
+		}
+		left = left.Sub(applied)
+
+		fee.PaidAmount = fee.PaidAmount.Add(applied)
+		updated = append(updated, fee)
+		allocations = append(allocations, domain.PaymentAllocation{
+			BillingScheduleID: fee.BillingScheduleID,
+			Period:            fee.Period,
+			LateFeeID:         fee.ID,
+			Amount:            applied,
+			Fee:               applied,
+			Remaining:         fee.Remaining(),
+		})
+	}
+	return updated, allocations, left
+}
+
+// payLateFees allocates amount to the fees and stores their paid amounts, it returns the allocations and what is left.
+func payLateFees(ctx context.Context, repo domain.LoanRepository, fees []domain.LateFee, amount domain.Money) ([]domain.PaymentAllocation, domain.Money, error) {
+	paid, allocations, left := allocateLateFees(fees, amount)
+	for i := range paid {
+		if err := repo.UpdateLateFee(ctx, &paid[i]); err != nil {
+			return nil, domain.Money{}, err
+		}
+	}
+	return allocations, left, nil
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// lateFeeSchedules is three weekly installments of 110,000 due one, two and three weeks after start.
+func lateFeeSchedules(loanID uint, start time.Time) []domain.BillingSchedule {
+	schedules := weeklySchedules(loanID, 3, domain.NewMoneyFromUnits(110000))
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	for i := range schedules {
+		schedules[i].DueDate = pgtype.Date{Time: day.AddDate(0, 0, 7*(i+1)), Valid: true}
+	}
+	return schedules
+}
+
+// 50 per week plus 1% of the installment, so 1,150 on a 110,000 installment
+var testLateFeePolicy = LateFeePolicy{
+	FlatPerInstallment: domain.NewMoneyFromUnits(50),
+	PercentOfArrears:   big.NewRat(1, 100),
+	GraceDays:          1,
+}
+
+func TestParseLateFeePolicy(t *testing.T) {
+	policy, err := ParseLateFeePolicy("50", "2.5", 3, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "50.00", policy.FlatPerInstallment.String())
+	assert.Equal(t, big.NewRat(1, 40), policy.PercentOfArrears)
+	assert.Equal(t, 3, policy.GraceDays)
+	assert.True(t, policy.CapPerLoan.IsZero())
+	assert.True(t, policy.enabled())
+
+	policy, err = ParseLateFeePolicy("0", "0", 0, "0")
+	assert.NoError(t, err)
+	assert.False(t, policy.enabled())
+
+	_, err = ParseLateFeePolicy("-5", "", 0, "")
+	assert.Error(t, err)
+	_, err = ParseLateFeePolicy("", "five", 0, "")
+	assert.Error(t, err)
+	_, err = ParseLateFeePolicy("", "", -1, "")
+	assert.Error(t, err)
+}
+
+func TestMakePaymentChargesAndPaysLateFeesFirst(t *testing.T) {
+	start := time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC)
+	// two days after week 2 was due, past its one day of grace
+	now := start.AddDate(0, 0, 16)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)), WithLateFeePolicy(testLateFeePolicy))
+	ctx := context.Background()
+	loanID := uint(1)
+	loan := &domain.Loan{ID: loanID, Status: domain.LoanStatusDelinquent, Outstanding: domain.NewMoneyFromUnits(330000)}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(loan, nil)
+	mockRepo.On("IsDelinquent", ctx, loanID, now).Return(&domain.CheckDelinquentAmount{LoanID: loanID, TotalWeek: 2, Amount: domain.NewMoneyFromUnits(220000), IsDelinquent: true}, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, loanID).Return(lateFeeSchedules(loanID, start), nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("CreateLateFee", ctx, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+	mockRepo.On("UpdateLateFee", ctx, mock.Anything).Return(nil)
+	mockRepo.On("UpdateLoan", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+
+	// the arrears plus both late fees
+	payment, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(222300))
+
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "CreateLateFee", 2)
+	mockRepo.AssertNumberOfCalls(t, "UpdateLateFee", 2)
+	assert.Len(t, payment.Allocations, 4)
+	assert.Equal(t, uint(101), payment.Allocations[0].LateFeeID)
+	assert.Equal(t, "1150.00", payment.Allocations[0].Amount.String())
+	assert.Equal(t, uint(102), payment.Allocations[1].LateFeeID)
+	assert.Equal(t, uint(0), payment.Allocations[2].LateFeeID)
+	assert.Equal(t, "110000.00", payment.Allocations[2].Amount.String())
+	assert.Equal(t, "110000.00", loan.Outstanding.String())
+	assert.Equal(t, domain.LoanStatusActive, loan.Status)
+}
+
+func TestMakePaymentStrictPolicyRequiresLateFees(t *testing.T) {
+	start := time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC)
+	now := start.AddDate(0, 0, 16)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository),
+		WithClock(clock.NewFake(now)),
+		WithLateFeePolicy(testLateFeePolicy),
+		WithPaymentAllocationPolicy(PaymentAllocationPolicy{}),
+	)
+	ctx := context.Background()
+	loanID := uint(1)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusDelinquent, Outstanding: domain.NewMoneyFromUnits(330000)}, nil)
+	mockRepo.On("IsDelinquent", ctx, loanID, now).Return(&domain.CheckDelinquentAmount{LoanID: loanID, TotalWeek: 2, Amount: domain.NewMoneyFromUnits(220000), IsDelinquent: true}, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, loanID).Return(lateFeeSchedules(loanID, start), nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("CreateLateFee", ctx, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	_, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(220000))
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPayment)
+	assert.Contains(t, err.Error(), "222300.00")
+	mockRepo.AssertNotCalled(t, "UpdateLoan")
+}
+
+func TestGetOutstandingIncludesLateFeesUpToTheCap(t *testing.T) {
+	start := time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC)
+	policy := testLateFeePolicy
+	policy.CapPerLoan = domain.NewMoneyFromUnits(2000)
+	clk := clock.NewFake(start.AddDate(0, 0, 15))
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clk), WithLateFeePolicy(policy))
+	ctx := context.Background()
+	loanID := uint(1)
+	// week 1 was charged and its fee paid, but nothing of the installment
+	charged := []domain.LateFee{{
+		ID: 7, LoanID: loanID, BillingScheduleID: 101, Period: 1,
+		Amount: domain.NewMoneyFromUnits(1150), PaidAmount: domain.NewMoneyFromUnits(1150),
+	}}
+
+	mockRepo.On("GetLoanByID", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusActive, Outstanding: domain.NewMoneyFromUnits(330000)}, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, loanID).Return(lateFeeSchedules(loanID, start), nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return(charged, nil)
+
+	// week 2 is still within its grace day
+	outstanding, err := loanUsecase.GetOutstanding(ctx, loanID)
+	assert.NoError(t, err)
+	assert.True(t, outstanding.LateFees.IsZero())
+	assert.Equal(t, "330000.00", outstanding.Total.String())
+
+	// the week 2 fee is cut to what is left under the cap, and is not stored by a read
+	clk.AdvanceDays(1)
+	outstanding, err = loanUsecase.GetOutstanding(ctx, loanID)
+	assert.NoError(t, err)
+	assert.Equal(t, "850.00", outstanding.LateFees.String())
+	assert.Equal(t, "330000.00", outstanding.Installments.String())
+	assert.Equal(t, "330850.00", outstanding.Total.String())
+	mockRepo.AssertNotCalled(t, "CreateLateFee", mock.Anything, mock.Anything)
+}
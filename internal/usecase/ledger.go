@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+func (lu *loanUsecase) GetJournal(ctx context.Context, loanID uint) ([]domain.JournalEntry, error) {
+	if _, err := lu.loanRepo.GetLoanByID(ctx, loanID); err != nil {
+		return nil, err
+	}
+	return lu.loanRepo.GetJournalEntries(ctx, loanID)
+}
+
+// postJournalEntries records the entries in the unit of work of repo, entries without postings are skipped.
+func postJournalEntries(ctx context.Context, repo domain.LoanRepository, entries ...*domain.JournalEntry) error {
+	for _, entry := range entries {
+		if len(entry.Postings) == 0 {
+			continue
+		}
+		if err := repo.PostJournalEntry(ctx, entry); err != nil {
+			return fmt.Errorf("loan usecase: failed to post %s entry: %w", entry.Kind, err)
+		}
+	}
+	return nil
+}
+
+// disbursementEntry books the principal paid out of cash and the interest and fees the schedule charges on top.
+// Interest stays unearned until it accrues and installment fees until they are collected, what is waived or
+// written off never becomes income.
+func disbursementEntry(loan *domain.Loan, schedules []domain.BillingSchedule) *domain.JournalEntry {
+	total := remainingComponents(schedules)
+	entry := &domain.JournalEntry{LoanID: loan.ID, Kind: domain.JournalDisbursement, Description: fmt.Sprintf("loan %d disbursed", loan.ID)}
+	entry.Debit(domain.AccountLoansReceivable, total.Principal).Credit(domain.AccountCash, total.Principal)
+	entry.Debit(domain.AccountInterestReceivable, total.Interest).Debit(domain.AccountFeesReceivable, total.Fee)
+	entry.Credit(domain.AccountUnearnedIncome, total.Interest.Add(total.Fee))
+	return entry
+}
+
+// paymentEntries books the cash received by payment against what its allocations settled, and recognises the
+// installment fees it collected as income. Interest is earned as it accrues, whether it was collected or not.
+func paymentEntries(payment *domain.Payment) []*domain.JournalEntry {
+	collected := domain.Components{}
+	lateFees := domain.Money{}
+	for _, allocation := range payment.Allocations {
+		if allocation.LateFeeID != 0 {
+			lateFees = lateFees.Add(allocation.Amount)
+			continue
+		}
+		collected = collected.Add(domain.Components{Principal: allocation.Principal, Interest: allocation.Interest, Fee: allocation.Fee})
+	}
+
+	collection := &domain.JournalEntry{LoanID: payment.LoanID, PaymentID: payment.ID, Kind: domain.JournalCollection, Description: fmt.Sprintf("payment %d", payment.ID)}
+	collection.Debit(domain.AccountCash, payment.Amount)
+	collection.Credit(domain.AccountLoansReceivable, collected.Principal)
+	collection.Credit(domain.AccountInterestReceivable, collected.Interest)
+	collection.Credit(domain.AccountFeesReceivable, collected.Fee.Add(lateFees))
+
+	accrual := &domain.JournalEntry{LoanID: payment.LoanID, PaymentID: payment.ID, Kind: domain.JournalAccrual, Description: fmt.Sprintf("earned by payment %d", payment.ID)}
+	accrual.Debit(domain.AccountUnearnedIncome, collected.Fee).Credit(domain.AccountFeeIncome, collected.Fee)
+	return []*domain.JournalEntry{collection, accrual}
+}
+
+// rebateEntry waives the interest a payoff rebated on its allocations, that interest was never earned.
+func rebateEntry(payment *domain.Payment) *domain.JournalEntry {
+	waived := domain.Money{}
+	for _, allocation := range payment.Allocations {
+		waived = waived.Add(allocation.InterestWaived)
+	}
+	entry := &domain.JournalEntry{LoanID: payment.LoanID, PaymentID: payment.ID, Kind: domain.JournalRebate, Description: fmt.Sprintf("rebate of payment %d", payment.ID)}
+	return entry.Debit(domain.AccountUnearnedIncome, waived).Credit(domain.AccountInterestReceivable, waived)
+}
+
+// accrualEntry earns the interest accrued on a loan by one accrual run.
+func accrualEntry(accrual *domain.InterestAccrual) *domain.JournalEntry {
+	entry := &domain.JournalEntry{LoanID: accrual.LoanID, Kind: domain.JournalAccrual, Description: fmt.Sprintf("interest accrued to %s", accrual.AccrualDate.Time.Format(time.DateOnly))}
+	return entry.Debit(domain.AccountUnearnedIncome, accrual.Amount).Credit(domain.AccountInterestIncome, accrual.Amount)
+}
+
+// settlementEntry brings the interest earned on schedules to what was collected on them once they are settled for
+// good: interest collected before it accrued is earned, interest accrued but never collected is taken back.
+// Schedules superseded by an earlier restructure were settled by it and are skipped.
+func settlementEntry(loan *domain.Loan, schedules []domain.BillingSchedule, paymentID uint) *domain.JournalEntry {
+	unaccrued := domain.Money{}
+	for _, schedule := range schedules {
+		if !schedule.SupersededAt.Valid {
+			unaccrued = unaccrued.Add(schedule.PaidComponents().Interest.Sub(schedule.AccruedInterest))
+		}
+	}
+	entry := &domain.JournalEntry{LoanID: loan.ID, PaymentID: paymentID, Kind: domain.JournalAccrual, Description: fmt.Sprintf("interest of loan %d settled", loan.ID)}
+	return entry.Debit(domain.AccountUnearnedIncome, unaccrued).Credit(domain.AccountInterestIncome, unaccrued)
+}
+
+// settleInterest posts the settlementEntry of every current schedule row of a loan that is paid off or written
+// off, repo must be the unit of work holding the loan row lock.
+func settleInterest(ctx context.Context, repo domain.LoanRepository, loan *domain.Loan, paymentID uint) error {
+	schedules, err := repo.GetBillingSchedules(ctx, loan.ID)
+	if err != nil {
+		return fmt.Errorf("loan usecase: failed to get billing schedules: %w", err)
+	}
+	return postJournalEntries(ctx, repo, settlementEntry(loan, schedules, paymentID))
+}
+
+// lateFeeEntry books a late fee as earned when it is charged.
+func lateFeeEntry(fee *domain.LateFee) *domain.JournalEntry {
+	entry := &domain.JournalEntry{LoanID: fee.LoanID, Kind: domain.JournalFeeCharge, Description: fmt.Sprintf("late fee of period %d", fee.Period)}
+	return entry.Debit(domain.AccountFeesReceivable, fee.Amount).Credit(domain.AccountFeeIncome, fee.Amount)
+}
+
+// writeOffEntry removes what is left of the loan from the receivables, the unearned interest and fees are
+// cancelled and the principal and unpaid late fees are a loss.
+func writeOffEntry(loan *domain.Loan, schedules []domain.BillingSchedule, lateFees domain.Money) *domain.JournalEntry {
+	remaining := remainingComponents(schedules)
+	entry := &domain.JournalEntry{LoanID: loan.ID, Kind: domain.JournalWriteOff, Description: fmt.Sprintf("loan %d written off", loan.ID)}
+	entry.Debit(domain.AccountUnearnedIncome, remaining.Interest.Add(remaining.Fee))
+	entry.Debit(domain.AccountWriteOffExpense, remaining.Principal.Add(lateFees))
+	entry.Credit(domain.AccountLoansReceivable, remaining.Principal)
+	entry.Credit(domain.AccountInterestReceivable, remaining.Interest)
+	entry.Credit(domain.AccountFeesReceivable, remaining.Fee.Add(lateFees))
+	return entry
+}
+
+// recoveryEntry books the cash collected on a written-off loan as income, its receivables are long gone.
+func recoveryEntry(recovery *domain.Recovery) *domain.JournalEntry {
+	entry := &domain.JournalEntry{LoanID: recovery.LoanID, Kind: domain.JournalRecovery, Description: fmt.Sprintf("recovery on written off loan %d", recovery.LoanID)}
+	return entry.Debit(domain.AccountCash, recovery.Amount).Credit(domain.AccountRecoveryIncome, recovery.Amount)
+}
+
+// restructureEntry replaces the receivables of the superseded schedules with the ones of the new schedule. The
+// interest and fees already due are capitalised into the new principal, which earns them, and the interest of the
+// superseded schedules is otherwise settled like in settlementEntry.
+func restructureEntry(loan *domain.Loan, superseded []domain.BillingSchedule, restructure *domain.LoanRestructure, asOf time.Time) *domain.JournalEntry {
+	old := remainingComponents(superseded)
+	capitalised := restructure.Principal.Sub(old.Principal)
+	dueInterest := domain.Money{}
+	asOfDate := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	for _, schedule := range superseded {
+		if !schedule.DueDate.Time.After(asOfDate) {
+			dueInterest = dueInterest.Add(schedule.RemainingComponents().Interest)
+		}
+	}
+	added := remainingComponents(restructure.Schedule)
+
+	entry := &domain.JournalEntry{LoanID: loan.ID, Kind: domain.JournalRestructure, Description: fmt.Sprintf("restructured to schedule version %d", restructure.ToVersion)}
+	entry.Debit(domain.AccountLoansReceivable, capitalised)
+	entry.Credit(domain.AccountInterestReceivable, old.Interest).Credit(domain.AccountFeesReceivable, old.Fee)
+	entry.Debit(domain.AccountUnearnedIncome, old.Interest.Add(old.Fee))
+	entry.Credit(domain.AccountInterestIncome, dueInterest).Credit(domain.AccountFeeIncome, capitalised.Sub(dueInterest))
+	entry.Debit(domain.AccountInterestReceivable, added.Interest).Debit(domain.AccountFeesReceivable, added.Fee)
+	entry.Credit(domain.AccountUnearnedIncome, added.Interest.Add(added.Fee))
+	settled := settlementEntry(loan, superseded, 0)
+	entry.Postings = append(entry.Postings, settled.Postings...)
+	return entry
+}
+
+// paymentHolidayEntry books the interest capitalised by a payment holiday, it is earned when collected.
+func paymentHolidayEntry(holiday *domain.PaymentHoliday) *domain.JournalEntry {
+	entry := &domain.JournalEntry{LoanID: holiday.LoanID, Kind: domain.JournalPaymentHoliday, Description: fmt.Sprintf("payment holiday of %d periods", holiday.Periods)}
+	return entry.Debit(domain.AccountInterestReceivable, holiday.CapitalisedInterest).Credit(domain.AccountUnearnedIncome, holiday.CapitalisedInterest)
+}
+
+// reversalEntries undoes every entry recorded by payment that was not reversed yet.
+func reversalEntries(entries []domain.JournalEntry, payment *domain.Payment) []*domain.JournalEntry {
+	reversed := make(map[uint]bool)
+	for _, entry := range entries {
+		if entry.ReversesEntryID != 0 {
+			reversed[entry.ReversesEntryID] = true
+		}
+	}
+	var reversals []*domain.JournalEntry
+	for i := range entries {
+		entry := &entries[i]
+		if entry.PaymentID != payment.ID || entry.Kind == domain.JournalReversal || reversed[entry.ID] {
+			continue
+		}
+		reversals = append(reversals, entry.Reversal(fmt.Sprintf("payment %d reversed: %s", payment.ID, payment.ReversalReason)))
+	}
+	return reversals
+}
@@ -0,0 +1,195 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) GetTrialBalance(ctx context.Context, asOf time.Time) ([]domain.TrialBalanceAccount, error) {
+	args := m.Called(ctx, asOf)
+	return args.Get(0).([]domain.TrialBalanceAccount), args.Error(1)
+}
+
+func (m *MockLedgerRepository) GetInterestAccrualTotals(ctx context.Context, from, to time.Time) ([]domain.InterestAccrualTotal, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).([]domain.InterestAccrualTotal), args.Error(1)
+}
+
+func (m *MockLedgerRepository) GetWriteOffs(ctx context.Context, from, to time.Time) ([]domain.LoanWriteOff, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).([]domain.LoanWriteOff), args.Error(1)
+}
+
+func (m *MockLedgerRepository) GetRecoveries(ctx context.Context, from, to time.Time) ([]domain.Recovery, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).([]domain.Recovery), args.Error(1)
+}
+
+// postedEntries returns the journal entries posted through the mock, in order.
+func (m *MockLoanRepository) postedEntries() []domain.JournalEntry {
+	var entries []domain.JournalEntry
+	for _, call := range m.Calls {
+		if call.Method == "PostJournalEntry" {
+			entries = append(entries, *call.Arguments.Get(1).(*domain.JournalEntry))
+		}
+	}
+	return entries
+}
+
+func TestPayOffClearsTheLedger(t *testing.T) {
+	asOf := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(asOf)), WithInterestRebatePolicy(RebateProRata))
+	ctx := context.Background()
+	// the unpaid weeks as disbursed, 600000 principal and 60000 interest
+	loan, schedules := payoffFixture(1, asOf)
+	disbursement := disbursementEntry(loan, schedules)
+	// what the payoff leaves in the schedule, without late fees the whole amount goes to the installments
+	settled, _ := settleSchedules(schedules, domain.NewMoneyFromUnits(620000), domain.NewMoneyFromUnits(40000))
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(settled, nil)
+	mockRepo.On("GetLateFees", ctx, uint(1)).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("UpdateLoan", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+
+	_, err := loanUsecase.PayOff(ctx, 1, domain.NewMoneyFromUnits(620000))
+	assert.NoError(t, err)
+
+	entries := append([]domain.JournalEntry{*disbursement}, mockRepo.postedEntries()...)
+	kinds := make([]domain.JournalEntryKind, 0, len(entries))
+	for _, entry := range entries {
+		assert.NoError(t, entry.Validate())
+		kinds = append(kinds, entry.Kind)
+	}
+	assert.Equal(t, []domain.JournalEntryKind{domain.JournalDisbursement, domain.JournalCollection, domain.JournalRebate, domain.JournalAccrual}, kinds)
+
+	balances := domain.LedgerBalances(entries)
+	for _, account := range append(domain.ReceivableAccounts, domain.AccountUnearnedIncome) {
+		assert.True(t, balances[account].IsZero(), "%s is %s", account, balances[account])
+	}
+	assert.Equal(t, "20000.00", balances[domain.AccountCash].String())
+	// nothing accrued yet, the interest of the two weeks already due is earned on settlement and the rest was rebated
+	assert.Equal(t, "-20000.00", balances[domain.AccountInterestIncome].String())
+}
+
+func TestPaymentEntriesCollectLateFeesAndEarnInstallmentFees(t *testing.T) {
+	payment := &domain.Payment{ID: 7, LoanID: 1, Amount: domain.NewMoneyFromUnits(115500), Allocations: []domain.PaymentAllocation{
+		{BillingScheduleID: 105, Period: 5, LateFeeID: 3, Amount: domain.NewMoneyFromUnits(5000), Fee: domain.NewMoneyFromUnits(5000)},
+		{BillingScheduleID: 105, Period: 5, Amount: domain.NewMoneyFromUnits(110500), Principal: domain.NewMoneyFromUnits(100000), Interest: domain.NewMoneyFromUnits(10000), Fee: domain.NewMoneyFromUnits(500)},
+	}}
+
+	entries := paymentEntries(payment)
+
+	assert.Len(t, entries, 2)
+	balances := map[domain.LedgerAccount]domain.Money{}
+	for _, entry := range entries {
+		assert.NoError(t, entry.Validate())
+		assert.Equal(t, uint(7), entry.PaymentID)
+		for account, balance := range domain.LedgerBalances([]domain.JournalEntry{*entry}) {
+			balances[account] = balances[account].Add(balance)
+		}
+	}
+	assert.Equal(t, "115500.00", balances[domain.AccountCash].String())
+	assert.Equal(t, "-100000.00", balances[domain.AccountLoansReceivable].String())
+	assert.Equal(t, "-10000.00", balances[domain.AccountInterestReceivable].String())
+	assert.Equal(t, "-5500.00", balances[domain.AccountFeesReceivable].String())
+	// the interest is earned as it accrues
+	assert.Equal(t, "500.00", balances[domain.AccountUnearnedIncome].String())
+	assert.True(t, balances[domain.AccountInterestIncome].IsZero())
+	assert.Equal(t, "-500.00", balances[domain.AccountFeeIncome].String())
+}
+
+func TestTransitionLoanPostsWriteOff(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+	loan, schedules := payoffFixture(1, now)
+	loan.Status = domain.LoanStatusDelinquent
+	// the interest of the overdue week accrued but was never collected
+	schedules[0].AccruedInterest = domain.NewMoneyFromUnits(10000)
+	fees := []domain.LateFee{{ID: 3, LoanID: 1, Amount: domain.NewMoneyFromUnits(5000)}}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("GetLateFees", ctx, uint(1)).Return(fees, nil)
+	mockRepo.On("WriteOffLoan", ctx, loan, mock.Anything).Return(nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	_, err := loanUsecase.TransitionLoan(ctx, 1, domain.LoanEventWriteOff, "uncollectable")
+	assert.NoError(t, err)
+
+	entries := mockRepo.postedEntries()
+	assert.Len(t, entries, 2)
+	assert.Equal(t, domain.JournalWriteOff, entries[0].Kind)
+	assert.Equal(t, domain.JournalAccrual, entries[1].Kind)
+	for _, entry := range entries {
+		assert.NoError(t, entry.Validate())
+	}
+	balances := domain.LedgerBalances(entries)
+	assert.Equal(t, "605000.00", balances[domain.AccountWriteOffExpense].String())
+	assert.Equal(t, "-600000.00", balances[domain.AccountLoansReceivable].String())
+	// the accrued interest is taken back, the unearned interest left is what never accrued
+	assert.Equal(t, "10000.00", balances[domain.AccountInterestIncome].String())
+	assert.Equal(t, "50000.00", balances[domain.AccountUnearnedIncome].String())
+}
+
+func TestReversalEntriesSkipReversedEntries(t *testing.T) {
+	payment := &domain.Payment{ID: 7, LoanID: 1, ReversalReason: "cheque bounced"}
+	entries := []domain.JournalEntry{
+		{ID: 1, LoanID: 1, Kind: domain.JournalDisbursement},
+		{ID: 2, LoanID: 1, PaymentID: 7, Kind: domain.JournalCollection, Postings: []domain.Posting{
+			{Account: domain.AccountCash, Debit: domain.NewMoneyFromUnits(100)},
+			{Account: domain.AccountLoansReceivable, Credit: domain.NewMoneyFromUnits(100)},
+		}},
+		{ID: 3, LoanID: 1, PaymentID: 7, Kind: domain.JournalAccrual},
+		{ID: 4, LoanID: 1, PaymentID: 7, Kind: domain.JournalReversal, ReversesEntryID: 3},
+		{ID: 5, LoanID: 1, PaymentID: 8, Kind: domain.JournalCollection},
+	}
+
+	reversals := reversalEntries(entries, payment)
+
+	assert.Len(t, reversals, 1)
+	assert.Equal(t, uint(2), reversals[0].ReversesEntryID)
+	assert.Equal(t, "payment 7 reversed: cheque bounced", reversals[0].Description)
+	assert.Equal(t, domain.Posting{Account: domain.AccountCash, Credit: domain.NewMoneyFromUnits(100)}, reversals[0].Postings[0])
+}
+
+func TestGetTrialBalance(t *testing.T) {
+	asOf := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLedgerRepository)
+	ledgerUsecase := NewLedgerUsecase(mockRepo, clock.NewFake(asOf))
+	ctx := context.Background()
+
+	mockRepo.On("GetTrialBalance", ctx, asOf).Return([]domain.TrialBalanceAccount{
+		{Code: domain.AccountCash, Type: domain.AccountAsset, Debit: domain.NewMoneyFromUnits(300), Credit: domain.NewMoneyFromUnits(1000)},
+		{Code: domain.AccountLoansReceivable, Type: domain.AccountAsset, Debit: domain.NewMoneyFromUnits(1000), Credit: domain.NewMoneyFromUnits(250)},
+		{Code: domain.AccountInterestIncome, Type: domain.AccountIncome, Credit: domain.NewMoneyFromUnits(50)},
+	}, nil)
+
+	balance, err := ledgerUsecase.GetTrialBalance(ctx, time.Time{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, asOf, balance.AsOf)
+	assert.Equal(t, "-700.00", balance.Accounts[0].Balance.String())
+	assert.Equal(t, "750.00", balance.Accounts[1].Balance.String())
+	assert.Equal(t, "50.00", balance.Accounts[2].Balance.String())
+	assert.Equal(t, "1300.00", balance.TotalDebit.String())
+	assert.Equal(t, "1300.00", balance.TotalCredit.String())
+	assert.True(t, balance.Balanced)
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type LedgerUsecase interface {
+	// GetTrialBalance sums the postings of every ledger account up to asOf, a zero asOf is now.
+	GetTrialBalance(ctx context.Context, asOf time.Time) (*domain.TrialBalance, error)
+	// GetInterestAccrualReport sums the interest accrued on all loans day by day from from to to, to defaults to
+	// today and from to the first day of the month of to.
+	GetInterestAccrualReport(ctx context.Context, from, to time.Time) (*domain.InterestAccrualReport, error)
+	// GetWriteOffReport lists the loans written off and the recoveries collected from from to to, with the same
+	// defaults as GetInterestAccrualReport.
+	GetWriteOffReport(ctx context.Context, from, to time.Time) (*domain.WriteOffReport, error)
+}
+
+type ledgerUsecase struct {
+	ledgerRepo domain.LedgerRepository
+	clock      clock.Clock
+}
+
+func NewLedgerUsecase(ledgerRepo domain.LedgerRepository, clk clock.Clock) LedgerUsecase {
+	return &ledgerUsecase{ledgerRepo: ledgerRepo, clock: clk}
+}
+
+func (u *ledgerUsecase) GetTrialBalance(ctx context.Context, asOf time.Time) (*domain.TrialBalance, error) {
+	if asOf.IsZero() {
+		asOf = u.clock.Now()
+	}
+	accounts, err := u.ledgerRepo.GetTrialBalance(ctx, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &domain.TrialBalance{AsOf: asOf, Accounts: accounts}
+	for i := range balance.Accounts {
+		account := &balance.Accounts[i]
+		account.Balance = account.Credit.Sub(account.Debit)
+		if account.Type.DebitNormal() {
+			account.Balance = account.Debit.Sub(account.Credit)
+		}
+		balance.TotalDebit = balance.TotalDebit.Add(account.Debit)
+		balance.TotalCredit = balance.TotalCredit.Add(account.Credit)
+	}
+	balance.Balanced = balance.TotalDebit.Equal(balance.TotalCredit)
+	return balance, nil
+}
+
+func (u *ledgerUsecase) GetInterestAccrualReport(ctx context.Context, from, to time.Time) (*domain.InterestAccrualReport, error) {
+	from, to = u.reportPeriod(from, to)
+	days, err := u.ledgerRepo.GetInterestAccrualTotals(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.InterestAccrualReport{
+		From: pgtype.Date{Time: from, Valid: true},
+		To:   pgtype.Date{Time: to, Valid: true},
+		Days: days,
+	}
+	for _, day := range days {
+		report.Total = report.Total.Add(day.Amount)
+	}
+	return report, nil
+}
+
+func (u *ledgerUsecase) GetWriteOffReport(ctx context.Context, from, to time.Time) (*domain.WriteOffReport, error) {
+	from, to = u.reportPeriod(from, to)
+	// both days are included
+	end := to.AddDate(0, 0, 1)
+
+	writeOffs, err := u.ledgerRepo.GetWriteOffs(ctx, from, end)
+	if err != nil {
+		return nil, err
+	}
+	recoveries, err := u.ledgerRepo.GetRecoveries(ctx, from, end)
+	if err != nil {
+		return nil, err
+	}
+	report := &domain.WriteOffReport{
+		From:       pgtype.Date{Time: from, Valid: true},
+		To:         pgtype.Date{Time: to, Valid: true},
+		WriteOffs:  writeOffs,
+		Recoveries: recoveries,
+	}
+	for _, writeOff := range writeOffs {
+		report.WrittenOff = report.WrittenOff.Add(writeOff.Amount)
+	}
+	for _, recovery := range recoveries {
+		report.Recovered = report.Recovered.Add(recovery.Amount)
+	}
+	return report, nil
+}
+
+// reportPeriod truncates from and to to days, to defaults to today and from to the first day of the month of to.
+func (u *ledgerUsecase) reportPeriod(from, to time.Time) (time.Time, time.Time) {
+	if to.IsZero() {
+		to = u.clock.Now()
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	if from.IsZero() {
+		from = to.AddDate(0, 0, 1-to.Day())
+	}
+	return time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC), to
+}
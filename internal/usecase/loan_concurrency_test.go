@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+// loanStore holds a single loan shared by every transaction of a lockingLoanRepository.
+type loanStore struct {
+	rowLock   sync.Mutex // stands in for the row lock taken by SELECT ... FOR UPDATE
+	mu        sync.Mutex
+	loan      domain.Loan
+	schedules []domain.BillingSchedule
+	payments  int
+}
+
+// lockingLoanRepository is an in-memory repository that only serialises callers who lock the loan,
+// any read-check-write done without GetLoanByIDForUpdate races like it would against the database.
+type lockingLoanRepository struct {
+	domain.LoanRepository
+	store  *loanStore
+	locked bool
+}
+
+func (r *lockingLoanRepository) WithinTransaction(ctx context.Context, fn func(repo domain.LoanRepository) error) error {
+	tx := &lockingLoanRepository{store: r.store}
+	defer func() {
+		if tx.locked {
+			r.store.rowLock.Unlock()
+		}
+	}()
+	return fn(tx)
+}
+
+func (r *lockingLoanRepository) GetLoanByID(ctx context.Context, loanID uint) (*domain.Loan, error) {
+	r.store.mu.Lock()
+	loan := r.store.loan
+	r.store.mu.Unlock()
+	// give concurrent payments the chance to read the same balance
+	time.Sleep(time.Millisecond)
+	return &loan, nil
+}
+
+func (r *lockingLoanRepository) GetLoanByIDForUpdate(ctx context.Context, loanID uint) (*domain.Loan, error) {
+	if !r.locked {
+		r.store.rowLock.Lock()
+		r.locked = true
+	}
+	return r.GetLoanByID(ctx, loanID)
+}
+
+func (r *lockingLoanRepository) IsDelinquent(ctx context.Context, loanID uint, asOf time.Time) (*domain.CheckDelinquentAmount, error) {
+	return &domain.CheckDelinquentAmount{LoanID: loanID}, nil
+}
+
+func (r *lockingLoanRepository) GetUnpaidBillingSchedules(ctx context.Context, loanID uint) ([]domain.BillingSchedule, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var unpaid []domain.BillingSchedule
+	for _, schedule := range r.store.schedules {
+		if !schedule.Paid.Bool {
+			unpaid = append(unpaid, schedule)
+		}
+	}
+	return unpaid, nil
+}
+
+func (r *lockingLoanRepository) GetBillingSchedules(ctx context.Context, loanID uint) ([]domain.BillingSchedule, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return append([]domain.BillingSchedule(nil), r.store.schedules...), nil
+}
+
+func (r *lockingLoanRepository) GetLateFees(ctx context.Context, loanID uint) ([]domain.LateFee, error) {
+	return nil, nil
+}
+
+func (r *lockingLoanRepository) UpdateLoan(ctx context.Context, loan *domain.Loan, schedules []domain.BillingSchedule, payment *domain.Payment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.loan = *loan
+	for _, schedule := range schedules {
+		r.store.schedules[schedule.Period-1] = schedule
+	}
+	r.store.payments++
+	return nil
+}
+
+func (r *lockingLoanRepository) TransitionLoanStatus(ctx context.Context, transition *domain.LoanStatusTransition) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.loan.Status = transition.To
+	return nil
+}
+
+func (r *lockingLoanRepository) PostJournalEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	return entry.Validate()
+}
+
+func TestMakePaymentConcurrentPaymentsAreNotLost(t *testing.T) {
+	const weeks = 10
+	installment := domain.NewMoneyFromUnits(100)
+	store := &loanStore{
+		loan: domain.Loan{ID: 1, Status: domain.LoanStatusActive, Amount: installment.MulInt(weeks), Outstanding: installment.MulInt(weeks), TenorPeriods: weeks, InstallmentAmount: installment},
+	}
+	for week := 1; week <= weeks; week++ {
+		store.schedules = append(store.schedules, domain.BillingSchedule{
+			ID:        uint(week),
+			LoanID:    1,
+			Period:    uint(week),
+			Amount:    installment,
+			Principal: installment,
+			DueDate:   pgtype.Date{Time: time.Now().AddDate(0, 0, 7*week), Valid: true},
+		})
+	}
+	loanUsecase := NewLoanUsecase(&lockingLoanRepository{store: store}, new(MockLoanProductRepository))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, weeks)
+	for i := 0; i < weeks; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := loanUsecase.MakePayment(context.Background(), 1, installment)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, weeks, store.payments)
+	assert.True(t, store.loan.Outstanding.IsZero(), "outstanding is %s", store.loan.Outstanding)
+	assert.Equal(t, domain.LoanStatusPaidOff, store.loan.Status)
+	for _, schedule := range store.schedules {
+		assert.True(t, schedule.Paid.Bool, "week %d is unpaid", schedule.Period)
+	}
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"fmt"
+	"time"
+)
+
+func (lu *loanUsecase) GetLoan(ctx context.Context, loanID uint) (*domain.LoanDetail, error) {
+	loan, err := lu.loanRepo.GetLoanByID(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	borrower, err := lu.loanRepo.GetLoanBorrower(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := lu.loanRepo.GetBillingSchedules(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("loan usecase: failed to get billing schedules: %w", err)
+	}
+	outstanding, err := lu.outstanding(ctx, loan)
+	if err != nil {
+		return nil, err
+	}
+	arrears, err := lu.arrears(ctx, loan)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &domain.LoanDetail{
+		Loan:        *loan,
+		Borrower:    *borrower,
+		Schedule:    lu.installments(loan, currentSchedules(schedules), lu.clock.Now()),
+		Outstanding: *outstanding,
+		Arrears:     *arrears,
+	}
+	for _, installment := range detail.Schedule {
+		if installment.State == domain.InstallmentUnpaid {
+			detail.NextDue = &domain.NextInstallment{
+				Period:  installment.Period,
+				DueDate: installment.DueDate,
+				Amount:  installment.Remaining,
+			}
+			break
+		}
+	}
+	return detail, nil
+}
+
+// installments tells the state of every one of schedules as of asOf, installments are overdue like in the arrears
+// once their due date is past.
+func (lu *loanUsecase) installments(loan *domain.Loan, schedules []domain.BillingSchedule, asOf time.Time) []domain.LoanInstallment {
+	cutoff := lu.arrearsCutoff(asOf)
+	installments := make([]domain.LoanInstallment, 0, len(schedules))
+	for _, schedule := range schedules {
+		installment := domain.LoanInstallment{BillingSchedule: schedule, Remaining: schedule.Remaining(), State: domain.InstallmentUnpaid}
+		switch {
+		case schedule.Paid.Bool:
+			// a payoff settles the installments it rebated without paying them in full
+			installment.Remaining = domain.Money{}
+			installment.State = domain.InstallmentPaid
+		case loan.Status == domain.LoanStatusWrittenOff:
+			installment.State = domain.InstallmentWrittenOff
+		case schedule.DueDate.Time.Before(cutoff):
+			installment.State = domain.InstallmentOverdue
+		}
+		installments = append(installments, installment)
+	}
+	return installments
+}
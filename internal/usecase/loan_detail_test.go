@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetLoanTellsTheStateOfEveryInstallment(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+	loan, unpaid := payoffFixture(1, now)
+	loan.Status = domain.LoanStatusDelinquent
+	paid := domain.BillingSchedule{
+		ID: 104, LoanID: 1, Period: 4, Amount: loan.InstallmentAmount, PaidAmount: loan.InstallmentAmount,
+		Paid:    pgtype.Bool{Bool: true, Valid: true},
+		DueDate: pgtype.Date{Time: time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC), Valid: true},
+	}
+	// the last installment is half paid
+	unpaid[5].PaidAmount = domain.NewMoneyFromUnits(55000)
+	schedules := append([]domain.BillingSchedule{paid}, unpaid...)
+	borrower := &domain.LoanBorrower{ID: 9, Name: "Jane Doe", Email: "jane@example.com"}
+
+	mockRepo.On("GetLoanByID", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetLoanBorrower", ctx, uint(1)).Return(borrower, nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(unpaid, nil)
+	mockRepo.On("GetLateFees", ctx, uint(1)).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("IsDelinquent", ctx, uint(1), now).Return(&domain.CheckDelinquentAmount{LoanID: 1, TotalWeek: 2, Amount: domain.NewMoneyFromUnits(220000)}, nil)
+
+	detail, err := loanUsecase.GetLoan(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, *borrower, detail.Borrower)
+	assert.Equal(t, domain.LoanStatusDelinquent, detail.Loan.Status)
+	states := make([]domain.InstallmentState, 0, len(detail.Schedule))
+	for _, installment := range detail.Schedule {
+		states = append(states, installment.State)
+	}
+	// the fifth installment was due last week and the sixth earlier today
+	assert.Equal(t, []domain.InstallmentState{
+		domain.InstallmentPaid, domain.InstallmentOverdue, domain.InstallmentOverdue,
+		domain.InstallmentUnpaid, domain.InstallmentUnpaid, domain.InstallmentUnpaid, domain.InstallmentUnpaid,
+	}, states)
+	assert.True(t, detail.Schedule[0].Remaining.IsZero())
+	assert.Equal(t, "55000.00", detail.Schedule[6].Remaining.String())
+
+	// what was paid on the last installment settled its interest first
+	assert.Equal(t, "555000.00", detail.Outstanding.Components.Principal.String())
+	assert.Equal(t, "50000.00", detail.Outstanding.Components.Interest.String())
+	assert.Equal(t, 2, detail.Arrears.TotalWeek)
+	assert.True(t, detail.Arrears.IsDelinquent)
+	assert.Equal(t, uint(7), detail.NextDue.Period)
+	assert.Equal(t, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), detail.NextDue.DueDate.Time)
+	assert.Equal(t, "110000.00", detail.NextDue.Amount.String())
+}
+
+func TestGetLoanOfWrittenOffLoanOwesNothing(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+	loan, schedules := payoffFixture(1, now)
+	loan.Status = domain.LoanStatusWrittenOff
+	loan.Outstanding = domain.Money{}
+
+	mockRepo.On("GetLoanByID", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetLoanBorrower", ctx, uint(1)).Return(&domain.LoanBorrower{ID: 9}, nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+
+	detail, err := loanUsecase.GetLoan(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, detail.Schedule, 6)
+	for _, installment := range detail.Schedule {
+		assert.Equal(t, domain.InstallmentWrittenOff, installment.State)
+	}
+	assert.True(t, detail.Outstanding.Total.IsZero())
+	assert.True(t, detail.Arrears.Amount.IsZero())
+	assert.Nil(t, detail.NextDue)
+	mockRepo.AssertNotCalled(t, "IsDelinquent", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrJobAlreadyRunning is returned when a run starts while the previous one is still going.
+var ErrJobAlreadyRunning = errors.New("job is already running")
+
+// DefaultJobBatchSize is how many loans a job lists per query when no batch size is configured.
+const DefaultJobBatchSize = 100
+
+// jobRecord is the run record of a job, R with the counters of the job around a domain.JobRun.
+type jobRecord[R any] interface {
+	*R
+	Job() *domain.JobRun
+}
+
+// loanJobStep processes one loan as of asOf and returns how to count what it did in the run record.
+type loanJobStep[P any] func(ctx context.Context, loanID uint, asOf time.Time) (func(run P), error)
+
+// loanJob runs a step over every active or delinquent loan, a batch of loans at a time, and keeps the record of
+// its latest run. Only one run goes at a time.
+type loanJob[R any, P jobRecord[R]] struct {
+	name      string
+	loanRepo  domain.LoanRepository
+	clock     clock.Clock
+	batchSize int
+	step      loanJobStep[P]
+
+	mu      sync.Mutex
+	lastRun P
+}
+
+func newLoanJob[R any, P jobRecord[R]](name string, lr domain.LoanRepository, clk clock.Clock, batchSize int, step loanJobStep[P]) *loanJob[R, P] {
+	if batchSize <= 0 {
+		batchSize = DefaultJobBatchSize
+	}
+	return &loanJob[R, P]{name: name, loanRepo: lr, clock: clk, batchSize: batchSize, step: step}
+}
+
+func (j *loanJob[R, P]) Run(ctx context.Context) (P, error) {
+	asOf := j.clock.Now()
+	if err := j.start(asOf); err != nil {
+		return nil, err
+	}
+
+	statuses := []domain.LoanStatus{domain.LoanStatusActive, domain.LoanStatusDelinquent}
+	var afterID uint
+	for {
+		loanIDs, err := j.loanRepo.ListLoanIDsByStatus(ctx, statuses, afterID, j.batchSize)
+		if err != nil {
+			j.update(func(r P) {
+				r.Job().Failures++
+				r.Job().Error = err.Error()
+			})
+			break
+		}
+
+		for _, loanID := range loanIDs {
+			count, err := j.step(ctx, loanID, asOf)
+			j.update(func(r P) {
+				run := r.Job()
+				run.LoansChecked++
+				if err != nil {
+					log.Printf("%s: failed on loan %d: %v", j.name, loanID, err)
+					run.Failures++
+					if run.Error == "" {
+						run.Error = fmt.Sprintf("loan %d: %v", loanID, err)
+					}
+					return
+				}
+				count(r)
+			})
+		}
+
+		if len(loanIDs) < j.batchSize {
+			break
+		}
+		afterID = loanIDs[len(loanIDs)-1]
+	}
+
+	return j.finish(), nil
+}
+
+func (j *loanJob[R, P]) LastRun() P {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.lastRun == nil {
+		return nil
+	}
+	run := *j.lastRun
+	return &run
+}
+
+func (j *loanJob[R, P]) start(asOf time.Time) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.lastRun != nil && j.lastRun.Job().Status == domain.JobRunning {
+		return ErrJobAlreadyRunning
+	}
+	j.lastRun = new(R)
+	*j.lastRun.Job() = domain.JobRun{Status: domain.JobRunning, AsOf: asOf, StartedAt: j.clock.Now()}
+	return nil
+}
+
+func (j *loanJob[R, P]) update(fn func(run P)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(j.lastRun)
+}
+
+func (j *loanJob[R, P]) finish() P {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	run := j.lastRun.Job()
+	finishedAt := j.clock.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = domain.JobSucceeded
+	if run.Failures > 0 {
+		run.Status = domain.JobFailed
+	}
+	finished := *j.lastRun
+	return &finished
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLoanJobRejectsOverlappingRuns(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	ctx := context.Background()
+	started, release := make(chan struct{}), make(chan struct{})
+	job := newLoanJob[domain.DelinquencyRun]("test job", mockRepo, clock.New(), 10,
+		func(ctx context.Context, loanID uint, asOf time.Time) (func(run *domain.DelinquencyRun), error) {
+			close(started)
+			<-release
+			return func(run *domain.DelinquencyRun) { run.LoansUpdated++ }, nil
+		})
+
+	mockRepo.On("ListLoanIDsByStatus", ctx, mock.Anything, uint(0), 10).Return([]uint{1}, nil)
+
+	done := make(chan *domain.DelinquencyRun)
+	go func() {
+		run, _ := job.Run(ctx)
+		done <- run
+	}()
+	<-started
+
+	_, err := job.Run(ctx)
+	assert.ErrorIs(t, err, ErrJobAlreadyRunning)
+	assert.Equal(t, domain.JobRunning, job.LastRun().Status)
+
+	close(release)
+	run := <-done
+	assert.Equal(t, domain.JobSucceeded, run.Status)
+	assert.Equal(t, 1, run.LoansChecked)
+	assert.Equal(t, 1, run.LoansUpdated)
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/internal/utils"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type LoanProductUsecase interface {
+	CreateLoanProduct(ctx context.Context, terms LoanProductTerms) (*domain.LoanProduct, error)
+	GetLoanProduct(ctx context.Context, productID uint) (*domain.LoanProduct, error)
+	ListLoanProducts(ctx context.Context) ([]domain.LoanProduct, error)
+	SetLoanProductActive(ctx context.Context, productID uint, active bool) error
+}
+
+// LoanProductTerms is what a new loan product offers. Rates are in percent, InterestRate is for the whole term
+// with flat interest and per year otherwise, OriginationFeeRate is charged once on the loan amount.
+type LoanProductTerms struct {
+	Name               string
+	MinAmount          domain.Money
+	MaxAmount          domain.Money
+	InterestRate       *big.Rat
+	InterestMethod     domain.InterestMethod
+	TenorPeriods       []int
+	RepaymentFrequency domain.RepaymentFrequency
+	InstallmentFee     domain.Money
+	OriginationFeeRate *big.Rat
+}
+
+type loanProductUsecase struct {
+	productRepo domain.LoanProductRepository
+}
+
+func NewLoanProductUsecase(pr domain.LoanProductRepository) LoanProductUsecase {
+	return &loanProductUsecase{productRepo: pr}
+}
+
+func (pu *loanProductUsecase) CreateLoanProduct(ctx context.Context, terms LoanProductTerms) (*domain.LoanProduct, error) {
+	product := &domain.LoanProduct{
+		Name:               strings.TrimSpace(terms.Name),
+		MinAmount:          terms.MinAmount,
+		MaxAmount:          terms.MaxAmount,
+		InterestMethod:     terms.InterestMethod,
+		RepaymentFrequency: terms.RepaymentFrequency,
+		InstallmentFee:     terms.InstallmentFee,
+		Active:             true,
+	}
+	if product.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", domain.ErrInvalidLoanProduct)
+	}
+	if product.MinAmount.Sign() <= 0 || product.MaxAmount.Cmp(product.MinAmount) < 0 {
+		return nil, fmt.Errorf("%w: amounts must be positive with min_amount at most max_amount", domain.ErrInvalidLoanProduct)
+	}
+	if product.InstallmentFee.Sign() < 0 {
+		return nil, fmt.Errorf("%w: installment fee must not be negative", domain.ErrInvalidLoanProduct)
+	}
+	if product.InterestMethod == "" {
+		product.InterestMethod = domain.InterestFlat
+	}
+	if _, err := domain.ParseInterestMethod(string(product.InterestMethod)); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidLoanProduct, err)
+	}
+	frequency, err := domain.ParseRepaymentFrequency(string(product.RepaymentFrequency))
+	if err != nil {
+		return nil, err
+	}
+	product.RepaymentFrequency = frequency
+
+	if len(terms.TenorPeriods) == 0 {
+		return nil, fmt.Errorf("%w: at least one tenor is required", domain.ErrInvalidLoanProduct)
+	}
+	seen := make(map[int]bool, len(terms.TenorPeriods))
+	for _, periods := range terms.TenorPeriods {
+		if periods <= 0 {
+			return nil, fmt.Errorf("%w: tenors must be positive", domain.ErrInvalidLoanProduct)
+		}
+		if !seen[periods] {
+			seen[periods] = true
+			product.TenorPeriods = append(product.TenorPeriods, periods)
+		}
+	}
+	sort.Ints(product.TenorPeriods)
+
+	if product.InterestRate, err = percentToNumeric(terms.InterestRate); err != nil {
+		return nil, fmt.Errorf("%w: interest rate %v", domain.ErrInvalidLoanProduct, err)
+	}
+	if product.OriginationFeeRate, err = percentToNumeric(terms.OriginationFeeRate); err != nil {
+		return nil, fmt.Errorf("%w: origination fee rate %v", domain.ErrInvalidLoanProduct, err)
+	}
+	return pu.productRepo.CreateLoanProduct(ctx, product)
+}
+
+// percentToNumeric stores a percent as the fraction kept in rate columns, nil is zero.
+func percentToNumeric(percent *big.Rat) (pgtype.Numeric, error) {
+	if percent == nil {
+		percent = new(big.Rat)
+	}
+	if percent.Sign() < 0 {
+		return pgtype.Numeric{}, fmt.Errorf("must not be negative")
+	}
+	return utils.RatToNumeric(new(big.Rat).Quo(percent, big.NewRat(100, 1)), 6)
+}
+
+func (pu *loanProductUsecase) GetLoanProduct(ctx context.Context, productID uint) (*domain.LoanProduct, error) {
+	return pu.productRepo.GetLoanProductByID(ctx, productID)
+}
+
+func (pu *loanProductUsecase) ListLoanProducts(ctx context.Context) ([]domain.LoanProduct, error) {
+	return pu.productRepo.ListLoanProducts(ctx)
+}
+
+func (pu *loanProductUsecase) SetLoanProductActive(ctx context.Context, productID uint, active bool) error {
+	return pu.productRepo.SetLoanProductActive(ctx, productID, active)
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockLoanProductRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoanProductRepository) CreateLoanProduct(ctx context.Context, product *domain.LoanProduct) (*domain.LoanProduct, error) {
+	args := m.Called(ctx, product)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoanProduct), args.Error(1)
+}
+
+func (m *MockLoanProductRepository) GetLoanProductByID(ctx context.Context, productID uint) (*domain.LoanProduct, error) {
+	args := m.Called(ctx, productID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoanProduct), args.Error(1)
+}
+
+func (m *MockLoanProductRepository) ListLoanProducts(ctx context.Context) ([]domain.LoanProduct, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.LoanProduct), args.Error(1)
+}
+
+func (m *MockLoanProductRepository) SetLoanProductActive(ctx context.Context, productID uint, active bool) error {
+	args := m.Called(ctx, productID, active)
+	return args.Error(0)
+}
+
+// testProduct lends 1,000,000 to 10,000,000 over 10 or 50 weeks at 10% flat.
+func testProduct() *domain.LoanProduct {
+	return &domain.LoanProduct{
+		ID:                 3,
+		Name:               "Weekly Flat",
+		MinAmount:          domain.NewMoneyFromUnits(1000000),
+		MaxAmount:          domain.NewMoneyFromUnits(10000000),
+		InterestRate:       pgtype.Numeric{Int: big.NewInt(10), Exp: -2, Valid: true},
+		InterestMethod:     domain.InterestFlat,
+		TenorPeriods:       []int{10, 50},
+		RepaymentFrequency: domain.FrequencyWeekly,
+		OriginationFeeRate: pgtype.Numeric{Int: big.NewInt(0), Valid: true},
+		Active:             true,
+	}
+}
+
+func TestCreateLoanProduct(t *testing.T) {
+	mockRepo := new(MockLoanProductRepository)
+	productUsecase := NewLoanProductUsecase(mockRepo)
+	ctx := context.Background()
+
+	var created *domain.LoanProduct
+	mockRepo.On("CreateLoanProduct", ctx, mock.Anything).
+		Run(func(args mock.Arguments) { created = args.Get(1).(*domain.LoanProduct) }).
+		Return(&domain.LoanProduct{ID: 1}, nil)
+
+	_, err := productUsecase.CreateLoanProduct(ctx, LoanProductTerms{
+		Name:               " Annuity 26% ",
+		MinAmount:          domain.NewMoneyFromUnits(500000),
+		MaxAmount:          domain.NewMoneyFromUnits(5000000),
+		InterestRate:       big.NewRat(1275, 100),
+		InterestMethod:     domain.InterestAnnuity,
+		TenorPeriods:       []int{52, 26, 26},
+		OriginationFeeRate: big.NewRat(1, 1),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Annuity 26%", created.Name)
+	assert.Equal(t, []int{26, 52}, created.TenorPeriods)
+	assert.Equal(t, domain.FrequencyWeekly, created.RepaymentFrequency)
+	assert.Equal(t, pgtype.Numeric{Int: big.NewInt(127500), Exp: -6, Valid: true}, created.InterestRate)
+	assert.Equal(t, pgtype.Numeric{Int: big.NewInt(10000), Exp: -6, Valid: true}, created.OriginationFeeRate)
+	assert.True(t, created.Active)
+}
+
+func TestCreateLoanProductRejectsInvalidTerms(t *testing.T) {
+	valid := LoanProductTerms{
+		Name:         "Weekly Flat",
+		MinAmount:    domain.NewMoneyFromUnits(1000000),
+		MaxAmount:    domain.NewMoneyFromUnits(10000000),
+		InterestRate: big.NewRat(10, 1),
+		TenorPeriods: []int{10, 50},
+	}
+	tests := map[string]func(terms *LoanProductTerms){
+		"no name":              func(terms *LoanProductTerms) { terms.Name = " " },
+		"inverted range":       func(terms *LoanProductTerms) { terms.MaxAmount = domain.NewMoneyFromUnits(1) },
+		"no tenor":             func(terms *LoanProductTerms) { terms.TenorPeriods = nil },
+		"negative tenor":       func(terms *LoanProductTerms) { terms.TenorPeriods = []int{-1} },
+		"unknown method":       func(terms *LoanProductTerms) { terms.InterestMethod = "balloon" },
+		"unknown frequency":    func(terms *LoanProductTerms) { terms.RepaymentFrequency = "hourly" },
+		"too precise rate":     func(terms *LoanProductTerms) { terms.InterestRate = big.NewRat(1, 3) },
+		"negative fee":         func(terms *LoanProductTerms) { terms.InstallmentFee = domain.NewMoneyFromUnits(-1) },
+		"negative origination": func(terms *LoanProductTerms) { terms.OriginationFeeRate = big.NewRat(-1, 1) },
+	}
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockLoanProductRepository)
+			terms := valid
+			change(&terms)
+
+			_, err := NewLoanProductUsecase(mockRepo).CreateLoanProduct(context.Background(), terms)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidLoanProduct)
+			mockRepo.AssertNotCalled(t, "CreateLoanProduct")
+		})
+	}
+}
+
+func TestCreateLoanValidatesAgainstProduct(t *testing.T) {
+	ctx := context.Background()
+	inactive := testProduct()
+	inactive.Active = false
+	tests := map[string]struct {
+		application LoanApplication
+		product     *domain.LoanProduct
+		err         error
+	}{
+		"no product":      {application: LoanApplication{BorrowerID: 2, Amount: domain.NewMoneyFromUnits(1000000), TenorPeriods: 10}},
+		"unknown product": {application: LoanApplication{BorrowerID: 2, ProductID: 3, Amount: domain.NewMoneyFromUnits(1000000), TenorPeriods: 10}, err: domain.ErrLoanProductNotFound},
+		"closed product":  {application: LoanApplication{BorrowerID: 2, ProductID: 3, Amount: domain.NewMoneyFromUnits(1000000), TenorPeriods: 10}, product: inactive},
+		"below minimum":   {application: LoanApplication{BorrowerID: 2, ProductID: 3, Amount: domain.NewMoneyFromUnits(999999), TenorPeriods: 10}, product: testProduct()},
+		"above maximum":   {application: LoanApplication{BorrowerID: 2, ProductID: 3, Amount: domain.NewMoneyFromUnits(10000001), TenorPeriods: 10}, product: testProduct()},
+		"unknown tenor":   {application: LoanApplication{BorrowerID: 2, ProductID: 3, Amount: domain.NewMoneyFromUnits(1000000), TenorPeriods: 7}, product: testProduct()},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(MockLoanRepository)
+			productRepo := new(MockLoanProductRepository)
+			if tt.product != nil {
+				productRepo.On("GetLoanProductByID", ctx, uint(3)).Return(tt.product, nil)
+			} else {
+				productRepo.On("GetLoanProductByID", ctx, uint(3)).Return(nil, tt.err)
+			}
+
+			_, err := NewLoanUsecase(mockRepo, productRepo).CreateLoan(ctx, tt.application)
+
+			assert.ErrorIs(t, err, domain.ErrInvalidLoan)
+			mockRepo.AssertNotCalled(t, "CreateLoan")
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"fmt"
+)
+
+func (lu *loanUsecase) TransitionLoan(ctx context.Context, loanID uint, event domain.LoanEvent, reason string) (*domain.LoanStatusTransition, error) {
+	var transition *domain.LoanStatusTransition
+	err := lu.loanRepo.WithinTransaction(ctx, func(repo domain.LoanRepository) error {
+		loan, err := repo.GetLoanByIDForUpdate(ctx, loanID)
+		if err != nil {
+			return err
+		}
+		if event == domain.LoanEventClose {
+			if err := ensureRepaid(ctx, repo, loan); err != nil {
+				return err
+			}
+		}
+		if event == domain.LoanEventWriteOff {
+			transition, _, err = lu.writeOff(ctx, repo, loan, reason, false)
+			return err
+		}
+		transition, err = transitionLoan(ctx, repo, loan, event, reason)
+		if err != nil {
+			return err
+		}
+		return lu.journalTransition(ctx, repo, loan, event)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return transition, nil
+}
+
+func (lu *loanUsecase) GetStatusHistory(ctx context.Context, loanID uint) ([]domain.LoanStatusTransition, error) {
+	if _, err := lu.loanRepo.GetLoanByID(ctx, loanID); err != nil {
+		return nil, err
+	}
+	return lu.loanRepo.GetLoanStatusHistory(ctx, loanID)
+}
+
+// journalTransition posts the ledger entry of the lifecycle events that move money, disbursing the loan pays out
+// its principal. Writing it off is journalled by writeOff.
+func (lu *loanUsecase) journalTransition(ctx context.Context, repo domain.LoanRepository, loan *domain.Loan, event domain.LoanEvent) error {
+	if event != domain.LoanEventDisburse {
+		return nil
+	}
+	schedules, err := repo.GetUnpaidBillingSchedules(ctx, loan.ID)
+	if err != nil {
+		return fmt.Errorf("loan usecase: failed to get unpaid billing schedules: %w", err)
+	}
+	return postJournalEntries(ctx, repo, disbursementEntry(loan, schedules))
+}
+
+// transitionLoan moves the loan to the status reached by event and records it in the status history,
+// repo must be the unit of work holding the loan row lock.
+func transitionLoan(ctx context.Context, repo domain.LoanRepository, loan *domain.Loan, event domain.LoanEvent, reason string) (*domain.LoanStatusTransition, error) {
+	to, err := loan.Status.Transition(event)
+	if err != nil {
+		return nil, err
+	}
+
+	transition := &domain.LoanStatusTransition{
+		LoanID: loan.ID,
+		From:   loan.Status,
+		To:     to,
+		Event:  event,
+		Reason: reason,
+	}
+	if err := repo.TransitionLoanStatus(ctx, transition); err != nil {
+		return nil, err
+	}
+	loan.Status = to
+	return transition, nil
+}
+
+// ensureRepaid rejects closing a loan while its installments or its late fees are not fully paid.
+func ensureRepaid(ctx context.Context, repo domain.LoanRepository, loan *domain.Loan) error {
+	if loan.Outstanding.Sign() > 0 {
+		return fmt.Errorf("%w: the outstanding %s must be repaid before the loan is closed", domain.ErrInvalidTransition, loan.Outstanding)
+	}
+	fees, err := repo.GetLateFees(ctx, loan.ID)
+	if err != nil {
+		return fmt.Errorf("loan usecase: failed to get late fees: %w", err)
+	}
+	if unpaid := unpaidLateFees(fees); unpaid.Sign() > 0 {
+		return fmt.Errorf("%w: the late fees %s must be paid before the loan is closed", domain.ErrInvalidTransition, unpaid)
+	}
+	return nil
+}
+
+// ensureAcceptsPayments rejects payments on loans that are not disbursed yet or already closed.
+func ensureAcceptsPayments(loan *domain.Loan) error {
+	if !loan.Status.AcceptsPayments() {
+		return fmt.Errorf("%w: loan is %s", domain.ErrLoanNotActive, loan.Status)
+	}
+	if loan.Outstanding.Sign() <= 0 {
+		return domain.ErrLoanFullyPaid
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransitionLoanRecordsHistory(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusPending}, nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+
+	transition, err := loanUsecase.TransitionLoan(ctx, loanID, domain.LoanEventApprove, "documents verified")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusPending, transition.From)
+	assert.Equal(t, domain.LoanStatusApproved, transition.To)
+	assert.Equal(t, "documents verified", transition.Reason)
+}
+
+func TestTransitionLoanRejectsInvalidEvent(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusPending}, nil)
+
+	_, err := loanUsecase.TransitionLoan(ctx, loanID, domain.LoanEventDisburse, "")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+	mockRepo.AssertNotCalled(t, "TransitionLoanStatus")
+}
+
+func TestTransitionLoanCloseRequiresZeroOutstanding(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusActive, Outstanding: domain.NewMoneyFromUnits(100)}, nil)
+
+	_, err := loanUsecase.TransitionLoan(ctx, loanID, domain.LoanEventClose, "")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+	mockRepo.AssertNotCalled(t, "TransitionLoanStatus")
+}
+
+func TestTransitionLoanCloseRequiresPaidLateFees(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+	fees := []domain.LateFee{{ID: 3, LoanID: loanID, Amount: domain.NewMoneyFromUnits(5000), PaidAmount: domain.NewMoneyFromUnits(2000)}}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusDelinquent}, nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return(fees, nil)
+
+	_, err := loanUsecase.TransitionLoan(ctx, loanID, domain.LoanEventClose, "")
+
+	assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+	assert.Contains(t, err.Error(), "3000.00")
+	mockRepo.AssertNotCalled(t, "TransitionLoanStatus")
+}
+
+func TestMakePaymentRejectsLoanNotDisbursed(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusApproved, Outstanding: domain.NewMoneyFromUnits(330000)}, nil)
+
+	_, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(110000))
+
+	assert.ErrorIs(t, err, domain.ErrLoanNotActive)
+	mockRepo.AssertNotCalled(t, "UpdateLoan")
+}
+
+func TestMakePaymentCuresDelinquentLoan(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+	installment := domain.NewMoneyFromUnits(110000)
+	loan := &domain.Loan{ID: loanID, Status: domain.LoanStatusDelinquent, Outstanding: domain.NewMoneyFromUnits(330000), InstallmentAmount: installment}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(loan, nil)
+	mockRepo.On("IsDelinquent", ctx, loanID, mock.Anything).Return(&domain.CheckDelinquentAmount{LoanID: loanID, TotalWeek: 2, Amount: domain.NewMoneyFromUnits(220000), IsDelinquent: true}, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, loanID).Return(weeklySchedules(loanID, 3, installment), nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("UpdateLoan", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+
+	_, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(220000))
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LoanStatusActive, loan.Status)
+	transition := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(*domain.LoanStatusTransition)
+	assert.Equal(t, domain.LoanEventCure, transition.Event)
+}
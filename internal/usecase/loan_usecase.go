@@ -1,155 +1,700 @@
 package usecase
 
 import (
+	"billing-engine/internal/clock"
 	"billing-engine/internal/domain"
 	"billing-engine/internal/utils"
 	"context"
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type LoanUsecase interface {
-	GetOutstanding(ctx context.Context, loanID uint) (float64, error)
+	// GetLoan returns the loan with its borrower, current schedule, outstanding, next installment and arrears.
+	GetLoan(ctx context.Context, loanID uint) (*domain.LoanDetail, error)
+	// GetOutstanding returns what is owed on the loan today, split between installments and late fees.
+	GetOutstanding(ctx context.Context, loanID uint) (*domain.Outstanding, error)
 	IsDelinquent(ctx context.Context, loanID uint) (*domain.CheckDelinquentAmount, error)
-	MakePayment(ctx context.Context, loanID uint, amount float64) error
+	// RefreshDelinquency stores the overdue weeks and late fees of the loan as of asOf and moves it in or out of
+	// the delinquent status, or writes it off once automatic write-offs are due. It reports whether the weeks and
+	// the status changed.
+	RefreshDelinquency(ctx context.Context, loanID uint, asOf time.Time) (updated, changed bool, err error)
+	MakePayment(ctx context.Context, loanID uint, amount domain.Money) (*domain.Payment, error)
+	GetPayments(ctx context.Context, loanID uint) ([]domain.Payment, error)
+	// ReversePayment undoes a payment, what it settled is owed again and the delinquency of the loan is recomputed.
+	ReversePayment(ctx context.Context, loanID, paymentID uint, reason string) (*domain.Payment, error)
+	// GetPayoffQuote quotes the payoff amount as of the given date, a zero asOf quotes as of today.
+	GetPayoffQuote(ctx context.Context, loanID uint, asOf time.Time) (*domain.PayoffQuote, error)
+	PayOff(ctx context.Context, loanID uint, amount domain.Money) (*domain.Payment, error)
+	// TransitionLoan applies a lifecycle event such as approve or disburse to the loan.
+	TransitionLoan(ctx context.Context, loanID uint, event domain.LoanEvent, reason string) (*domain.LoanStatusTransition, error)
+	GetStatusHistory(ctx context.Context, loanID uint) ([]domain.LoanStatusTransition, error)
+	// RestructureLoan reschedules what is left of the loan with new terms, the unpaid installments are superseded
+	// by a new schedule version and kept for audit.
+	RestructureLoan(ctx context.Context, loanID uint, terms LoanRestructuring) (*domain.LoanRestructure, error)
+	// GrantPaymentHoliday defers the upcoming installments of the loan, installments not due yet are never in arrears.
+	GrantPaymentHoliday(ctx context.Context, loanID uint, request PaymentHolidayRequest) (*domain.PaymentHoliday, error)
+	// AccrueInterest earns the interest accrued on the loan up to asOf, it returns nil when there is nothing to earn
+	// or the loan no longer accrues.
+	AccrueInterest(ctx context.Context, loanID uint, asOf time.Time) (*domain.InterestAccrual, error)
+	GetInterestAccruals(ctx context.Context, loanID uint) ([]domain.InterestAccrual, error)
+	// WriteOffLoan writes off what is left of an active or delinquent loan, it owes nothing afterwards and stops
+	// accruing interest, late fees and arrears.
+	WriteOffLoan(ctx context.Context, loanID uint, reason string) (*domain.LoanWriteOff, error)
+	GetWriteOff(ctx context.Context, loanID uint) (*domain.LoanWriteOff, error)
+	// RecordRecovery records money collected on a written-off loan, up to what was written off and not recovered yet.
+	RecordRecovery(ctx context.Context, loanID uint, amount domain.Money) (*domain.Recovery, error)
+	GetRecoveries(ctx context.Context, loanID uint) ([]domain.Recovery, error)
+	// GetJournal returns the ledger entries of the loan with their postings, oldest first.
+	GetJournal(ctx context.Context, loanID uint) ([]domain.JournalEntry, error)
+	// GetSchedule returns every installment of the loan, including the ones superseded by a restructure.
+	GetSchedule(ctx context.Context, loanID uint) ([]domain.BillingSchedule, error)
 	GetLoansWithBorrower(ctx context.Context, limit, offset uint) ([]domain.LoanWithBorrower, error)
-	CreateLoan(ctx context.Context, borrowerID uint, amount float64, interestRate, durationWeeks int) (uint, error)
+	CreateLoan(ctx context.Context, application LoanApplication) (uint, error)
+}
+
+// LoanApplication is a request for a new loan, its pricing and repayment frequency come from the loan product.
+// The schedule starts on DisbursementDate, today when zero. FirstDueDate defaults to one period after it, another
+// date makes the first period longer or shorter and prorates its interest, later installments follow it.
+type LoanApplication struct {
+	BorrowerID       uint
+	ProductID        uint
+	Amount           domain.Money
+	TenorPeriods     int
+	DisbursementDate time.Time
+	FirstDueDate     time.Time
 }
 
 type loanUsecase struct {
-	loanRepo domain.LoanRepository
+	loanRepo     domain.LoanRepository
+	productRepo  domain.LoanProductRepository
+	rounding     domain.RoundingMode
+	allocation   PaymentAllocationPolicy
+	rebatePolicy InterestRebatePolicy
+	lateFee      LateFeePolicy
+	calculators  map[domain.InterestMethod]InterestCalculator
+	remainder    RemainderPolicy
+	clock        clock.Clock
+	calendar     *domain.BusinessCalendar
+	convention   domain.BusinessDayConvention
+	accrual      domain.AccrualMethod
+	writeOffAt   int
+}
+
+// LoanUsecaseOption customises the policies used by the loan usecase.
+type LoanUsecaseOption func(*loanUsecase)
+
+// WithRoundingMode sets how computed amounts such as interest are rounded to cents, half-up by default.
+func WithRoundingMode(mode domain.RoundingMode) LoanUsecaseOption {
+	return func(lu *loanUsecase) {
+		lu.rounding = mode
+	}
+}
+
+// WithPaymentAllocationPolicy sets whether partial payments and overpayments are accepted, both are by default.
+func WithPaymentAllocationPolicy(policy PaymentAllocationPolicy) LoanUsecaseOption {
+	return func(lu *loanUsecase) {
+		lu.allocation = policy
+	}
+}
+
+// WithInterestRebatePolicy sets how unearned interest is rebated on early payoff, pro rata by default.
+func WithInterestRebatePolicy(policy InterestRebatePolicy) LoanUsecaseOption {
+	return func(lu *loanUsecase) {
+		lu.rebatePolicy = policy
+	}
 }
 
-func NewLoanUsecase(lr domain.LoanRepository) LoanUsecase {
-	return &loanUsecase{loanRepo: lr}
+// WithLateFeePolicy sets the fees charged on late installments, none by default.
+func WithLateFeePolicy(policy LateFeePolicy) LoanUsecaseOption {
+	return func(lu *loanUsecase) {
+		lu.lateFee = policy
+	}
+}
+
+// WithRemainderPolicy sets which installment absorbs the cents left over by splitting a loan into installments, the final one by default.
+func WithRemainderPolicy(policy RemainderPolicy) LoanUsecaseOption {
+	return func(lu *loanUsecase) {
+		lu.remainder = policy
+	}
+}
+
+// WithInterestCalculator replaces the calculator used for loans with the given interest method.
+func WithInterestCalculator(method domain.InterestMethod, calculator InterestCalculator) LoanUsecaseOption {
+	return func(lu *loanUsecase) {
+		lu.calculators[method] = calculator
+	}
+}
+
+// WithClock sets the clock that decides which installments are due, the wall clock by default.
+func WithClock(clk clock.Clock) LoanUsecaseOption {
+	return func(lu *loanUsecase) {
+		lu.clock = clk
+	}
 }
 
-func (lu *loanUsecase) GetOutstanding(ctx context.Context, loanID uint) (float64, error) {
+// WithBusinessCalendar moves due dates falling on weekends and holidays of the calendar by the convention,
+// both when a schedule is generated and when installments are checked for arrears. Dates are kept by default.
+func WithBusinessCalendar(calendar *domain.BusinessCalendar, convention domain.BusinessDayConvention) LoanUsecaseOption {
+	return func(lu *loanUsecase) {
+		lu.calendar = calendar
+		lu.convention = convention
+	}
+}
+
+// WithAccrualMethod sets how the interest of loans is earned day by day, straight line by default.
+func WithAccrualMethod(method domain.AccrualMethod) LoanUsecaseOption {
+	return func(lu *loanUsecase) {
+		lu.accrual = method
+	}
+}
+
+// WithAutomaticWriteOff makes RefreshDelinquency write off delinquent loans with at least afterInstallments
+// installments overdue, loans are only written off manually when afterInstallments is 0, the default.
+func WithAutomaticWriteOff(afterInstallments int) LoanUsecaseOption {
+	return func(lu *loanUsecase) {
+		lu.writeOffAt = afterInstallments
+	}
+}
+
+func NewLoanUsecase(lr domain.LoanRepository, pr domain.LoanProductRepository, opts ...LoanUsecaseOption) LoanUsecase {
+	lu := &loanUsecase{
+		loanRepo:     lr,
+		productRepo:  pr,
+		rounding:     domain.RoundHalfUp,
+		allocation:   PaymentAllocationPolicy{AllowPartial: true, AllowOverpayment: true},
+		rebatePolicy: RebateProRata,
+		calculators:  defaultInterestCalculators(),
+		clock:        clock.New(),
+		accrual:      domain.AccrualStraightLine,
+	}
+	for _, opt := range opts {
+		opt(lu)
+	}
+	return lu
+}
+
+func (lu *loanUsecase) GetOutstanding(ctx context.Context, loanID uint) (*domain.Outstanding, error) {
 	loan, err := lu.loanRepo.GetLoanByID(ctx, loanID)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	return lu.outstanding(ctx, loan)
+}
+
+// outstanding returns what is owed on the loan today.
+func (lu *loanUsecase) outstanding(ctx context.Context, loan *domain.Loan) (*domain.Outstanding, error) {
+	// the installments and late fees still unpaid were written off with the loan
+	if loan.Status == domain.LoanStatusWrittenOff {
+		return &domain.Outstanding{}, nil
 	}
-	outstandingFloat, err := utils.NumericToFloat64(loan.Outstanding)
+	schedules, err := lu.loanRepo.GetUnpaidBillingSchedules(ctx, loan.ID)
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("loan usecase: failed to get unpaid billing schedules: %w", err)
 	}
-	return outstandingFloat, nil
+	fees, err := lu.lateFees(ctx, lu.loanRepo, loan, schedules, lu.clock.Now(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	lateFees := unpaidLateFees(fees)
+	return &domain.Outstanding{
+		Installments: loan.Outstanding,
+		Components:   remainingComponents(schedules),
+		LateFees:     lateFees,
+		Total:        loan.Outstanding.Add(lateFees),
+	}, nil
 }
 
 func (lu *loanUsecase) IsDelinquent(ctx context.Context, loanID uint) (*domain.CheckDelinquentAmount, error) {
-	check, err := lu.loanRepo.IsDelinquent(ctx, loanID)
+	loan, err := lu.loanRepo.GetLoanByID(ctx, loanID)
 	if err != nil {
 		return nil, err
 	}
-	check.IsDelinquent = check.TotalWeek >= 2
-	return check, nil
+	return lu.arrears(ctx, loan)
 }
 
-func (lu *loanUsecase) MakePayment(ctx context.Context, loanID uint, amount float64) error {
+// arrears returns what is overdue on the loan today, written off loans owe nothing.
+func (lu *loanUsecase) arrears(ctx context.Context, loan *domain.Loan) (*domain.CheckDelinquentAmount, error) {
+	if loan.Status == domain.LoanStatusWrittenOff {
+		return &domain.CheckDelinquentAmount{LoanID: loan.ID}, nil
+	}
+	return lu.checkDelinquent(ctx, lu.loanRepo, loan, lu.clock.Now(), false)
+}
 
-	loan, err := lu.loanRepo.GetLoanByID(ctx, loanID)
+// checkDelinquent returns the arrears of the loan as of asOf including its unpaid late fees,
+// which are charged when charge is set.
+func (lu *loanUsecase) checkDelinquent(ctx context.Context, repo domain.LoanRepository, loan *domain.Loan, asOf time.Time, charge bool) (*domain.CheckDelinquentAmount, error) {
+	check, err := repo.IsDelinquent(ctx, loan.ID, lu.arrearsCutoff(asOf))
 	if err != nil {
-		return fmt.Errorf("failed to get loan by id %d: %w", loanID, err)
+		return nil, fmt.Errorf("loan usecase: failed to check delinquent amount: %w", err)
 	}
-	// Convert pgtype.Numeric to big.Float for comparison
-	amountFloat := new(big.Float).SetFloat64(amount)
 
-	outstandingFloat, err := utils.NumericToBigFloat(loan.Outstanding)
+	var schedules []domain.BillingSchedule
+	if lu.lateFee.enabled() {
+		schedules, err = repo.GetUnpaidBillingSchedules(ctx, loan.ID)
+		if err != nil {
+			return nil, fmt.Errorf("loan usecase: failed to get unpaid billing schedules: %w", err)
+		}
+	}
+	fees, err := lu.lateFees(ctx, repo, loan, schedules, asOf, charge)
 	if err != nil {
-		return errors.New("NumericToBigFloat outstanding amount")
+		return nil, err
 	}
 
-	if outstandingFloat.Cmp(big.NewFloat(0)) == 0 {
-		return errors.New("loan is already full paid")
+	check.LateFees = unpaidLateFees(fees)
+	check.Amount = check.Amount.Add(check.LateFees)
+	check.IsDelinquent = check.TotalWeek >= domain.DelinquentAfterWeeks
+	return check, nil
+}
+
+func (lu *loanUsecase) RefreshDelinquency(ctx context.Context, loanID uint, asOf time.Time) (updated, changed bool, err error) {
+	err = lu.loanRepo.WithinTransaction(ctx, func(repo domain.LoanRepository) error {
+		loan, err := repo.GetLoanByIDForUpdate(ctx, loanID)
+		if err != nil {
+			return err
+		}
+		// a payment or a manual transition may have closed the loan since it was listed
+		if !loan.Status.AcceptsPayments() {
+			return nil
+		}
+		updated, changed, err = lu.refreshDelinquency(ctx, repo, loan, asOf)
+		if err != nil || lu.writeOffAt <= 0 || loan.Status != domain.LoanStatusDelinquent || loan.DelinquentWeeks < lu.writeOffAt {
+			return err
+		}
+		_, _, err = lu.writeOff(ctx, repo, loan, fmt.Sprintf("%d installments overdue", loan.DelinquentWeeks), true)
+		changed = err == nil
+		return err
+	})
+	if err != nil {
+		return false, false, err
 	}
+	return updated, changed, nil
+}
 
-	installmentAmountFloat, err := utils.NumericToBigFloat(loan.InstallmentAmount)
+// refreshDelinquency stores the overdue weeks and late fees of the loan as of asOf and moves it in or out of
+// the delinquent status, repo must be the unit of work holding the loan row lock.
+func (lu *loanUsecase) refreshDelinquency(ctx context.Context, repo domain.LoanRepository, loan *domain.Loan, asOf time.Time) (updated, changed bool, err error) {
+	check, err := lu.checkDelinquent(ctx, repo, loan, asOf, true)
 	if err != nil {
-		return errors.New("NumericToBigFloat for installment amount")
+		return false, false, err
+	}
+	if check.TotalWeek != loan.DelinquentWeeks {
+		if err := repo.UpdateDelinquentWeeks(ctx, loan.ID, check.TotalWeek); err != nil {
+			return false, false, err
+		}
+		loan.DelinquentWeeks = check.TotalWeek
+		updated = true
+	}
+
+	switch {
+	case check.IsDelinquent && loan.Status == domain.LoanStatusActive:
+		_, err = transitionLoan(ctx, repo, loan, domain.LoanEventMarkDelinquent, fmt.Sprintf("%d installments overdue", check.TotalWeek))
+		changed = err == nil
+	case !check.IsDelinquent && loan.Status == domain.LoanStatusDelinquent:
+		_, err = transitionLoan(ctx, repo, loan, domain.LoanEventCure, "arrears repaid")
+		changed = err == nil
+	}
+	return updated, changed, err
+}
+
+func (lu *loanUsecase) MakePayment(ctx context.Context, loanID uint, amount domain.Money) (*domain.Payment, error) {
+
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", domain.ErrInvalidPayment)
 	}
 
-	checkDelinquentAmount, err := lu.loanRepo.IsDelinquent(ctx, loanID)
+	// the loan row stays locked from the balance check until the payment is written,
+	// so concurrent payments on one loan are applied one after the other
+	var payment *domain.Payment
+	err := lu.loanRepo.WithinTransaction(ctx, func(repo domain.LoanRepository) error {
+		var err error
+		payment, err = lu.makePayment(ctx, repo, loanID, amount)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("loan usecase: failed to check delinquent amount: %w", err)
+		return nil, err
 	}
+	return payment, nil
+}
 
-	if checkDelinquentAmount.IsDelinquent && amount != float64(checkDelinquentAmount.Amount) {
-		return fmt.Errorf("please repay the arrears amount first, for: %.2f", float64(checkDelinquentAmount.Amount))
+func (lu *loanUsecase) makePayment(ctx context.Context, repo domain.LoanRepository, loanID uint, amount domain.Money) (*domain.Payment, error) {
+	loan, err := repo.GetLoanByIDForUpdate(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan by id %d: %w", loanID, err)
+	}
+	if err := ensureAcceptsPayments(loan); err != nil {
+		return nil, err
 	}
 
-	if amountFloat.Cmp(installmentAmountFloat) != 0 && !checkDelinquentAmount.IsDelinquent {
-		return fmt.Errorf("payment amount not equal to installment amount: %.2f", installmentAmountFloat)
+	now := lu.clock.Now()
+	checkDelinquentAmount, err := repo.IsDelinquent(ctx, loanID, lu.arrearsCutoff(now))
+	if err != nil {
+		return nil, fmt.Errorf("loan usecase: failed to check delinquent amount: %w", err)
 	}
 
-	outstandingBalance := new(big.Float).Sub(outstandingFloat, amountFloat)
-	loan.Outstanding, err = utils.BigFloatToNumeric(outstandingBalance)
+	schedules, err := repo.GetUnpaidBillingSchedules(ctx, loanID)
 	if err != nil {
-		return errors.New("BigFloatToNumeric for outstanding balance")
+		return nil, fmt.Errorf("loan usecase: failed to get unpaid billing schedules: %w", err)
+	}
+	if len(schedules) == 0 {
+		return nil, domain.ErrLoanFullyPaid
 	}
 
+	fees, err := lu.lateFees(ctx, repo, loan, schedules, now, true)
+	if err != nil {
+		return nil, err
+	}
+	lateFees := unpaidLateFees(fees)
+	arrears := checkDelinquentAmount.Amount.Add(lateFees)
+
+	// a delinquent borrower owes the arrears, otherwise the oldest unpaid installment is due, late fees come on top of either
+	amountDue := schedules[0].Remaining().Add(lateFees)
 	if checkDelinquentAmount.IsDelinquent {
-		return lu.loanRepo.UpdateRepaymentSchedule(ctx, loan)
+		amountDue = arrears
+	}
+
+	if (amount.Cmp(amountDue) < 0 && !lu.allocation.AllowPartial) || (amount.Cmp(amountDue) > 0 && !lu.allocation.AllowOverpayment) {
+		if checkDelinquentAmount.IsDelinquent {
+			return nil, fmt.Errorf("%w: please repay the arrears amount first, for: %s", domain.ErrInvalidPayment, amountDue)
+		}
+		return nil, fmt.Errorf("%w: payment amount not equal to installment amount: %s", domain.ErrInvalidPayment, amountDue)
 	}
 
-	nearestBillingSchedule, err := lu.loanRepo.GetBillingSchedule(ctx, loanID)
+	if balance := remainingBalance(schedules).Add(lateFees); amount.Cmp(balance) > 0 {
+		return nil, fmt.Errorf("%w: payment amount exceeds the remaining balance: %s", domain.ErrInvalidPayment, balance)
+	}
+
+	// late fees are paid first, only what is left reduces the loan's outstanding
+	feeAllocations, left, err := payLateFees(ctx, repo, fees, amount)
 	if err != nil {
-		return errors.New("failed to get billing schedule")
+		return nil, err
 	}
-	nearestBillingSchedule.Paid = pgtype.Bool{Bool: true, Valid: true}
+	updatedSchedules, allocations := allocatePayment(schedules, left)
+	loan.Outstanding = loan.Outstanding.Sub(left)
 
-	return lu.loanRepo.UpdateLoan(ctx, loan, nearestBillingSchedule)
+	payment := &domain.Payment{LoanID: loanID, Amount: amount, Allocations: append(feeAllocations, allocations...)}
+	if err := repo.UpdateLoan(ctx, loan, updatedSchedules, payment); err != nil {
+		return nil, err
+	}
+	if err := postJournalEntries(ctx, repo, paymentEntries(payment)...); err != nil {
+		return nil, err
+	}
+
+	// allocations settle the oldest periods first, so paying at least the arrears brings the loan up to date
+	switch {
+	case loan.Outstanding.Sign() <= 0:
+		if err := settleInterest(ctx, repo, loan, payment.ID); err != nil {
+			return nil, err
+		}
+		_, err = transitionLoan(ctx, repo, loan, domain.LoanEventClose, "paid in full")
+	case loan.Status == domain.LoanStatusDelinquent && amount.Cmp(arrears) >= 0:
+		_, err = transitionLoan(ctx, repo, loan, domain.LoanEventCure, "arrears repaid")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
 }
 
-func (lu *loanUsecase) GetLoansWithBorrower(ctx context.Context, limit, offset uint) ([]domain.LoanWithBorrower, error) {
-	return lu.loanRepo.GetLoansWithBorrower(ctx, limit, offset)
+func (lu *loanUsecase) GetPayments(ctx context.Context, loanID uint) ([]domain.Payment, error) {
+	if _, err := lu.loanRepo.GetLoanByID(ctx, loanID); err != nil {
+		return nil, err
+	}
+	return lu.loanRepo.GetPaymentsByLoanID(ctx, loanID)
+}
+
+func (lu *loanUsecase) GetPayoffQuote(ctx context.Context, loanID uint, asOf time.Time) (*domain.PayoffQuote, error) {
+	loan, err := lu.loanRepo.GetLoanByID(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if loan.Status == domain.LoanStatusWrittenOff {
+		return nil, fmt.Errorf("%w: loan is %s, record recoveries instead", domain.ErrLoanNotActive, loan.Status)
+	}
+	schedules, err := lu.payoffSchedules(ctx, lu.loanRepo, loan)
+	if err != nil {
+		return nil, err
+	}
+	if asOf.IsZero() {
+		asOf = lu.clock.Now()
+	}
+	fees, err := lu.lateFees(ctx, lu.loanRepo, loan, schedules, asOf, false)
+	if err != nil {
+		return nil, err
+	}
+	return lu.quotePayoff(loan, schedules, fees, asOf)
 }
 
-func (lu *loanUsecase) CreateLoan(ctx context.Context, borrowerID uint, amount float64, interestRate, durationWeeks int) (uint, error) {
+func (lu *loanUsecase) PayOff(ctx context.Context, loanID uint, amount domain.Money) (*domain.Payment, error) {
+	var payment *domain.Payment
+	err := lu.loanRepo.WithinTransaction(ctx, func(repo domain.LoanRepository) error {
+		var err error
+		payment, err = lu.payOff(ctx, repo, loanID, amount)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
 
-	rate := float64(interestRate) / 100
-	interestAmount := amount * rate
-	outstanding := new(big.Float).SetFloat64(amount + interestAmount)
+func (lu *loanUsecase) payOff(ctx context.Context, repo domain.LoanRepository, loanID uint, amount domain.Money) (*domain.Payment, error) {
+	loan, err := repo.GetLoanByIDForUpdate(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := lu.payoffSchedules(ctx, repo, loan)
+	if err != nil {
+		return nil, err
+	}
 
-	outstandingNumeric, err := utils.BigFloatToNumeric(outstanding)
+	now := lu.clock.Now()
+	fees, err := lu.lateFees(ctx, repo, loan, schedules, now, true)
 	if err != nil {
-		return 0, fmt.Errorf("failed to convert outstanding amount: %w", err)
+		return nil, err
+	}
+	quote, err := lu.quotePayoff(loan, schedules, fees, now)
+	if err != nil {
+		return nil, err
+	}
+	if !amount.Equal(quote.PayoffAmount) {
+		return nil, fmt.Errorf("%w: payoff amount must be %s", domain.ErrInvalidPayment, quote.PayoffAmount)
 	}
 
-	amountNumeric, err := utils.BigFloatToNumeric(new(big.Float).SetFloat64(amount))
+	feeAllocations, left, err := payLateFees(ctx, repo, fees, amount)
 	if err != nil {
-		return 0, fmt.Errorf("failed to convert amount: %w", err)
+		return nil, err
+	}
+	updatedSchedules, allocations := settleSchedules(schedules, left, quote.Rebate)
+	loan.Outstanding = domain.Money{}
+
+	payment := &domain.Payment{LoanID: loanID, Amount: amount, Rebate: quote.Rebate, Allocations: append(feeAllocations, allocations...)}
+	if err := repo.UpdateLoan(ctx, loan, updatedSchedules, payment); err != nil {
+		return nil, err
+	}
+	if err := postJournalEntries(ctx, repo, append(paymentEntries(payment), rebateEntry(payment))...); err != nil {
+		return nil, err
+	}
+	if err := settleInterest(ctx, repo, loan, payment.ID); err != nil {
+		return nil, err
 	}
+	if _, err := transitionLoan(ctx, repo, loan, domain.LoanEventClose, "paid off early"); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// payoffSchedules checks the loan can still be paid off and returns its unpaid schedule rows.
+func (lu *loanUsecase) payoffSchedules(ctx context.Context, repo domain.LoanRepository, loan *domain.Loan) ([]domain.BillingSchedule, error) {
+	if err := ensureAcceptsPayments(loan); err != nil {
+		return nil, err
+	}
+
+	schedules, err := repo.GetUnpaidBillingSchedules(ctx, loan.ID)
+	if err != nil {
+		return nil, fmt.Errorf("loan usecase: failed to get unpaid billing schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func (lu *loanUsecase) GetLoansWithBorrower(ctx context.Context, limit, offset uint) ([]domain.LoanWithBorrower, error) {
+	return lu.loanRepo.GetLoansWithBorrower(ctx, limit, offset)
+}
 
-	interestRateNumeric, err := utils.BigFloatToNumeric(new(big.Float).SetFloat64(rate))
+func (lu *loanUsecase) CreateLoan(ctx context.Context, application LoanApplication) (uint, error) {
+
+	if application.BorrowerID == 0 {
+		return 0, fmt.Errorf("%w: borrower_id is required", domain.ErrInvalidLoan)
+	}
+	if application.ProductID == 0 {
+		return 0, fmt.Errorf("%w: product_id is required", domain.ErrInvalidLoan)
+	}
+	product, err := lu.productRepo.GetLoanProductByID(ctx, application.ProductID)
+	if err != nil {
+		if errors.Is(err, domain.ErrLoanProductNotFound) {
+			return 0, fmt.Errorf("%w: %w", domain.ErrInvalidLoan, err)
+		}
+		return 0, err
+	}
+	if err := validateApplication(product, application); err != nil {
+		return 0, err
+	}
+	rate, err := utils.NumericToRat(product.InterestRate)
 	if err != nil {
 		return 0, fmt.Errorf("failed to convert interest rate: %w", err)
 	}
+	originationRate, err := utils.NumericToRat(product.OriginationFeeRate)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert origination fee rate: %w", err)
+	}
 
-	weeklyAmount := utils.CeilBigFloat(new(big.Float).Quo(outstanding, big.NewFloat(float64(durationWeeks))))
-	weeklyAmountNumeric, err := utils.BigFloatToNumeric(weeklyAmount)
+	disbursement := application.DisbursementDate
+	if disbursement.IsZero() {
+		disbursement = lu.clock.Now()
+	}
+	schedule, err := lu.generateSchedule(scheduleRequest{
+		Principal:      application.Amount,
+		Rate:           rate,
+		Method:         product.InterestMethod,
+		Periods:        application.TenorPeriods,
+		Frequency:      product.RepaymentFrequency,
+		Start:          disbursement,
+		FirstDueDate:   application.FirstDueDate,
+		FirstPeriod:    1,
+		InstallmentFee: product.InstallmentFee,
+		UpfrontFee:     application.Amount.Mul(originationRate, lu.rounding),
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to convert weekly amount: %w", err)
+		return 0, err
 	}
 
 	loan := &domain.Loan{
-		Amount:            amountNumeric,
-		InterestRate:      interestRateNumeric,
-		DurationWeeks:     durationWeeks,
-		Outstanding:       outstandingNumeric,
-		InstallmentAmount: weeklyAmountNumeric,
+		Amount:             application.Amount,
+		InterestRate:       product.InterestRate,
+		InterestMethod:     product.InterestMethod,
+		TenorPeriods:       application.TenorPeriods,
+		RepaymentFrequency: product.RepaymentFrequency,
+		Outstanding:        schedule.Total,
+		InstallmentAmount:  schedule.Regular,
+		ProductID:          product.ID,
+		DisbursementDate:   pgtype.Date{Time: disbursement, Valid: true},
 	}
 
-	loanID, err := lu.loanRepo.CreateLoan(ctx, borrowerID, loan)
+	loanID, err := lu.loanRepo.CreateLoan(ctx, application.BorrowerID, loan, schedule.Installments)
 	if err != nil {
+		// deleted borrowers are not found and cannot take new loans
+		if errors.Is(err, domain.ErrBorrowerNotFound) {
+			return 0, fmt.Errorf("%w: %w", domain.ErrInvalidLoan, err)
+		}
 		return 0, err
 	}
 
 	return loanID, nil
 }
 
+// scheduleRequest is what a schedule is generated from, for a new loan or the balance of a restructured one.
+// Installments are numbered from FirstPeriod, each carries the InstallmentFee and the first one the UpfrontFee too.
+type scheduleRequest struct {
+	Principal      domain.Money
+	Rate           *big.Rat
+	Method         domain.InterestMethod
+	Periods        int
+	Frequency      domain.RepaymentFrequency
+	Start          time.Time
+	FirstDueDate   time.Time
+	FirstPeriod    uint
+	InstallmentFee domain.Money
+	UpfrontFee     domain.Money
+}
+
+// generatedSchedule is a schedule ready to be stored, Total is what its installments add up to and Regular the
+// amount of a regular installment.
+type generatedSchedule struct {
+	Installments []domain.BillingSchedule
+	Total        domain.Money
+	Regular      domain.Money
+}
+
+// generateSchedule splits the principal into installments with the calculator of the interest method, prorates
+// a broken first period and moves due dates off non business days.
+func (lu *loanUsecase) generateSchedule(request scheduleRequest) (*generatedSchedule, error) {
+	calculator, err := lu.interestCalculator(request.Method)
+	if err != nil {
+		return nil, err
+	}
+	dueDate, firstPeriod, err := scheduleDates(request.Frequency, request.Start, request.FirstDueDate)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := calculator.Schedule(ScheduleTerms{
+		Principal: request.Principal,
+		Rate:      request.Rate,
+		Periods:   request.Periods,
+		Frequency: request.Frequency,
+		Rounding:  lu.rounding,
+		Remainder: lu.remainder,
+	})
+	prorated := firstPeriod.Cmp(big.NewRat(1, 1)) != 0
+	if prorated {
+		prorateFirstPeriod(&schedule, firstPeriod, lu.rounding)
+	}
+	total := domain.Money{}
+	for i := range schedule.Installments {
+		installment := &schedule.Installments[i]
+		installment.DueDate = pgtype.Date{Time: lu.adjustDueDate(dueDate(int(installment.Period))), Valid: true}
+		installment.Period += request.FirstPeriod - 1
+		installment.Fee = request.InstallmentFee
+		if i == 0 {
+			installment.Fee = installment.Fee.Add(request.UpfrontFee)
+		}
+		installment.Amount = installment.Amount.Add(installment.Fee)
+		total = total.Add(installment.Amount)
+	}
+
+	// the installment amount is the first one not absorbing the rounding remainder, the upfront fee or the
+	// interest of a broken first period, later ones decrease with equal principal repayments
+	regular := schedule.Installments[0]
+	if (lu.remainder == RemainderFirst || request.UpfrontFee.Sign() > 0 || prorated) && len(schedule.Installments) > 1 {
+		regular = schedule.Installments[1]
+	}
+	return &generatedSchedule{Installments: schedule.Installments, Total: total, Regular: regular.Amount}, nil
+}
+
+// scheduleDates returns the due date of every period of a schedule disbursed on disbursement, and the length of
+// the first period relative to a regular one. Without a firstDue the periods are regular.
+func scheduleDates(frequency domain.RepaymentFrequency, disbursement, firstDue time.Time) (func(period int) time.Time, *big.Rat, error) {
+	regularFirst := frequency.DueDate(disbursement, 1)
+	if firstDue.IsZero() || sameDay(firstDue, regularFirst) {
+		return func(period int) time.Time { return frequency.DueDate(disbursement, period) }, big.NewRat(1, 1), nil
+	}
+
+	days := daysBetween(disbursement, firstDue)
+	if days <= 0 {
+		return nil, nil, fmt.Errorf("%w: first due date must be after the disbursement date", domain.ErrInvalidLoan)
+	}
+	if daysBetween(frequency.DueDate(disbursement, 2), firstDue) > 0 {
+		return nil, nil, fmt.Errorf("%w: first due date must be within two periods of the disbursement date", domain.ErrInvalidLoan)
+	}
+	// keep the time of day of the other due dates
+	first := time.Date(firstDue.Year(), firstDue.Month(), firstDue.Day(),
+		disbursement.Hour(), disbursement.Minute(), disbursement.Second(), disbursement.Nanosecond(), disbursement.Location())
+	dueDate := func(period int) time.Time { return frequency.DueDate(first, period-1) }
+	return dueDate, big.NewRat(int64(days), int64(daysBetween(disbursement, regularFirst))), nil
+}
+
+// daysBetween counts the calendar days from the day of from to the day of to.
+func daysBetween(from, to time.Time) int {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDay := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDay.Sub(fromDay).Hours() / 24)
+}
+
+func sameDay(a, b time.Time) bool {
+	return daysBetween(a, b) == 0
+}
+
+// validateApplication checks the requested amount and duration against what the product offers.
+func validateApplication(product *domain.LoanProduct, application LoanApplication) error {
+	if !product.Active {
+		return fmt.Errorf("%w: product %q is not open to new loans", domain.ErrInvalidLoan, product.Name)
+	}
+	if application.Amount.Cmp(product.MinAmount) < 0 || application.Amount.Cmp(product.MaxAmount) > 0 {
+		return fmt.Errorf("%w: amount must be between %s and %s for product %q",
+			domain.ErrInvalidLoan, product.MinAmount, product.MaxAmount, product.Name)
+	}
+	if !product.AllowsTenor(application.TenorPeriods) {
+		return fmt.Errorf("%w: tenor periods must be one of %v for product %q",
+			domain.ErrInvalidLoan, product.TenorPeriods, product.Name)
+	}
+	return nil
+}
+
 func (lu *loanUsecase) UpdateBillingSchedule(ctx context.Context, schedule *domain.BillingSchedule) error {
 	return lu.loanRepo.UpdateBillingSchedule(ctx, schedule)
 }
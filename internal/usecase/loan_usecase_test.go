@@ -1,10 +1,12 @@
 package usecase
 
 import (
+	"billing-engine/internal/clock"
 	"billing-engine/internal/domain"
 	"context"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/assert"
@@ -16,14 +18,25 @@ type MockLoanRepository struct {
 	mock.Mock
 }
 
+// lastCall returns the arguments of the latest call to method.
+func (m *MockLoanRepository) lastCall(method string) mock.Arguments {
+	for i := len(m.Calls) - 1; i >= 0; i-- {
+		if m.Calls[i].Method == method {
+			return m.Calls[i].Arguments
+		}
+	}
+	return nil
+}
+
 // CreateBillingSchedule implements domain.LoanRepository.
 func (m *MockLoanRepository) CreateBillingSchedule(ctx context.Context, schedule *domain.BillingSchedule) error {
 	panic("unimplemented")
 }
 
 // CreateLoan implements domain.LoanRepository.
-func (m *MockLoanRepository) CreateLoan(ctx context.Context, borrowerID uint, loan *domain.Loan) (uint, error) {
-	panic("unimplemented")
+func (m *MockLoanRepository) CreateLoan(ctx context.Context, borrowerID uint, loan *domain.Loan, schedules []domain.BillingSchedule) (uint, error) {
+	args := m.Called(ctx, borrowerID, loan, schedules)
+	return args.Get(0).(uint), args.Error(1)
 }
 
 // GetBillingSchedule implements domain.LoanRepository.
@@ -36,9 +49,16 @@ func (m *MockLoanRepository) GetLoansWithBorrower(ctx context.Context, limit uin
 	panic("unimplemented")
 }
 
+// GetLoansByBorrowerID implements domain.LoanRepository.
+func (m *MockLoanRepository) GetLoansByBorrowerID(ctx context.Context, borrowerID uint) ([]domain.Loan, error) {
+	args := m.Called(ctx, borrowerID)
+	return args.Get(0).([]domain.Loan), args.Error(1)
+}
+
 // IsDelinquent implements domain.LoanRepository.
-func (m *MockLoanRepository) IsDelinquent(ctx context.Context, loanID uint) (*domain.CheckDelinquentAmount, error) {
-	panic("unimplemented")
+func (m *MockLoanRepository) IsDelinquent(ctx context.Context, loanID uint, asOf time.Time) (*domain.CheckDelinquentAmount, error) {
+	args := m.Called(ctx, loanID, asOf)
+	return args.Get(0).(*domain.CheckDelinquentAmount), args.Error(1)
 }
 
 // UpdateBillingSchedule implements domain.LoanRepository.
@@ -46,14 +66,102 @@ func (m *MockLoanRepository) UpdateBillingSchedule(ctx context.Context, schedule
 	panic("unimplemented")
 }
 
-// UpdateLoan implements domain.LoanRepository.
-func (m *MockLoanRepository) UpdateLoan(ctx context.Context, loan *domain.Loan, schedule *domain.BillingSchedule) error {
-	panic("unimplemented")
+func (m *MockLoanRepository) UpdateLoan(ctx context.Context, loan *domain.Loan, schedules []domain.BillingSchedule, payment *domain.Payment) error {
+	args := m.Called(ctx, loan, schedules, payment)
+	return args.Error(0)
 }
 
-// UpdateRepaymentSchedule implements domain.LoanRepository.
-func (m *MockLoanRepository) UpdateRepaymentSchedule(ctx context.Context, loan *domain.Loan) error {
-	panic("unimplemented")
+func (m *MockLoanRepository) ListLoanIDsByStatus(ctx context.Context, statuses []domain.LoanStatus, afterID uint, limit int) ([]uint, error) {
+	args := m.Called(ctx, statuses, afterID, limit)
+	return args.Get(0).([]uint), args.Error(1)
+}
+
+func (m *MockLoanRepository) UpdateDelinquentWeeks(ctx context.Context, loanID uint, weeks int) error {
+	args := m.Called(ctx, loanID, weeks)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) TransitionLoanStatus(ctx context.Context, transition *domain.LoanStatusTransition) error {
+	args := m.Called(ctx, transition)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetLoanStatusHistory(ctx context.Context, loanID uint) ([]domain.LoanStatusTransition, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).([]domain.LoanStatusTransition), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetUnpaidBillingSchedules(ctx context.Context, loanID uint) ([]domain.BillingSchedule, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).([]domain.BillingSchedule), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetBillingSchedules(ctx context.Context, loanID uint) ([]domain.BillingSchedule, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).([]domain.BillingSchedule), args.Error(1)
+}
+
+func (m *MockLoanRepository) RestructureLoan(ctx context.Context, loan *domain.Loan, restructure *domain.LoanRestructure) error {
+	args := m.Called(ctx, loan, restructure)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) DeferBillingSchedules(ctx context.Context, loan *domain.Loan, holiday *domain.PaymentHoliday) error {
+	args := m.Called(ctx, loan, holiday)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetPaymentsByLoanID(ctx context.Context, loanID uint) ([]domain.Payment, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).([]domain.Payment), args.Error(1)
+}
+
+func (m *MockLoanRepository) PostJournalEntry(ctx context.Context, entry *domain.JournalEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetJournalEntries(ctx context.Context, loanID uint) ([]domain.JournalEntry, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).([]domain.JournalEntry), args.Error(1)
+}
+
+func (m *MockLoanRepository) AccrueInterest(ctx context.Context, loan *domain.Loan, schedules []domain.BillingSchedule, accrual *domain.InterestAccrual) error {
+	args := m.Called(ctx, loan, schedules, accrual)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetInterestAccruals(ctx context.Context, loanID uint) ([]domain.InterestAccrual, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).([]domain.InterestAccrual), args.Error(1)
+}
+
+func (m *MockLoanRepository) WriteOffLoan(ctx context.Context, loan *domain.Loan, writeOff *domain.LoanWriteOff) error {
+	args := m.Called(ctx, loan, writeOff)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetLoanWriteOff(ctx context.Context, loanID uint) (*domain.LoanWriteOff, error) {
+	args := m.Called(ctx, loanID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LoanWriteOff), args.Error(1)
+}
+
+func (m *MockLoanRepository) RecordRecovery(ctx context.Context, writeOff *domain.LoanWriteOff, recovery *domain.Recovery) error {
+	args := m.Called(ctx, writeOff, recovery)
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) GetRecoveries(ctx context.Context, loanID uint) ([]domain.Recovery, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).([]domain.Recovery), args.Error(1)
+}
+
+func (m *MockLoanRepository) ReversePayment(ctx context.Context, loan *domain.Loan, schedules []domain.BillingSchedule, payment *domain.Payment) error {
+	args := m.Called(ctx, loan, schedules, payment)
+	return args.Error(0)
 }
 
 func (m *MockLoanRepository) GetLoanByID(ctx context.Context, loanID uint) (*domain.Loan, error) {
@@ -61,26 +169,579 @@ func (m *MockLoanRepository) GetLoanByID(ctx context.Context, loanID uint) (*dom
 	return args.Get(0).(*domain.Loan), args.Error(1)
 }
 
+func (m *MockLoanRepository) GetLoanBorrower(ctx context.Context, loanID uint) (*domain.LoanBorrower, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).(*domain.LoanBorrower), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetLoanByIDForUpdate(ctx context.Context, loanID uint) (*domain.Loan, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).(*domain.Loan), args.Error(1)
+}
+
+func (m *MockLoanRepository) GetLateFees(ctx context.Context, loanID uint) ([]domain.LateFee, error) {
+	args := m.Called(ctx, loanID)
+	return args.Get(0).([]domain.LateFee), args.Error(1)
+}
+
+// CreateLateFee numbers the fee after its week so allocations can be told apart.
+func (m *MockLoanRepository) CreateLateFee(ctx context.Context, fee *domain.LateFee) error {
+	args := m.Called(ctx, fee)
+	fee.ID = 100 + fee.Period
+	return args.Error(0)
+}
+
+func (m *MockLoanRepository) UpdateLateFee(ctx context.Context, fee *domain.LateFee) error {
+	args := m.Called(ctx, fee)
+	return args.Error(0)
+}
+
+// WithinTransaction runs fn against the mock itself, there is no transaction to roll back.
+func (m *MockLoanRepository) WithinTransaction(ctx context.Context, fn func(repo domain.LoanRepository) error) error {
+	return fn(m)
+}
+
 func TestGetOutstanding(t *testing.T) {
 	mockRepo := new(MockLoanRepository)
-	loanUsecase := NewLoanUsecase(mockRepo)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
 	ctx := context.Background()
 	loanID := uint(1)
 
 	// Setting up the expected values
 	expectedLoan := &domain.Loan{
-		Outstanding: pgtype.Numeric{Int: big.NewInt(500), Exp: 0},
+		ID:          loanID,
+		Outstanding: domain.NewMoneyFromUnits(500),
 	}
 	// Assuming the value 500.00 needs to be set
 	// expectedLoan.Outstanding.Set(500.00)
 
 	mockRepo.On("GetLoanByID", ctx, loanID).Return(expectedLoan, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, loanID).Return([]domain.BillingSchedule(nil), nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
 
 	// Running the test
 	result, err := loanUsecase.GetOutstanding(ctx, loanID)
 
 	// Asserting the results
 	assert.NoError(t, err)
-	assert.Equal(t, 500.00, result)
+	assert.Equal(t, "500.00", result.Total.String())
+	assert.True(t, result.LateFees.IsZero())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetPayments(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+	payments := []domain.Payment{{
+		ID:     10,
+		LoanID: loanID,
+		Amount: domain.NewMoneyFromUnits(110000),
+		Allocations: []domain.PaymentAllocation{
+			{BillingScheduleID: 5, Period: 1, Amount: domain.NewMoneyFromUnits(110000)},
+		},
+	}}
+
+	mockRepo.On("GetLoanByID", ctx, loanID).Return(&domain.Loan{ID: loanID}, nil)
+	mockRepo.On("GetPaymentsByLoanID", ctx, loanID).Return(payments, nil)
+
+	result, err := loanUsecase.GetPayments(ctx, loanID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, payments, result)
 	mockRepo.AssertExpectations(t)
 }
+
+func TestGetPaymentsUnknownLoan(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+
+	mockRepo.On("GetLoanByID", ctx, uint(99)).Return((*domain.Loan)(nil), domain.ErrLoanNotFound)
+
+	_, err := loanUsecase.GetPayments(ctx, 99)
+
+	assert.ErrorIs(t, err, domain.ErrLoanNotFound)
+	mockRepo.AssertNotCalled(t, "GetPaymentsByLoanID")
+}
+
+func TestIsDelinquentAsOfClock(t *testing.T) {
+	start := time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC)
+	clk := clock.NewFake(start)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clk))
+	ctx := context.Background()
+	loanID := uint(1)
+
+	// in week 3 the installments due in weeks 1 and 2 are unpaid
+	clk.AdvanceDays(15)
+	weekThree := start.AddDate(0, 0, 15)
+	mockRepo.On("GetLoanByID", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusActive}, nil)
+	mockRepo.On("IsDelinquent", ctx, loanID, weekThree).Return(&domain.CheckDelinquentAmount{LoanID: loanID, TotalWeek: 2, Amount: domain.NewMoneyFromUnits(220000)}, nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
+
+	check, err := loanUsecase.IsDelinquent(ctx, loanID)
+
+	assert.NoError(t, err)
+	assert.True(t, check.IsDelinquent)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateLoan(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	productRepo := new(MockLoanProductRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, productRepo)
+	ctx := context.Background()
+
+	var created *domain.Loan
+	var schedules []domain.BillingSchedule
+	productRepo.On("GetLoanProductByID", ctx, uint(3)).Return(testProduct(), nil)
+	mockRepo.On("CreateLoan", ctx, uint(2), mock.AnythingOfType("*domain.Loan"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			created = args.Get(2).(*domain.Loan)
+			schedules = args.Get(3).([]domain.BillingSchedule)
+		}).
+		Return(uint(42), nil)
+
+	loanID, err := loanUsecase.CreateLoan(ctx, LoanApplication{
+		BorrowerID:   2,
+		ProductID:    3,
+		Amount:       domain.NewMoneyFromUnits(5000000),
+		TenorPeriods: 50,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(42), loanID)
+	assert.Equal(t, uint(3), created.ProductID)
+	assert.Equal(t, domain.InterestFlat, created.InterestMethod)
+	assert.Equal(t, "5500000.00", created.Outstanding.String())
+	assert.Equal(t, "110000.00", created.InstallmentAmount.String())
+	assert.Len(t, schedules, 50)
+	assert.Equal(t, "100000.00", schedules[0].Principal.String())
+	assert.Equal(t, "10000.00", schedules[0].Interest.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateLoanRejectsUnknownBorrower(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	productRepo := new(MockLoanProductRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, productRepo)
+	ctx := context.Background()
+	application := LoanApplication{ProductID: 3, Amount: domain.NewMoneyFromUnits(5000000), TenorPeriods: 50}
+
+	_, err := loanUsecase.CreateLoan(ctx, application)
+	assert.ErrorIs(t, err, domain.ErrInvalidLoan)
+	productRepo.AssertNotCalled(t, "GetLoanProductByID")
+
+	// deleted borrowers are not found either
+	productRepo.On("GetLoanProductByID", ctx, uint(3)).Return(testProduct(), nil)
+	mockRepo.On("CreateLoan", ctx, uint(2), mock.Anything, mock.Anything).Return(uint(0), domain.ErrBorrowerNotFound)
+	application.BorrowerID = 2
+
+	_, err = loanUsecase.CreateLoan(ctx, application)
+	assert.ErrorIs(t, err, domain.ErrInvalidLoan)
+	assert.ErrorIs(t, err, domain.ErrBorrowerNotFound)
+}
+
+func TestCreateLoanChargesProductFees(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	productRepo := new(MockLoanProductRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, productRepo)
+	ctx := context.Background()
+	product := testProduct()
+	product.InstallmentFee = domain.NewMoneyFromUnits(500)
+	// 1.5% of the amount with the first installment
+	product.OriginationFeeRate = pgtype.Numeric{Int: big.NewInt(15), Exp: -3, Valid: true}
+
+	var created *domain.Loan
+	var schedules []domain.BillingSchedule
+	productRepo.On("GetLoanProductByID", ctx, uint(3)).Return(product, nil)
+	mockRepo.On("CreateLoan", ctx, uint(2), mock.AnythingOfType("*domain.Loan"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			created = args.Get(2).(*domain.Loan)
+			schedules = args.Get(3).([]domain.BillingSchedule)
+		}).
+		Return(uint(42), nil)
+
+	_, err := loanUsecase.CreateLoan(ctx, LoanApplication{
+		BorrowerID:   2,
+		ProductID:    3,
+		Amount:       domain.NewMoneyFromUnits(1000000),
+		TenorPeriods: 10,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "1120000.00", created.Outstanding.String())
+	assert.Equal(t, "110500.00", created.InstallmentAmount.String())
+	assert.Equal(t, "15500.00", schedules[0].Fee.String())
+	assert.Equal(t, "125500.00", schedules[0].Amount.String())
+	assert.Equal(t, "500.00", schedules[9].Fee.String())
+	assert.Equal(t, "110500.00", schedules[9].Amount.String())
+}
+
+func TestCreateLoanMonthlySchedule(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	productRepo := new(MockLoanProductRepository)
+	start := time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC)
+	loanUsecase := NewLoanUsecase(mockRepo, productRepo, WithClock(clock.NewFake(start)))
+	ctx := context.Background()
+	product := testProduct()
+	product.RepaymentFrequency = domain.FrequencyMonthly
+	product.InterestMethod = domain.InterestAnnuity
+	product.InterestRate = pgtype.Numeric{Int: big.NewInt(12), Exp: -2, Valid: true}
+	product.TenorPeriods = []int{12}
+
+	var created *domain.Loan
+	var schedules []domain.BillingSchedule
+	productRepo.On("GetLoanProductByID", ctx, uint(3)).Return(product, nil)
+	mockRepo.On("CreateLoan", ctx, uint(2), mock.AnythingOfType("*domain.Loan"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			created = args.Get(2).(*domain.Loan)
+			schedules = args.Get(3).([]domain.BillingSchedule)
+		}).
+		Return(uint(42), nil)
+
+	_, err := loanUsecase.CreateLoan(ctx, LoanApplication{
+		BorrowerID:   2,
+		ProductID:    3,
+		Amount:       domain.NewMoneyFromUnits(1200000),
+		TenorPeriods: 12,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.FrequencyMonthly, created.RepaymentFrequency)
+	assert.Equal(t, 12, created.TenorPeriods)
+	assert.Len(t, schedules, 12)
+	// a twelfth of the yearly rate each month
+	assert.Equal(t, "12000.00", schedules[0].Interest.String())
+	assert.Equal(t, "106618.55", created.InstallmentAmount.String())
+	for i, want := range []string{"2024-02-29", "2024-03-31", "2024-04-30", "2024-05-31"} {
+		assert.Equal(t, uint(i+1), schedules[i].Period)
+		assert.Equal(t, want, schedules[i].DueDate.Time.Format(time.DateOnly))
+	}
+	assert.Equal(t, "2025-01-31", schedules[11].DueDate.Time.Format(time.DateOnly))
+}
+
+func TestCreateLoanWithBrokenFirstPeriod(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	productRepo := new(MockLoanProductRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, productRepo, WithClock(clock.NewFake(time.Date(2024, time.June, 10, 9, 0, 0, 0, time.UTC))))
+	ctx := context.Background()
+	disbursed := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+	var created *domain.Loan
+	var schedules []domain.BillingSchedule
+	productRepo.On("GetLoanProductByID", ctx, uint(3)).Return(testProduct(), nil)
+	mockRepo.On("CreateLoan", ctx, uint(2), mock.AnythingOfType("*domain.Loan"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			created = args.Get(2).(*domain.Loan)
+			schedules = args.Get(3).([]domain.BillingSchedule)
+		}).
+		Return(uint(42), nil)
+
+	// booked retroactively with a first period of 10 days instead of 7
+	_, err := loanUsecase.CreateLoan(ctx, LoanApplication{
+		BorrowerID:       2,
+		ProductID:        3,
+		Amount:           domain.NewMoneyFromUnits(1000000),
+		TenorPeriods:     10,
+		DisbursementDate: disbursed,
+		FirstDueDate:     time.Date(2024, time.May, 11, 0, 0, 0, 0, time.UTC),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-05-01", created.DisbursementDate.Time.Format(time.DateOnly))
+	assert.Equal(t, "2024-05-11", schedules[0].DueDate.Time.Format(time.DateOnly))
+	assert.Equal(t, "2024-05-18", schedules[1].DueDate.Time.Format(time.DateOnly))
+	// 10/7 of a regular week of interest
+	assert.Equal(t, "14285.71", schedules[0].Interest.String())
+	assert.Equal(t, "10000.00", schedules[1].Interest.String())
+	assert.Equal(t, "114285.71", schedules[0].Amount.String())
+	assert.Equal(t, "110000.00", created.InstallmentAmount.String())
+	assert.Equal(t, "1104285.71", created.Outstanding.String())
+}
+
+func TestCreateLoanRejectsInvalidFirstDueDate(t *testing.T) {
+	disbursed := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]time.Time{
+		"on the disbursement date": disbursed,
+		"before the disbursement":  disbursed.AddDate(0, 0, -3),
+		"after two periods":        disbursed.AddDate(0, 0, 15),
+	}
+	for name, firstDue := range tests {
+		t.Run(name, func(t *testing.T) {
+			productRepo := new(MockLoanProductRepository)
+			loanUsecase := NewLoanUsecase(new(MockLoanRepository), productRepo)
+			ctx := context.Background()
+			productRepo.On("GetLoanProductByID", ctx, uint(3)).Return(testProduct(), nil)
+
+			_, err := loanUsecase.CreateLoan(ctx, LoanApplication{
+				BorrowerID:       2,
+				ProductID:        3,
+				Amount:           domain.NewMoneyFromUnits(1000000),
+				TenorPeriods:     10,
+				DisbursementDate: disbursed,
+				FirstDueDate:     firstDue,
+			})
+
+			assert.ErrorIs(t, err, domain.ErrInvalidLoan)
+		})
+	}
+}
+
+func weeklySchedules(loanID uint, weeks int, installment domain.Money) []domain.BillingSchedule {
+	schedules := make([]domain.BillingSchedule, 0, weeks)
+	for week := 1; week <= weeks; week++ {
+		schedules = append(schedules, domain.BillingSchedule{ID: uint(100 + week), LoanID: loanID, Period: uint(week), Amount: installment})
+	}
+	return schedules
+}
+
+func TestMakePaymentPartial(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+	installment := domain.NewMoneyFromUnits(110000)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusActive, Outstanding: domain.NewMoneyFromUnits(330000), InstallmentAmount: installment}, nil)
+	mockRepo.On("IsDelinquent", ctx, loanID, mock.Anything).Return(&domain.CheckDelinquentAmount{LoanID: loanID}, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, loanID).Return(weeklySchedules(loanID, 3, installment), nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("UpdateLoan", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	payment, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(50000))
+
+	assert.NoError(t, err)
+	assert.Len(t, payment.Allocations, 1)
+	assert.Equal(t, uint(1), payment.Allocations[0].Period)
+	assert.Equal(t, "60000.00", payment.Allocations[0].Remaining.String())
+
+	args := mockRepo.lastCall("UpdateLoan")
+	loan := args.Get(1).(*domain.Loan)
+	schedules := args.Get(2).([]domain.BillingSchedule)
+	assert.Equal(t, "280000.00", loan.Outstanding.String())
+	assert.Len(t, schedules, 1)
+	assert.False(t, schedules[0].Paid.Bool)
+	assert.Equal(t, "50000.00", schedules[0].PaidAmount.String())
+}
+
+func TestMakePaymentSettlesFeeInterestThenPrincipal(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+	installment := domain.NewMoneyFromUnits(110000)
+	schedules := weeklySchedules(loanID, 2, installment)
+	for i := range schedules {
+		schedules[i].Principal = domain.NewMoneyFromUnits(100000)
+		schedules[i].Interest = domain.NewMoneyFromUnits(9000)
+		schedules[i].Fee = domain.NewMoneyFromUnits(1000)
+	}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusActive, Outstanding: domain.NewMoneyFromUnits(220000), InstallmentAmount: installment}, nil)
+	mockRepo.On("IsDelinquent", ctx, loanID, mock.Anything).Return(&domain.CheckDelinquentAmount{LoanID: loanID}, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, loanID).Return(schedules, nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("UpdateLoan", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	payment, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(115000))
+
+	assert.NoError(t, err)
+	assert.Len(t, payment.Allocations, 2)
+	assert.Equal(t, "100000.00", payment.Allocations[0].Principal.String())
+	assert.Equal(t, "9000.00", payment.Allocations[0].Interest.String())
+	assert.Equal(t, "1000.00", payment.Allocations[0].Fee.String())
+	assert.True(t, payment.Allocations[1].Principal.IsZero())
+	assert.Equal(t, "4000.00", payment.Allocations[1].Interest.String())
+	assert.Equal(t, "1000.00", payment.Allocations[1].Fee.String())
+}
+
+func TestMakePaymentOverpaymentRollsForward(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+	installment := domain.NewMoneyFromUnits(110000)
+	schedules := weeklySchedules(loanID, 3, installment)
+	schedules[0].PaidAmount = domain.NewMoneyFromUnits(50000)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusActive, Outstanding: domain.NewMoneyFromUnits(280000), InstallmentAmount: installment}, nil)
+	mockRepo.On("IsDelinquent", ctx, loanID, mock.Anything).Return(&domain.CheckDelinquentAmount{LoanID: loanID}, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, loanID).Return(schedules, nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("UpdateLoan", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	payment, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(200000))
+
+	assert.NoError(t, err)
+	assert.Len(t, payment.Allocations, 3)
+	assert.Equal(t, "60000.00", payment.Allocations[0].Amount.String())
+	assert.True(t, payment.Allocations[0].Remaining.IsZero())
+	assert.Equal(t, "110000.00", payment.Allocations[1].Amount.String())
+	assert.Equal(t, "30000.00", payment.Allocations[2].Amount.String())
+	assert.Equal(t, "80000.00", payment.Allocations[2].Remaining.String())
+}
+
+func TestMakePaymentRejectsMoreThanBalance(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loanID := uint(1)
+	installment := domain.NewMoneyFromUnits(110000)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusActive, Outstanding: domain.NewMoneyFromUnits(220000), InstallmentAmount: installment}, nil)
+	mockRepo.On("IsDelinquent", ctx, loanID, mock.Anything).Return(&domain.CheckDelinquentAmount{LoanID: loanID}, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, loanID).Return(weeklySchedules(loanID, 2, installment), nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
+
+	_, err := loanUsecase.MakePayment(ctx, loanID, domain.NewMoneyFromUnits(230000))
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPayment)
+	mockRepo.AssertNotCalled(t, "UpdateLoan")
+}
+
+func TestMakePaymentStrictPolicyRequiresArrears(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithPaymentAllocationPolicy(PaymentAllocationPolicy{}))
+	ctx := context.Background()
+	loanID := uint(1)
+	installment := domain.NewMoneyFromUnits(110000)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, loanID).Return(&domain.Loan{ID: loanID, Status: domain.LoanStatusActive, Outstanding: domain.NewMoneyFromUnits(330000), InstallmentAmount: installment}, nil)
+	mockRepo.On("IsDelinquent", ctx, loanID, mock.Anything).Return(&domain.CheckDelinquentAmount{LoanID: loanID, TotalWeek: 2, Amount: domain.NewMoneyFromUnits(220000), IsDelinquent: true}, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, loanID).Return(weeklySchedules(loanID, 3, installment), nil)
+	mockRepo.On("GetLateFees", ctx, loanID).Return([]domain.LateFee(nil), nil)
+
+	_, err := loanUsecase.MakePayment(ctx, loanID, installment)
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPayment)
+	assert.Contains(t, err.Error(), "220000.00")
+	mockRepo.AssertNotCalled(t, "UpdateLoan")
+}
+
+// payoffFixture is a 1,000,000 loan at 10% flat over 10 weeks with the first 4 weeks paid,
+// leaving weeks 5 and 6 due on or before asOf and weeks 7 to 10 not yet due.
+func payoffFixture(loanID uint, asOf time.Time) (*domain.Loan, []domain.BillingSchedule) {
+	installment := domain.NewMoneyFromUnits(110000)
+	asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	loan := &domain.Loan{
+		ID:                loanID,
+		Amount:            domain.NewMoneyFromUnits(1000000),
+		InterestRate:      pgtype.Numeric{Int: big.NewInt(10), Exp: -2, Valid: true},
+		InterestMethod:    domain.InterestFlat,
+		TenorPeriods:      10,
+		Outstanding:       domain.NewMoneyFromUnits(660000),
+		InstallmentAmount: installment,
+		Status:            domain.LoanStatusActive,
+	}
+	var schedules []domain.BillingSchedule
+	for week := 5; week <= 10; week++ {
+		schedules = append(schedules, domain.BillingSchedule{
+			ID:        uint(100 + week),
+			LoanID:    loanID,
+			Period:    uint(week),
+			Amount:    installment,
+			Principal: domain.NewMoneyFromUnits(100000),
+			Interest:  domain.NewMoneyFromUnits(10000),
+			DueDate:   pgtype.Date{Time: asOf.AddDate(0, 0, 7*(week-6)), Valid: true},
+		})
+	}
+	return loan, schedules
+}
+
+func TestGetPayoffQuote(t *testing.T) {
+	asOf := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		policy InterestRebatePolicy
+		rebate string
+		payoff string
+	}{
+		{RebateProRata, "40000.00", "620000.00"},
+		{RebateRuleOf78, "18181.82", "641818.18"},
+		{RebateNone, "0.00", "660000.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			mockRepo := new(MockLoanRepository)
+			loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithInterestRebatePolicy(tt.policy))
+			ctx := context.Background()
+			loan, schedules := payoffFixture(1, asOf)
+
+			mockRepo.On("GetLoanByID", ctx, uint(1)).Return(loan, nil)
+			mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+			mockRepo.On("GetLateFees", ctx, uint(1)).Return([]domain.LateFee(nil), nil)
+
+			quote, err := loanUsecase.GetPayoffQuote(ctx, 1, asOf)
+
+			assert.NoError(t, err)
+			assert.Equal(t, "40000.00", quote.UnearnedInterest.String())
+			assert.Equal(t, tt.rebate, quote.Rebate.String())
+			assert.Equal(t, tt.payoff, quote.PayoffAmount.String())
+		})
+	}
+}
+
+func TestPayOffSettlesEverySchedule(t *testing.T) {
+	asOf := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(asOf)))
+	ctx := context.Background()
+	loan, schedules := payoffFixture(1, asOf)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("GetLateFees", ctx, uint(1)).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("UpdateLoan", ctx, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return([]domain.BillingSchedule(nil), nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+
+	payment, err := loanUsecase.PayOff(ctx, 1, domain.NewMoneyFromUnits(620000))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "40000.00", payment.Rebate.String())
+	assert.Len(t, payment.Allocations, 6)
+	// weeks 5 and 6 are due and paid in full, the interest of weeks 7 to 10 is waived rather than paid
+	for i, allocation := range payment.Allocations {
+		interest, waived := "10000.00", "0.00"
+		if i >= 2 {
+			interest, waived = "0.00", "10000.00"
+		}
+		assert.Equal(t, "100000.00", allocation.Principal.String())
+		assert.Equal(t, interest, allocation.Interest.String())
+		assert.Equal(t, waived, allocation.InterestWaived.String())
+	}
+
+	args := mockRepo.lastCall("UpdateLoan")
+	assert.True(t, args.Get(1).(*domain.Loan).Outstanding.IsZero())
+	for _, schedule := range args.Get(2).([]domain.BillingSchedule) {
+		assert.True(t, schedule.Paid.Bool)
+		assert.True(t, schedule.Remaining().IsZero())
+		assert.Equal(t, "100000.00", schedule.PaidComponents().Principal.String())
+	}
+	transition := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(*domain.LoanStatusTransition)
+	assert.Equal(t, domain.LoanStatusPaidOff, transition.To)
+	assert.Equal(t, domain.LoanStatusPaidOff, loan.Status)
+}
+
+func TestPayOffRejectsWrongAmount(t *testing.T) {
+	asOf := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(asOf)))
+	ctx := context.Background()
+	loan, schedules := payoffFixture(1, asOf)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("GetLateFees", ctx, uint(1)).Return([]domain.LateFee(nil), nil)
+
+	_, err := loanUsecase.PayOff(ctx, 1, domain.NewMoneyFromUnits(600000))
+
+	assert.ErrorIs(t, err, domain.ErrInvalidPayment)
+	mockRepo.AssertNotCalled(t, "UpdateLoan")
+}
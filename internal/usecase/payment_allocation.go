@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PaymentAllocationPolicy decides which payment amounts MakePayment accepts besides the exact amount due.
+type PaymentAllocationPolicy struct {
+	// AllowPartial accepts less than the amount due, leaving the oldest unpaid period partially settled.
+	AllowPartial bool
+	// AllowOverpayment accepts more than the amount due, the excess rolls forward to the next unpaid periods.
+	AllowOverpayment bool
+}
+
+// allocatePayment applies amount to the unpaid schedules, oldest period first.
+// It returns the schedules that received money and how much each of them got.
+func allocatePayment(schedules []domain.BillingSchedule, amount domain.Money) ([]domain.BillingSchedule, []domain.PaymentAllocation) {
+	var updated []domain.BillingSchedule
+	var allocations []domain.PaymentAllocation
+
+	left := amount
+	for _, schedule := range schedules {
+		if left.Sign() <= 0 {
+			break
+		}
+		remaining := schedule.Remaining()
+		if remaining.Sign() <= 0 {
+			continue
+		}
+
+		applied := remaining
+		if left.Cmp(remaining) < 0 {
+			applied = left
+		}
+		left = left.Sub(applied)
+
+		schedule, allocation := applyToSchedule(schedule, applied, domain.Money{})
+		schedule.Paid = pgtype.Bool{Bool: schedule.Remaining().Sign() <= 0, Valid: true}
+		allocation.Remaining = schedule.Remaining()
+		updated = append(updated, schedule)
+		allocations = append(allocations, allocation)
+	}
+	return updated, allocations
+}
+
+// applyToSchedule adds applied to what was paid on the schedule and waived to its waived interest, the allocation
+// records which of its fee, interest and principal the money settled.
+func applyToSchedule(schedule domain.BillingSchedule, applied, waived domain.Money) (domain.BillingSchedule, domain.PaymentAllocation) {
+	before := schedule.PaidComponents()
+	schedule.PaidAmount = schedule.PaidAmount.Add(applied)
+	schedule.WaivedInterest = schedule.WaivedInterest.Add(waived)
+	settled := schedule.PaidComponents().Sub(before)
+	return schedule, domain.PaymentAllocation{
+		BillingScheduleID: schedule.ID,
+		Period:            schedule.Period,
+		Amount:            applied,
+		Principal:         settled.Principal,
+		Interest:          settled.Interest,
+		Fee:               settled.Fee,
+		InterestWaived:    waived,
+	}
+}
+
+// remainingBalance sums what is still owed on the given schedules.
+func remainingBalance(schedules []domain.BillingSchedule) domain.Money {
+	total := domain.Money{}
+	for _, schedule := range schedules {
+		total = total.Add(schedule.Remaining())
+	}
+	return total
+}
+
+// remainingComponents breaks down what is still owed on the given schedules.
+func remainingComponents(schedules []domain.BillingSchedule) domain.Components {
+	total := domain.Components{}
+	for _, schedule := range schedules {
+		total = total.Add(schedule.RemainingComponents())
+	}
+	return total
+}
+
+// settleSchedules settles every schedule for good. The rebate waives the interest still owed on them, latest
+// period first since its interest is the furthest from being earned, and amount pays what is left of them.
+func settleSchedules(schedules []domain.BillingSchedule, amount, rebate domain.Money) ([]domain.BillingSchedule, []domain.PaymentAllocation) {
+	updated := make([]domain.BillingSchedule, 0, len(schedules))
+	allocations := make([]domain.PaymentAllocation, 0, len(schedules))
+
+	waived := make([]domain.Money, len(schedules))
+	for i := len(schedules) - 1; i >= 0 && rebate.Sign() > 0; i-- {
+		waived[i] = schedules[i].RemainingComponents().Interest
+		if rebate.Cmp(waived[i]) < 0 {
+			waived[i] = rebate
+		}
+		rebate = rebate.Sub(waived[i])
+	}
+
+	left := amount
+	for i, schedule := range schedules {
+		applied := schedule.Remaining().Sub(waived[i])
+		if left.Cmp(applied) < 0 {
+			applied = left
+		}
+		left = left.Sub(applied)
+
+		schedule, allocation := applyToSchedule(schedule, applied, waived[i])
+		schedule.Paid = pgtype.Bool{Bool: true, Valid: true}
+		updated = append(updated, schedule)
+		allocations = append(allocations, allocation)
+	}
+	return updated, allocations
+}
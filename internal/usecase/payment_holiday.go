@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/internal/utils"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PaymentHolidayRequest defers the next Periods installments not due yet, and every later one, by Periods periods.
+// With CapitaliseInterest the unpaid principal is charged interest for the holiday, spread over the deferred
+// installments, otherwise the holiday is interest free.
+type PaymentHolidayRequest struct {
+	Periods            int
+	CapitaliseInterest bool
+	Reason             string
+}
+
+func (lu *loanUsecase) GrantPaymentHoliday(ctx context.Context, loanID uint, request PaymentHolidayRequest) (*domain.PaymentHoliday, error) {
+	if request.Periods <= 0 {
+		return nil, fmt.Errorf("%w: periods must be positive", domain.ErrInvalidLoan)
+	}
+
+	var holiday *domain.PaymentHoliday
+	err := lu.loanRepo.WithinTransaction(ctx, func(repo domain.LoanRepository) error {
+		var err error
+		holiday, err = lu.grantPaymentHoliday(ctx, repo, loanID, request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return holiday, nil
+}
+
+func (lu *loanUsecase) grantPaymentHoliday(ctx context.Context, repo domain.LoanRepository, loanID uint, request PaymentHolidayRequest) (*domain.PaymentHoliday, error) {
+	loan, err := repo.GetLoanByIDForUpdate(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureAcceptsPayments(loan); err != nil {
+		return nil, err
+	}
+	schedules, err := repo.GetUnpaidBillingSchedules(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("loan usecase: failed to get unpaid billing schedules: %w", err)
+	}
+
+	// installments already due stay in the arrears, the holiday starts with the first one that is not
+	now := lu.clock.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	first := len(schedules)
+	for i, schedule := range schedules {
+		if schedule.DueDate.Time.After(today) {
+			first = i
+			break
+		}
+	}
+	if first == len(schedules) {
+		return nil, fmt.Errorf("%w: no upcoming installment to defer", domain.ErrInvalidLoan)
+	}
+	deferred := append([]domain.BillingSchedule(nil), schedules[first:]...)
+
+	capitalised := domain.Money{}
+	if request.CapitaliseInterest {
+		capitalised, err = lu.holidayInterest(loan, remainingComponents(schedules).Principal, request.Periods)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// an installment takes the due date of the one periods later, so the dates keep following the schedule,
+	// the last ones continue it past its end
+	last := deferred[len(deferred)-1].DueDate.Time
+	dueDates := make([]pgtype.Date, len(deferred))
+	for i := range deferred {
+		if j := i + request.Periods; j < len(deferred) {
+			dueDates[i] = deferred[j].DueDate
+			continue
+		}
+		dueDate := loan.RepaymentFrequency.DueDate(last, i+request.Periods-len(deferred)+1)
+		dueDates[i] = pgtype.Date{Time: lu.adjustDueDate(dueDate), Valid: true}
+	}
+
+	parts := splitEvenly(capitalised, len(deferred), ScheduleTerms{Periods: len(deferred), Remainder: lu.remainder}.remainderPeriod())
+	for i := range deferred {
+		installment := &deferred[i]
+		installment.DueDate = dueDates[i]
+		installment.DeferredPeriods += request.Periods
+		installment.Interest = installment.Interest.Add(parts[i])
+		installment.Amount = installment.Amount.Add(parts[i])
+	}
+
+	holiday := &domain.PaymentHoliday{
+		LoanID:              loan.ID,
+		FromPeriod:          deferred[0].Period,
+		Periods:             request.Periods,
+		StartDate:           schedules[first].DueDate,
+		EndDate:             deferred[0].DueDate,
+		CapitalisedInterest: capitalised,
+		Reason:              request.Reason,
+		Schedule:            deferred,
+	}
+	loan.Outstanding = loan.Outstanding.Add(capitalised)
+	if err := repo.DeferBillingSchedules(ctx, loan, holiday); err != nil {
+		return nil, err
+	}
+	if err := postJournalEntries(ctx, repo, paymentHolidayEntry(holiday)); err != nil {
+		return nil, err
+	}
+	return holiday, nil
+}
+
+// holidayInterest is the interest principal earns over periods periods of the loan: the rate split over the tenor
+// with flat interest, the yearly rate split over the periods of a year with the declining balance methods.
+func (lu *loanUsecase) holidayInterest(loan *domain.Loan, principal domain.Money, periods int) (domain.Money, error) {
+	rate, err := utils.NumericToRat(loan.InterestRate)
+	if err != nil {
+		return domain.Money{}, fmt.Errorf("failed to convert interest rate: %w", err)
+	}
+	perPeriod := ScheduleTerms{Rate: rate, Frequency: loan.RepaymentFrequency}.periodicRate()
+	if loan.InterestMethod == domain.InterestFlat {
+		if loan.TenorPeriods <= 0 {
+			return domain.Money{}, nil
+		}
+		perPeriod = new(big.Rat).Quo(rate, big.NewRat(int64(loan.TenorPeriods), 1))
+	}
+	return principal.Mul(perPeriod.Mul(perPeriod, big.NewRat(int64(periods), 1)), lu.rounding), nil
+}
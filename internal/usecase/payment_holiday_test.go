@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGrantPaymentHolidayCapitalisesInterest(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+	// weeks 5 and 6 are due, weeks 7 to 10 are deferred
+	loan, schedules := payoffFixture(1, now)
+	today := schedules[1].DueDate.Time
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("DeferBillingSchedules", ctx, loan, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	holiday, err := loanUsecase.GrantPaymentHoliday(ctx, 1, PaymentHolidayRequest{Periods: 2, CapitaliseInterest: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), holiday.FromPeriod)
+	assert.Equal(t, today.AddDate(0, 0, 7), holiday.StartDate.Time)
+	assert.Equal(t, today.AddDate(0, 0, 21), holiday.EndDate.Time)
+	// two weeks of the flat 10% over 10 weeks on the unpaid principal of 600000
+	assert.Equal(t, "12000.00", holiday.CapitalisedInterest.String())
+	assert.Len(t, holiday.Schedule, 4)
+	for i, installment := range holiday.Schedule {
+		assert.Equal(t, uint(7+i), installment.Period)
+		assert.Equal(t, today.AddDate(0, 0, 7*(i+3)), installment.DueDate.Time)
+		assert.Equal(t, 2, installment.DeferredPeriods)
+		assert.Equal(t, "13000.00", installment.Interest.String())
+		assert.Equal(t, "113000.00", installment.Amount.String())
+	}
+	assert.Equal(t, "672000.00", loan.Outstanding.String())
+}
+
+func TestGrantPaymentHolidayWithoutInterest(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+	loan, schedules := payoffFixture(1, now)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("DeferBillingSchedules", ctx, loan, mock.Anything).Return(nil)
+
+	holiday, err := loanUsecase.GrantPaymentHoliday(ctx, 1, PaymentHolidayRequest{Periods: 1})
+
+	assert.NoError(t, err)
+	assert.True(t, holiday.CapitalisedInterest.IsZero())
+	for _, installment := range holiday.Schedule {
+		assert.Equal(t, "110000.00", installment.Amount.String())
+	}
+	assert.Equal(t, "660000.00", loan.Outstanding.String())
+	mockRepo.AssertNotCalled(t, "PostJournalEntry", mock.Anything, mock.Anything)
+}
+
+func TestGrantPaymentHolidayNeedsAnUpcomingInstallment(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+	loan, schedules := payoffFixture(1, now)
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules[:2], nil)
+
+	_, err := loanUsecase.GrantPaymentHoliday(ctx, 1, PaymentHolidayRequest{Periods: 1})
+
+	assert.ErrorIs(t, err, domain.ErrInvalidLoan)
+	mockRepo.AssertNotCalled(t, "DeferBillingSchedules", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func (lu *loanUsecase) ReversePayment(ctx context.Context, loanID, paymentID uint, reason string) (*domain.Payment, error) {
+	if strings.TrimSpace(reason) == "" {
+		return nil, fmt.Errorf("%w: a reason is required to reverse a payment", domain.ErrInvalidPayment)
+	}
+
+	var payment *domain.Payment
+	err := lu.loanRepo.WithinTransaction(ctx, func(repo domain.LoanRepository) error {
+		var err error
+		payment, err = lu.reversePayment(ctx, repo, loanID, paymentID, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+func (lu *loanUsecase) reversePayment(ctx context.Context, repo domain.LoanRepository, loanID, paymentID uint, reason string) (*domain.Payment, error) {
+	loan, err := repo.GetLoanByIDForUpdate(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	// a paid off loan is reopened by reversing the payment that closed it, other closed loans stay closed
+	if !loan.Status.AcceptsPayments() && loan.Status != domain.LoanStatusPaidOff {
+		return nil, fmt.Errorf("%w: loan is %s", domain.ErrLoanNotActive, loan.Status)
+	}
+
+	payments, err := repo.GetPaymentsByLoanID(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	var payment *domain.Payment
+	for i := range payments {
+		if payments[i].ID == paymentID {
+			payment = &payments[i]
+			break
+		}
+	}
+	if payment == nil {
+		return nil, fmt.Errorf("%w: payment %d of loan %d", domain.ErrPaymentNotFound, paymentID, loanID)
+	}
+	if payment.ReversedAt.Valid {
+		return nil, fmt.Errorf("%w: payment %d", domain.ErrPaymentAlreadyReversed, paymentID)
+	}
+
+	schedules, err := repo.GetBillingSchedules(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	restored, restoredFees, err := reverseAllocations(schedules, payment)
+	if err != nil {
+		return nil, err
+	}
+	if len(restoredFees) > 0 {
+		if err := restoreLateFees(ctx, repo, loanID, restoredFees); err != nil {
+			return nil, err
+		}
+	}
+
+	// late fees are not part of the outstanding, the rebate waived by a payoff is owed again
+	loan.Outstanding = loan.Outstanding.Add(payment.Rebate)
+	for _, allocation := range payment.Allocations {
+		if allocation.LateFeeID == 0 {
+			loan.Outstanding = loan.Outstanding.Add(allocation.Amount)
+		}
+	}
+
+	payment.ReversalReason = reason
+	if err := repo.ReversePayment(ctx, loan, restored, payment); err != nil {
+		return nil, err
+	}
+	entries, err := repo.GetJournalEntries(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if err := postJournalEntries(ctx, repo, reversalEntries(entries, payment)...); err != nil {
+		return nil, err
+	}
+	if loan.Status == domain.LoanStatusPaidOff {
+		if _, err := transitionLoan(ctx, repo, loan, domain.LoanEventReopen, fmt.Sprintf("payment %d reversed: %s", payment.ID, reason)); err != nil {
+			return nil, err
+		}
+	}
+	if _, _, err := lu.refreshDelinquency(ctx, repo, loan, lu.clock.Now()); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// reverseAllocations returns the schedule rows settled by payment with its allocations taken back, and what it
+// paid on each late fee. Payments made before a restructure cannot be reversed, the balance they left was
+// rescheduled by a new schedule version.
+func reverseAllocations(schedules []domain.BillingSchedule, payment *domain.Payment) ([]domain.BillingSchedule, map[uint]domain.Money, error) {
+	latest := 0
+	byID := make(map[uint]domain.BillingSchedule, len(schedules))
+	for _, schedule := range schedules {
+		byID[schedule.ID] = schedule
+		if !schedule.SupersededAt.Valid && schedule.Version > latest {
+			latest = schedule.Version
+		}
+	}
+
+	restored := make([]domain.BillingSchedule, 0, len(payment.Allocations))
+	fees := make(map[uint]domain.Money)
+	for _, allocation := range payment.Allocations {
+		if allocation.LateFeeID != 0 {
+			fees[allocation.LateFeeID] = fees[allocation.LateFeeID].Add(allocation.Amount)
+			continue
+		}
+		schedule, ok := byID[allocation.BillingScheduleID]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: period %d of payment %d not found", domain.ErrInvalidPayment, allocation.Period, payment.ID)
+		}
+		if schedule.SupersededAt.Valid || schedule.Version < latest {
+			return nil, nil, fmt.Errorf("%w: payment %d was made before the loan was restructured", domain.ErrInvalidPayment, payment.ID)
+		}
+		schedule.PaidAmount = schedule.PaidAmount.Sub(allocation.Amount)
+		schedule.WaivedInterest = schedule.WaivedInterest.Sub(allocation.InterestWaived)
+		schedule.Paid = pgtype.Bool{Bool: schedule.Remaining().Sign() <= 0, Valid: true}
+		restored = append(restored, schedule)
+	}
+	return restored, fees, nil
+}
+
+// restoreLateFees takes the amounts paid by a reversed payment back from the late fees of the loan.
+func restoreLateFees(ctx context.Context, repo domain.LoanRepository, loanID uint, paid map[uint]domain.Money) error {
+	fees, err := repo.GetLateFees(ctx, loanID)
+	if err != nil {
+		return fmt.Errorf("loan usecase: failed to get late fees: %w", err)
+	}
+	for i := range fees {
+		amount, ok := paid[fees[i].ID]
+		if !ok {
+			continue
+		}
+		fees[i].PaidAmount = fees[i].PaidAmount.Sub(amount)
+		if err := repo.UpdateLateFee(ctx, &fees[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
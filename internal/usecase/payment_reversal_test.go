@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReversePayment(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+	// the payment settled the late fee and week 5 and paid part of week 6
+	loan, schedules := payoffFixture(1, now)
+	loan.Outstanding = domain.NewMoneyFromUnits(500000)
+	schedules[0].PaidAmount = domain.NewMoneyFromUnits(110000)
+	schedules[0].Paid = pgtype.Bool{Bool: true, Valid: true}
+	schedules[1].PaidAmount = domain.NewMoneyFromUnits(50000)
+	fees := []domain.LateFee{{ID: 3, LoanID: 1, BillingScheduleID: 105, Period: 5, Amount: domain.NewMoneyFromUnits(5000), PaidAmount: domain.NewMoneyFromUnits(5000)}}
+	payment := domain.Payment{ID: 7, LoanID: 1, Amount: domain.NewMoneyFromUnits(165000), Allocations: []domain.PaymentAllocation{
+		{BillingScheduleID: 105, Period: 5, LateFeeID: 3, Amount: domain.NewMoneyFromUnits(5000), Fee: domain.NewMoneyFromUnits(5000)},
+		{BillingScheduleID: 105, Period: 5, Amount: domain.NewMoneyFromUnits(110000)},
+		{BillingScheduleID: 106, Period: 6, Amount: domain.NewMoneyFromUnits(50000), Remaining: domain.NewMoneyFromUnits(60000)},
+	}}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetPaymentsByLoanID", ctx, uint(1)).Return([]domain.Payment{payment}, nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("GetLateFees", ctx, uint(1)).Return(fees, nil)
+	mockRepo.On("UpdateLateFee", ctx, mock.MatchedBy(func(fee *domain.LateFee) bool {
+		return fee.ID == 3 && fee.PaidAmount.IsZero()
+	})).Return(nil)
+	mockRepo.On("ReversePayment", ctx, loan, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+	mockRepo.On("GetJournalEntries", ctx, mock.Anything).Return([]domain.JournalEntry(nil), nil)
+	mockRepo.On("IsDelinquent", ctx, uint(1), now).Return(&domain.CheckDelinquentAmount{LoanID: 1, TotalWeek: 2, Amount: domain.NewMoneyFromUnits(220000)}, nil)
+	mockRepo.On("UpdateDelinquentWeeks", ctx, uint(1), 2).Return(nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+
+	reversed, err := loanUsecase.ReversePayment(ctx, 1, 7, "cheque bounced")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "cheque bounced", reversed.ReversalReason)
+	assert.Equal(t, "660000.00", loan.Outstanding.String())
+	assert.Equal(t, domain.LoanStatusDelinquent, loan.Status)
+	assert.Equal(t, 2, loan.DelinquentWeeks)
+
+	restored := mockRepo.lastCall("ReversePayment").Get(2).([]domain.BillingSchedule)
+	assert.Len(t, restored, 2)
+	for _, schedule := range restored {
+		assert.True(t, schedule.PaidAmount.IsZero())
+		assert.False(t, schedule.Paid.Bool)
+	}
+	mockRepo.AssertCalled(t, "UpdateLateFee", ctx, mock.Anything)
+}
+
+func TestReversePaymentReopensPaidOffLoan(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+	// paid off pro rata: the interest of weeks 7 to 10 was rebated
+	loan, schedules := payoffFixture(1, now)
+	loan.Status = domain.LoanStatusPaidOff
+	loan.Outstanding = domain.Money{}
+	payment := domain.Payment{ID: 8, LoanID: 1, Amount: domain.NewMoneyFromUnits(620000), Rebate: domain.NewMoneyFromUnits(40000)}
+	for i := range schedules {
+		allocation := domain.PaymentAllocation{BillingScheduleID: schedules[i].ID, Period: schedules[i].Period, Amount: domain.NewMoneyFromUnits(110000), Principal: domain.NewMoneyFromUnits(100000), Interest: domain.NewMoneyFromUnits(10000)}
+		if i >= 2 {
+			allocation.Amount, allocation.Interest, allocation.InterestWaived = domain.NewMoneyFromUnits(100000), domain.Money{}, domain.NewMoneyFromUnits(10000)
+		}
+		schedules[i].PaidAmount = allocation.Amount
+		schedules[i].WaivedInterest = allocation.InterestWaived
+		schedules[i].Paid = pgtype.Bool{Bool: true, Valid: true}
+		payment.Allocations = append(payment.Allocations, allocation)
+	}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetPaymentsByLoanID", ctx, uint(1)).Return([]domain.Payment{payment}, nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("ReversePayment", ctx, loan, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+	mockRepo.On("GetJournalEntries", ctx, mock.Anything).Return([]domain.JournalEntry(nil), nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+	mockRepo.On("IsDelinquent", ctx, uint(1), now).Return(&domain.CheckDelinquentAmount{LoanID: 1, TotalWeek: 1, Amount: domain.NewMoneyFromUnits(110000)}, nil)
+	mockRepo.On("GetLateFees", ctx, uint(1)).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("UpdateDelinquentWeeks", ctx, uint(1), 1).Return(nil)
+
+	_, err := loanUsecase.ReversePayment(ctx, 1, 8, "payment recalled by the bank")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "660000.00", loan.Outstanding.String())
+	for _, schedule := range mockRepo.lastCall("ReversePayment").Get(2).([]domain.BillingSchedule) {
+		assert.False(t, schedule.Paid.Bool)
+		assert.Equal(t, "110000.00", schedule.Remaining().String())
+	}
+	assert.Equal(t, domain.LoanStatusActive, loan.Status)
+	mockRepo.AssertNumberOfCalls(t, "TransitionLoanStatus", 1)
+	transition := mockRepo.lastCall("TransitionLoanStatus").Get(1).(*domain.LoanStatusTransition)
+	assert.Equal(t, domain.LoanEventReopen, transition.Event)
+	assert.Equal(t, "payment 8 reversed: payment recalled by the bank", transition.Reason)
+}
+
+func TestReversePaymentRejects(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	reversedAt := pgtype.Timestamp{Time: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(&domain.Loan{ID: 1, Status: domain.LoanStatusActive}, nil)
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(2)).Return(&domain.Loan{ID: 2, Status: domain.LoanStatusWrittenOff}, nil)
+	mockRepo.On("GetPaymentsByLoanID", ctx, uint(1)).Return([]domain.Payment{{ID: 7, LoanID: 1, ReversedAt: reversedAt}}, nil)
+
+	_, err := loanUsecase.ReversePayment(ctx, 1, 7, " ")
+	assert.ErrorIs(t, err, domain.ErrInvalidPayment)
+
+	_, err = loanUsecase.ReversePayment(ctx, 1, 7, "duplicate")
+	assert.ErrorIs(t, err, domain.ErrPaymentAlreadyReversed)
+
+	_, err = loanUsecase.ReversePayment(ctx, 1, 9, "duplicate")
+	assert.ErrorIs(t, err, domain.ErrPaymentNotFound)
+
+	_, err = loanUsecase.ReversePayment(ctx, 2, 7, "duplicate")
+	assert.ErrorIs(t, err, domain.ErrLoanNotActive)
+	mockRepo.AssertNotCalled(t, "ReversePayment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
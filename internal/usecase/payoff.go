@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/internal/utils"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// InterestRebatePolicy decides how much of the unearned interest is waived when a loan is paid off early.
+type InterestRebatePolicy int
+
+const (
+	// RebateNone charges the full outstanding balance.
+	RebateNone InterestRebatePolicy = iota
+	// RebateProRata waives the interest carried by every installment not yet due.
+	RebateProRata
+	// RebateRuleOf78 waives interest with the sum-of-the-digits method, which front-loads earned interest.
+	RebateRuleOf78
+)
+
+func ParseInterestRebatePolicy(s string) (InterestRebatePolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "pro_rata":
+		return RebateProRata, nil
+	case "none":
+		return RebateNone, nil
+	case "rule_of_78":
+		return RebateRuleOf78, nil
+	}
+	return RebateProRata, fmt.Errorf("unknown interest rebate policy %q", s)
+}
+
+func (p InterestRebatePolicy) String() string {
+	switch p {
+	case RebateNone:
+		return "none"
+	case RebateRuleOf78:
+		return "rule_of_78"
+	}
+	return "pro_rata"
+}
+
+// quotePayoff computes what settles the loan on asOf, schedules are the loan's unpaid rows and fees its unpaid late fees.
+// Late fees are never rebated.
+func (lu *loanUsecase) quotePayoff(loan *domain.Loan, schedules []domain.BillingSchedule, fees []domain.LateFee, asOf time.Time) (*domain.PayoffQuote, error) {
+	rate, err := utils.NumericToRat(loan.InterestRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert interest rate: %w", err)
+	}
+
+	asOfDate := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	// the interest still owed on installments not yet due, a partial payment settles the interest of its period first
+	unearned := domain.Money{}
+	futurePeriods := 0
+	for _, schedule := range schedules {
+		if !schedule.DueDate.Time.After(asOfDate) {
+			continue
+		}
+		unearned = unearned.Add(schedule.RemainingComponents().Interest)
+		futurePeriods++
+	}
+
+	rebate := domain.Money{}
+	switch lu.rebatePolicy {
+	case RebateProRata:
+		rebate = unearned
+	case RebateRuleOf78:
+		// declining balance interest is already earned period by period, only flat interest is front-loaded,
+		// a restructured schedule no longer charges the interest of the original terms and is rebated pro rata
+		rebate = unearned
+		restructured := len(schedules) > 0 && schedules[0].Version > 1
+		if n := int64(loan.TenorPeriods); n > 0 && loan.InterestMethod == domain.InterestFlat && !restructured {
+			k := int64(futurePeriods)
+			totalInterest := loan.Amount.Mul(rate, lu.rounding)
+			rebate = totalInterest.Mul(big.NewRat(k*(k+1), n*(n+1)), lu.rounding)
+		}
+	}
+	if rebate.Cmp(loan.Outstanding) > 0 {
+		rebate = loan.Outstanding
+	}
+
+	lateFees := unpaidLateFees(fees)
+	return &domain.PayoffQuote{
+		LoanID:           loan.ID,
+		AsOf:             pgtype.Date{Time: asOfDate, Valid: true},
+		Outstanding:      loan.Outstanding,
+		UnearnedInterest: unearned,
+		Rebate:           rebate,
+		LateFees:         lateFees,
+		PayoffAmount:     loan.Outstanding.Sub(rebate).Add(lateFees),
+		RebatePolicy:     lu.rebatePolicy.String(),
+	}, nil
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"billing-engine/internal/utils"
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// LoanRestructuring is the new terms of a loan in hardship, empty fields keep the loan's current ones.
+// InterestRate is in percent like a product's. The new schedule starts today, FirstDueDate moves its first
+// installment as it does for a new loan.
+type LoanRestructuring struct {
+	TenorPeriods       int
+	InterestRate       *big.Rat
+	InterestMethod     domain.InterestMethod
+	RepaymentFrequency domain.RepaymentFrequency
+	FirstDueDate       time.Time
+	Reason             string
+}
+
+func (lu *loanUsecase) RestructureLoan(ctx context.Context, loanID uint, terms LoanRestructuring) (*domain.LoanRestructure, error) {
+	if terms.TenorPeriods <= 0 {
+		return nil, fmt.Errorf("%w: tenor periods must be positive", domain.ErrInvalidLoan)
+	}
+
+	var restructure *domain.LoanRestructure
+	err := lu.loanRepo.WithinTransaction(ctx, func(repo domain.LoanRepository) error {
+		var err error
+		restructure, err = lu.restructureLoan(ctx, repo, loanID, terms)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restructure, nil
+}
+
+func (lu *loanUsecase) restructureLoan(ctx context.Context, repo domain.LoanRepository, loanID uint, terms LoanRestructuring) (*domain.LoanRestructure, error) {
+	loan, err := repo.GetLoanByIDForUpdate(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureAcceptsPayments(loan); err != nil {
+		return nil, err
+	}
+	schedules, err := repo.GetUnpaidBillingSchedules(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("loan usecase: failed to get unpaid billing schedules: %w", err)
+	}
+	if len(schedules) == 0 {
+		return nil, domain.ErrLoanFullyPaid
+	}
+
+	interestRate := loan.InterestRate
+	if terms.InterestRate != nil {
+		if interestRate, err = percentToNumeric(terms.InterestRate); err != nil {
+			return nil, fmt.Errorf("%w: interest rate %v", domain.ErrInvalidLoan, err)
+		}
+	}
+	rate, err := utils.NumericToRat(interestRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert interest rate: %w", err)
+	}
+	method := loan.InterestMethod
+	if terms.InterestMethod != "" {
+		if method, err = domain.ParseInterestMethod(string(terms.InterestMethod)); err != nil {
+			return nil, err
+		}
+	}
+	frequency := loan.RepaymentFrequency
+	if terms.RepaymentFrequency != "" {
+		if frequency, err = domain.ParseRepaymentFrequency(string(terms.RepaymentFrequency)); err != nil {
+			return nil, err
+		}
+	}
+	// the installment fee of the product keeps being charged, the origination fee was charged once already
+	installmentFee := domain.Money{}
+	if loan.ProductID != 0 {
+		product, err := lu.productRepo.GetLoanProductByID(ctx, loan.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		installmentFee = product.InstallmentFee
+	}
+
+	now := lu.clock.Now()
+	principal := restructuredPrincipal(schedules, now)
+	first := schedules[0]
+	schedule, err := lu.generateSchedule(scheduleRequest{
+		Principal:      principal,
+		Rate:           rate,
+		Method:         method,
+		Periods:        terms.TenorPeriods,
+		Frequency:      frequency,
+		Start:          now,
+		FirstDueDate:   terms.FirstDueDate,
+		FirstPeriod:    first.Period,
+		InstallmentFee: installmentFee,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	restructure := &domain.LoanRestructure{
+		LoanID:             loan.ID,
+		FromVersion:        first.Version,
+		ToVersion:          first.Version + 1,
+		OutstandingBefore:  loan.Outstanding,
+		Principal:          principal,
+		OutstandingAfter:   schedule.Total,
+		InterestRate:       interestRate,
+		InterestMethod:     method,
+		TenorPeriods:       terms.TenorPeriods,
+		RepaymentFrequency: frequency,
+		Reason:             terms.Reason,
+		Schedule:           schedule.Installments,
+	}
+	// the loan is now repaid by the installments already paid followed by the new schedule
+	loan.InterestRate = interestRate
+	loan.InterestMethod = method
+	loan.TenorPeriods = int(first.Period) - 1 + terms.TenorPeriods
+	loan.RepaymentFrequency = frequency
+	loan.Outstanding = schedule.Total
+	loan.InstallmentAmount = schedule.Regular
+	loan.DelinquentWeeks = 0
+	if err := repo.RestructureLoan(ctx, loan, restructure); err != nil {
+		return nil, err
+	}
+	if err := postJournalEntries(ctx, repo, restructureEntry(loan, schedules, restructure, now)); err != nil {
+		return nil, err
+	}
+
+	// the arrears are rescheduled, so nothing is overdue anymore
+	if loan.Status == domain.LoanStatusDelinquent {
+		if _, err := transitionLoan(ctx, repo, loan, domain.LoanEventCure, "restructured"); err != nil {
+			return nil, err
+		}
+	}
+	return restructure, nil
+}
+
+// restructuredPrincipal is the balance a restructure reschedules as of asOf: the unpaid principal plus the interest
+// and fees of installments already due. The interest of installments not due yet is unearned, the new schedule
+// charges interest on the balance again.
+func restructuredPrincipal(schedules []domain.BillingSchedule, asOf time.Time) domain.Money {
+	asOfDate := time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 0, 0, 0, 0, time.UTC)
+	principal := domain.Money{}
+	for _, schedule := range schedules {
+		remaining := schedule.RemainingComponents()
+		principal = principal.Add(remaining.Principal)
+		if !schedule.DueDate.Time.After(asOfDate) {
+			principal = principal.Add(remaining.Interest).Add(remaining.Fee)
+		}
+	}
+	return principal
+}
+
+func (lu *loanUsecase) GetSchedule(ctx context.Context, loanID uint) ([]domain.BillingSchedule, error) {
+	if _, err := lu.loanRepo.GetLoanByID(ctx, loanID); err != nil {
+		return nil, err
+	}
+	return lu.loanRepo.GetBillingSchedules(ctx, loanID)
+}
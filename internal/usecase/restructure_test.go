@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRestructureLoan(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+	// weeks 5 and 6 are due, weeks 7 to 10 are not
+	loan, schedules := payoffFixture(1, now)
+	loan.Status = domain.LoanStatusDelinquent
+	loan.DelinquentWeeks = 2
+	for i := range schedules {
+		schedules[i].Version = 1
+	}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("RestructureLoan", ctx, loan, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+
+	restructure, err := loanUsecase.RestructureLoan(ctx, 1, LoanRestructuring{
+		TenorPeriods: 4,
+		InterestRate: big.NewRat(8, 1),
+		Reason:       "hardship",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, restructure.FromVersion)
+	assert.Equal(t, 2, restructure.ToVersion)
+	assert.Equal(t, "660000.00", restructure.OutstandingBefore.String())
+	// the unpaid principal plus the interest of the two installments already due, flat 8% on top of it
+	assert.Equal(t, "620000.00", restructure.Principal.String())
+	assert.Equal(t, "669600.00", restructure.OutstandingAfter.String())
+	assert.Len(t, restructure.Schedule, 4)
+	for i, installment := range restructure.Schedule {
+		assert.Equal(t, uint(5+i), installment.Period)
+		assert.Equal(t, "167400.00", installment.Amount.String())
+		assert.Equal(t, now.AddDate(0, 0, 7*(i+1)).Format(time.DateOnly), installment.DueDate.Time.Format(time.DateOnly))
+	}
+
+	assert.Equal(t, 8, loan.TenorPeriods)
+	assert.Equal(t, "669600.00", loan.Outstanding.String())
+	assert.Equal(t, "167400.00", loan.InstallmentAmount.String())
+	assert.Equal(t, 0, loan.DelinquentWeeks)
+	assert.Equal(t, domain.LoanStatusActive, loan.Status)
+	transition := mockRepo.Calls[len(mockRepo.Calls)-1].Arguments.Get(1).(*domain.LoanStatusTransition)
+	assert.Equal(t, domain.LoanEventCure, transition.Event)
+}
+
+func TestRestructureLoanRejectsClosedLoan(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(&domain.Loan{ID: 1, Status: domain.LoanStatusPaidOff}, nil)
+
+	_, err := loanUsecase.RestructureLoan(ctx, 1, LoanRestructuring{TenorPeriods: 4})
+	assert.ErrorIs(t, err, domain.ErrLoanNotActive)
+
+	_, err = loanUsecase.RestructureLoan(ctx, 1, LoanRestructuring{})
+	assert.ErrorIs(t, err, domain.ErrInvalidLoan)
+	mockRepo.AssertNotCalled(t, "RestructureLoan", mock.Anything, mock.Anything, mock.Anything)
+}
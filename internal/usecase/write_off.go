@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"billing-engine/internal/domain"
+	"context"
+	"fmt"
+)
+
+func (lu *loanUsecase) WriteOffLoan(ctx context.Context, loanID uint, reason string) (*domain.LoanWriteOff, error) {
+	var writeOff *domain.LoanWriteOff
+	err := lu.loanRepo.WithinTransaction(ctx, func(repo domain.LoanRepository) error {
+		loan, err := repo.GetLoanByIDForUpdate(ctx, loanID)
+		if err != nil {
+			return err
+		}
+		_, writeOff, err = lu.writeOff(ctx, repo, loan, reason, false)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return writeOff, nil
+}
+
+func (lu *loanUsecase) GetWriteOff(ctx context.Context, loanID uint) (*domain.LoanWriteOff, error) {
+	if _, err := lu.loanRepo.GetLoanByID(ctx, loanID); err != nil {
+		return nil, err
+	}
+	return lu.loanRepo.GetLoanWriteOff(ctx, loanID)
+}
+
+// writeOff moves the loan to written off and records what was left of it, repo must be the unit of work holding
+// the loan row lock. The receivables are removed from the ledger and the interest accrued on the loan is settled.
+func (lu *loanUsecase) writeOff(ctx context.Context, repo domain.LoanRepository, loan *domain.Loan, reason string, automatic bool) (*domain.LoanStatusTransition, *domain.LoanWriteOff, error) {
+	schedules, err := repo.GetUnpaidBillingSchedules(ctx, loan.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loan usecase: failed to get unpaid billing schedules: %w", err)
+	}
+	fees, err := repo.GetLateFees(ctx, loan.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loan usecase: failed to get late fees: %w", err)
+	}
+
+	transition, err := transitionLoan(ctx, repo, loan, domain.LoanEventWriteOff, reason)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	remaining := remainingComponents(schedules)
+	lateFees := unpaidLateFees(fees)
+	writeOff := &domain.LoanWriteOff{
+		LoanID:    loan.ID,
+		Principal: remaining.Principal,
+		Interest:  remaining.Interest,
+		Fee:       remaining.Fee,
+		LateFees:  lateFees,
+		Amount:    remaining.Principal.Add(remaining.Interest).Add(remaining.Fee).Add(lateFees),
+		Reason:    reason,
+		Automatic: automatic,
+	}
+	loan.Outstanding = domain.Money{}
+	if err := repo.WriteOffLoan(ctx, loan, writeOff); err != nil {
+		return nil, nil, err
+	}
+
+	if err := postJournalEntries(ctx, repo, writeOffEntry(loan, schedules, lateFees)); err != nil {
+		return nil, nil, err
+	}
+	if err := settleInterest(ctx, repo, loan, 0); err != nil {
+		return nil, nil, err
+	}
+	return transition, writeOff, nil
+}
+
+func (lu *loanUsecase) RecordRecovery(ctx context.Context, loanID uint, amount domain.Money) (*domain.Recovery, error) {
+	if amount.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", domain.ErrInvalidRecovery)
+	}
+
+	var recovery *domain.Recovery
+	err := lu.loanRepo.WithinTransaction(ctx, func(repo domain.LoanRepository) error {
+		loan, err := repo.GetLoanByIDForUpdate(ctx, loanID)
+		if err != nil {
+			return err
+		}
+		if loan.Status != domain.LoanStatusWrittenOff {
+			return fmt.Errorf("%w: loan is %s, recoveries are only collected on written off loans", domain.ErrInvalidRecovery, loan.Status)
+		}
+		writeOff, err := repo.GetLoanWriteOff(ctx, loanID)
+		if err != nil {
+			return err
+		}
+		if unrecovered := writeOff.Unrecovered(); amount.Cmp(unrecovered) > 0 {
+			return fmt.Errorf("%w: amount %s is more than the %s still to recover", domain.ErrInvalidRecovery, amount, unrecovered)
+		}
+
+		recovery = &domain.Recovery{LoanID: loanID, Amount: amount}
+		writeOff.Recovered = writeOff.Recovered.Add(amount)
+		if err := repo.RecordRecovery(ctx, writeOff, recovery); err != nil {
+			return err
+		}
+		return postJournalEntries(ctx, repo, recoveryEntry(recovery))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recovery, nil
+}
+
+func (lu *loanUsecase) GetRecoveries(ctx context.Context, loanID uint) ([]domain.Recovery, error) {
+	if _, err := lu.loanRepo.GetLoanByID(ctx, loanID); err != nil {
+		return nil, err
+	}
+	return lu.loanRepo.GetRecoveries(ctx, loanID)
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"billing-engine/internal/clock"
+	"billing-engine/internal/domain"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestWriteOffLoanRecordsWhatIsLeft(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)))
+	ctx := context.Background()
+	loan, schedules := payoffFixture(1, now)
+	loan.Status = domain.LoanStatusDelinquent
+	fees := []domain.LateFee{{ID: 3, LoanID: 1, Amount: domain.NewMoneyFromUnits(5000), PaidAmount: domain.NewMoneyFromUnits(2000)}}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("GetLateFees", ctx, uint(1)).Return(fees, nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+	mockRepo.On("WriteOffLoan", ctx, loan, mock.Anything).Return(nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	writeOff, err := loanUsecase.WriteOffLoan(ctx, 1, "borrower deceased")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "600000.00", writeOff.Principal.String())
+	assert.Equal(t, "60000.00", writeOff.Interest.String())
+	assert.Equal(t, "3000.00", writeOff.LateFees.String())
+	assert.Equal(t, "663000.00", writeOff.Amount.String())
+	assert.Equal(t, "borrower deceased", writeOff.Reason)
+	assert.False(t, writeOff.Automatic)
+	assert.Equal(t, domain.LoanStatusWrittenOff, loan.Status)
+	assert.True(t, loan.Outstanding.IsZero())
+
+	transition := mockRepo.lastCall("TransitionLoanStatus").Get(1).(*domain.LoanStatusTransition)
+	assert.Equal(t, domain.LoanEventWriteOff, transition.Event)
+	entries := mockRepo.postedEntries()
+	assert.Equal(t, domain.JournalWriteOff, entries[0].Kind)
+	assert.Equal(t, "603000.00", domain.LedgerBalances(entries)[domain.AccountWriteOffExpense].String())
+}
+
+func TestRefreshDelinquencyWritesOffAfterConfiguredWeeks(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)), WithAutomaticWriteOff(4))
+	ctx := context.Background()
+	loan, schedules := payoffFixture(1, now)
+	loan.Status = domain.LoanStatusDelinquent
+	loan.DelinquentWeeks = 3
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("IsDelinquent", ctx, uint(1), now).Return(&domain.CheckDelinquentAmount{LoanID: 1, TotalWeek: 4}, nil)
+	mockRepo.On("GetLateFees", ctx, uint(1)).Return([]domain.LateFee(nil), nil)
+	mockRepo.On("UpdateDelinquentWeeks", ctx, uint(1), 4).Return(nil)
+	mockRepo.On("GetUnpaidBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("TransitionLoanStatus", ctx, mock.Anything).Return(nil)
+	mockRepo.On("WriteOffLoan", ctx, loan, mock.Anything).Return(nil)
+	mockRepo.On("GetBillingSchedules", ctx, uint(1)).Return(schedules, nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	updated, changed, err := loanUsecase.RefreshDelinquency(ctx, 1, now)
+
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	assert.True(t, changed)
+	assert.Equal(t, domain.LoanStatusWrittenOff, loan.Status)
+	writeOff := mockRepo.lastCall("WriteOffLoan").Get(2).(*domain.LoanWriteOff)
+	assert.True(t, writeOff.Automatic)
+	assert.Equal(t, "4 installments overdue", writeOff.Reason)
+	assert.Equal(t, "660000.00", writeOff.Amount.String())
+}
+
+func TestRefreshDelinquencyKeepsLoansBelowTheWriteOffWeeks(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository), WithClock(clock.NewFake(now)), WithAutomaticWriteOff(4))
+	ctx := context.Background()
+	loan := &domain.Loan{ID: 1, Status: domain.LoanStatusDelinquent, DelinquentWeeks: 3}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("IsDelinquent", ctx, uint(1), now).Return(&domain.CheckDelinquentAmount{LoanID: 1, TotalWeek: 3}, nil)
+	mockRepo.On("GetLateFees", ctx, uint(1)).Return([]domain.LateFee(nil), nil)
+
+	updated, changed, err := loanUsecase.RefreshDelinquency(ctx, 1, now)
+
+	assert.NoError(t, err)
+	assert.False(t, updated)
+	assert.False(t, changed)
+	assert.Equal(t, domain.LoanStatusDelinquent, loan.Status)
+	mockRepo.AssertNotCalled(t, "WriteOffLoan", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRecordRecoveryOnWrittenOffLoan(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+	loan := &domain.Loan{ID: 1, Status: domain.LoanStatusWrittenOff}
+	writeOff := &domain.LoanWriteOff{ID: 4, LoanID: 1, Amount: domain.NewMoneyFromUnits(663000), Recovered: domain.NewMoneyFromUnits(600000)}
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(loan, nil)
+	mockRepo.On("GetLoanWriteOff", ctx, uint(1)).Return(writeOff, nil)
+	mockRepo.On("RecordRecovery", ctx, writeOff, mock.Anything).Return(nil)
+	mockRepo.On("PostJournalEntry", ctx, mock.Anything).Return(nil)
+
+	recovery, err := loanUsecase.RecordRecovery(ctx, 1, domain.NewMoneyFromUnits(50000))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "50000.00", recovery.Amount.String())
+	assert.Equal(t, "650000.00", writeOff.Recovered.String())
+	entries := mockRepo.postedEntries()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, domain.JournalRecovery, entries[0].Kind)
+	balances := domain.LedgerBalances(entries)
+	assert.Equal(t, "50000.00", balances[domain.AccountCash].String())
+	assert.Equal(t, "-50000.00", balances[domain.AccountRecoveryIncome].String())
+
+	// only 13000 is left to recover
+	_, err = loanUsecase.RecordRecovery(ctx, 1, domain.NewMoneyFromUnits(20000))
+	assert.True(t, errors.Is(err, domain.ErrInvalidRecovery))
+	mockRepo.AssertNumberOfCalls(t, "RecordRecovery", 1)
+}
+
+func TestRecordRecoveryRejectsLoansNotWrittenOff(t *testing.T) {
+	mockRepo := new(MockLoanRepository)
+	loanUsecase := NewLoanUsecase(mockRepo, new(MockLoanProductRepository))
+	ctx := context.Background()
+
+	mockRepo.On("GetLoanByIDForUpdate", ctx, uint(1)).Return(&domain.Loan{ID: 1, Status: domain.LoanStatusDelinquent}, nil)
+
+	_, err := loanUsecase.RecordRecovery(ctx, 1, domain.NewMoneyFromUnits(50000))
+
+	assert.True(t, errors.Is(err, domain.ErrInvalidRecovery))
+	mockRepo.AssertNotCalled(t, "RecordRecovery", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetWriteOffReportIncludesTheLastDay(t *testing.T) {
+	now := time.Date(2024, 6, 20, 15, 0, 0, 0, time.UTC)
+	mockRepo := new(MockLedgerRepository)
+	ledgerUsecase := NewLedgerUsecase(mockRepo, clock.NewFake(now))
+	ctx := context.Background()
+	from := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.On("GetWriteOffs", ctx, from, end).Return([]domain.LoanWriteOff{
+		{LoanID: 1, Amount: domain.NewMoneyFromUnits(663000)},
+		{LoanID: 2, Amount: domain.NewMoneyFromUnits(120000)},
+	}, nil)
+	mockRepo.On("GetRecoveries", ctx, from, end).Return([]domain.Recovery{
+		{LoanID: 1, Amount: domain.NewMoneyFromUnits(50000)},
+		{LoanID: 7, Amount: domain.NewMoneyFromUnits(2500)},
+	}, nil)
+
+	report, err := ledgerUsecase.GetWriteOffReport(ctx, time.Time{}, time.Time{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, from, report.From.Time)
+	assert.Equal(t, time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC), report.To.Time)
+	assert.Equal(t, "783000.00", report.WrittenOff.String())
+	assert.Equal(t, "52500.00", report.Recovered.String())
+}
@@ -21,7 +21,7 @@ Inputs:
 
 Flow:
 1. BigFloatToNumeric converts a big.Float to pgtype.Numeric by first converting the big.Float to a string and then scanning it into a pgtype.Numeric.
-2. NumericToBigFloat and NumericToFloat64 convert a pgtype.Numeric to big.Float and float64 respectively, using the integer and exponent parts of pgtype.Numeric.
+2. NumericToBigFloat and NumericToFloat64 convert a pgtype.Numeric to big.Float and float64 respectively, going through the exact big.Rat returned by NumericToRat.
 3. Float64ToNumeric converts a float64 to pgtype.Numeric by first converting the float64 to big.Float and then using BigFloatToNumeric.
 4. CeilBigFloat calculates the ceiling of a big.Float by separating it into integer and fractional parts, and adjusting the integer part if there is a non-zero fractional component.
 
@@ -35,7 +35,6 @@ package utils
 
 import (
 	"fmt"
-	"math"
 	"math/big"
 
 	"github.com/jackc/pgx/v5/pgtype"
@@ -56,18 +55,52 @@ func BigFloatToNumeric(f *big.Float) (pgtype.Numeric, error) {
 }
 
 func NumericToBigFloat(n pgtype.Numeric) (*big.Float, error) {
+	r, err := NumericToRat(n)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Float).SetRat(r), nil
+}
 
-	intnumber := n.Int.Int64()
-	expnumber := n.Exp
+func NumericToFloat64(n pgtype.Numeric) (float64, error) {
+	r, err := NumericToRat(n)
+	if err != nil {
+		return 0, err
+	}
+	f, _ := r.Float64()
+	return f, nil
+}
 
-	return big.NewFloat(float64(intnumber) * math.Pow(10, float64(expnumber))), nil
+// NumericToRat converts a pgtype.Numeric to an exact big.Rat without going through Int64 or float64.
+func NumericToRat(n pgtype.Numeric) (*big.Rat, error) {
+	if !n.Valid || n.Int == nil {
+		return new(big.Rat), nil
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return nil, fmt.Errorf("numeric is not a finite number")
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(n.Exp))), nil)
+	if n.Exp >= 0 {
+		return new(big.Rat).SetInt(new(big.Int).Mul(n.Int, scale)), nil
+	}
+	return new(big.Rat).SetFrac(n.Int, scale), nil
 }
 
-func NumericToFloat64(n pgtype.Numeric) (float64, error) {
-	intnumber := n.Int.Int64()
-	expnumber := n.Exp
+// RatToNumeric converts r to a pgtype.Numeric with scale decimal places, it fails when r needs more of them.
+func RatToNumeric(r *big.Rat, scale int32) (pgtype.Numeric, error) {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs32(scale))), nil)
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(unit))
+	if !scaled.IsInt() {
+		return pgtype.Numeric{}, fmt.Errorf("%s has more than %d decimal places", r.RatString(), scale)
+	}
+	return pgtype.Numeric{Int: new(big.Int).Set(scaled.Num()), Exp: -abs32(scale), Valid: true}, nil
+}
 
-	return float64(intnumber) * math.Pow(10, float64(expnumber)), nil
+func abs32(x int32) int32 {
+	if x < 0 {
+		return -x
+	}
+	return x
 }
 
 func Float64ToNumeric(f float64) (pgtype.Numeric, error) {
@@ -27,3 +27,31 @@ func TestBigFloatToNumericNilInput(t *testing.T) {
 		t.Error("Expected an error for nil big.Float input, but got none")
 	}
 }
+
+// NumericToRat keeps values that do not fit in an int64 exact
+func TestNumericToRatLargeValue(t *testing.T) {
+	intPart, _ := new(big.Int).SetString("123456789012345678901234", 10)
+	numeric := pgtype.Numeric{Int: intPart, Exp: -2, Valid: true}
+	r, err := NumericToRat(numeric)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if r.FloatString(2) != "1234567890123456789012.34" {
+		t.Errorf("Expected 1234567890123456789012.34, got %s", r.FloatString(2))
+	}
+}
+
+// RatToNumeric keeps the exact value and rejects values needing more decimal places
+func TestRatToNumeric(t *testing.T) {
+	numeric, err := RatToNumeric(big.NewRat(1275, 10000), 6)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if numeric.Int.Int64() != 127500 || numeric.Exp != -6 {
+		t.Errorf("Expected 127500e-6, got %ve%d", numeric.Int, numeric.Exp)
+	}
+
+	if _, err := RatToNumeric(big.NewRat(1, 3), 6); err == nil {
+		t.Error("Expected an error for 1/3, but got none")
+	}
+}
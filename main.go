@@ -1,8 +1,11 @@
 package main
 
 import (
+	"billing-engine/internal/clock"
 	"billing-engine/internal/config"
 	"billing-engine/internal/delivery/http"
+	"billing-engine/internal/domain"
+	"billing-engine/internal/job"
 	"billing-engine/internal/repository"
 	"billing-engine/internal/usecase"
 	"context"
@@ -10,6 +13,7 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 )
 
 func main() {
@@ -23,11 +27,96 @@ func main() {
 	}
 	defer dbpool.Close()
 
-	loanRepo := repository.NewLoanRepository(dbpool)
-	loanUsecase := usecase.NewLoanUsecase(loanRepo)
+	roundingMode, err := domain.ParseRoundingMode(cfg.RoundingMode)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	remainderPolicy, err := usecase.ParseRemainderPolicy(cfg.RoundingRemainder)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	rebatePolicy, err := usecase.ParseInterestRebatePolicy(cfg.PayoffRebatePolicy)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	lateFeePolicy, err := usecase.ParseLateFeePolicy(cfg.LateFeeFlatPerInstallment, cfg.LateFeePercent, cfg.LateFeeGraceDays, cfg.LateFeeCap)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	convention, err := domain.ParseBusinessDayConvention(cfg.BusinessDayConvention)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	accrualMethod, err := domain.ParseAccrualMethod(cfg.InterestAccrualMethod)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	holidayRepo := repository.NewHolidayRepository(dbpool)
+	if cfg.HolidayFile != "" {
+		holidayRepo = repository.NewHolidayFileRepository(cfg.HolidayFile)
+	}
+	holidays, err := holidayRepo.ListHolidays(context.Background(), cfg.HolidayRegion)
+	if err != nil {
+		log.Fatalf("Could not load holidays: %v", err)
+	}
+
+	clk := clock.New()
+	loanRepo := repository.NewLoanRepository(dbpool, clk)
+	productRepo := repository.NewLoanProductRepository(dbpool)
+	loanUsecase := usecase.NewLoanUsecase(loanRepo, productRepo,
+		usecase.WithClock(clk),
+		usecase.WithRoundingMode(roundingMode),
+		usecase.WithRemainderPolicy(remainderPolicy),
+		usecase.WithPaymentAllocationPolicy(usecase.PaymentAllocationPolicy{
+			AllowPartial:     cfg.AllowPartialPayments,
+			AllowOverpayment: cfg.AllowOverpayments,
+		}),
+		usecase.WithInterestRebatePolicy(rebatePolicy),
+		usecase.WithLateFeePolicy(lateFeePolicy),
+		usecase.WithBusinessCalendar(domain.NewBusinessCalendar(holidays), convention),
+		usecase.WithAccrualMethod(accrualMethod),
+		usecase.WithAutomaticWriteOff(cfg.WriteOffAfterInstallments),
+	)
+	borrowerRepo := repository.NewBorrowerRepository(dbpool)
+	if cfg.IdempotencyLease <= http.CreateLoanTimeout {
+		log.Fatalf("Invalid configuration: IDEMPOTENCY_LEASE must be longer than the %s loan creation timeout", http.CreateLoanTimeout)
+	}
+	idempotencyRepo := repository.NewIdempotencyRepository(dbpool, cfg.IdempotencyLease)
+	borrowerUsecase := usecase.NewBorrowerUsecase(borrowerRepo, loanRepo)
+	productUsecase := usecase.NewLoanProductUsecase(productRepo)
+	delinquencyUsecase := usecase.NewDelinquencyUsecase(loanRepo, loanUsecase, clk, cfg.DelinquencyJobBatchSize)
+	accrualUsecase := usecase.NewAccrualUsecase(loanRepo, loanUsecase, clk, cfg.AccrualJobBatchSize)
+	ledgerUsecase := usecase.NewLedgerUsecase(repository.NewLedgerRepository(dbpool), clk)
+
+	if cfg.DelinquencyJobSchedule != "" {
+		scheduler, err := job.ScheduleDelinquency(cfg.DelinquencyJobSchedule, delinquencyUsecase)
+		if err != nil {
+			log.Fatalf("Invalid configuration: %v", err)
+		}
+		defer scheduler.Stop()
+	}
+
+	if cfg.AccrualJobSchedule != "" {
+		scheduler, err := job.ScheduleAccrual(cfg.AccrualJobSchedule, accrualUsecase)
+		if err != nil {
+			log.Fatalf("Invalid configuration: %v", err)
+		}
+		defer scheduler.Stop()
+	}
 
 	e := echo.New()
-	http.NewLoanHandler(e, loanUsecase)
+	e.Use(middleware.Recover())
+	http.NewLoanHandler(e, loanUsecase, idempotencyRepo)
+	http.NewBorrowerHandler(e, borrowerUsecase)
+	http.NewLoanProductHandler(e, productUsecase)
+	http.NewJobHandler(e, delinquencyUsecase, accrualUsecase)
+	http.NewLedgerHandler(e, ledgerUsecase)
 
 	e.Logger.Fatal(e.Start(":8080"))
 }
@@ -9,12 +9,22 @@ import (
 )
 
 type BillingSchedule struct {
-	ID      int32
-	LoanID  int32
-	Week    int32
-	Amount  pgtype.Numeric
-	DueDate pgtype.Date
-	Paid    pgtype.Bool
+	ID              int32
+	LoanID          int32
+	Period          int32
+	Amount          pgtype.Numeric
+	DueDate         pgtype.Date
+	Paid            pgtype.Bool
+	PaidAmount      pgtype.Numeric
+	PrincipalAmount pgtype.Numeric
+	InterestAmount  pgtype.Numeric
+	FeeAmount       pgtype.Numeric
+	Version         int32
+	SupersededAt    pgtype.Timestamp
+	OriginalDueDate pgtype.Date
+	DeferredPeriods int32
+	WaivedInterest  pgtype.Numeric
+	AccruedInterest pgtype.Numeric
 }
 
 type Borrower struct {
@@ -27,18 +37,219 @@ type Borrower struct {
 	Phone     string
 }
 
-type Loan struct {
+type Holiday struct {
+	ID        int32
+	Createdat pgtype.Timestamp
+	Updatedat pgtype.Timestamp
+	Deletedat pgtype.Timestamp
+	Region    string
+	Date      pgtype.Date
+	Name      string
+}
+
+type IdempotencyKey struct {
+	Key            string
+	RequestHash    string
+	ResponseStatus pgtype.Int4
+	ResponseBody   []byte
+	Createdat      pgtype.Timestamp
+	Completedat    pgtype.Timestamp
+}
+
+type InterestAccrual struct {
+	ID            int32
+	Createdat     pgtype.Timestamp
+	Updatedat     pgtype.Timestamp
+	Deletedat     pgtype.Timestamp
+	LoanID        int32
+	AccrualDate   pgtype.Date
+	Method        string
+	Amount        pgtype.Numeric
+	AccruedToDate pgtype.Numeric
+}
+
+type JournalEntry struct {
+	ID              int32
+	Createdat       pgtype.Timestamp
+	Updatedat       pgtype.Timestamp
+	Deletedat       pgtype.Timestamp
+	LoanID          int32
+	PaymentID       pgtype.Int4
+	Kind            string
+	Description     string
+	PostedAt        pgtype.Timestamp
+	ReversesEntryID pgtype.Int4
+}
+
+type JournalPosting struct {
+	ID          int32
+	Createdat   pgtype.Timestamp
+	Updatedat   pgtype.Timestamp
+	Deletedat   pgtype.Timestamp
+	EntryID     int32
+	AccountCode string
+	Debit       pgtype.Numeric
+	Credit      pgtype.Numeric
+}
+
+type LateFee struct {
 	ID                int32
 	Createdat         pgtype.Timestamp
 	Updatedat         pgtype.Timestamp
 	Deletedat         pgtype.Timestamp
-	BorrowerID        int32
+	LoanID            int32
+	BillingScheduleID int32
+	Amount            pgtype.Numeric
+	PaidAmount        pgtype.Numeric
+	ChargedAt         pgtype.Timestamp
+}
+
+type LedgerAccount struct {
+	ID        int32
+	Createdat pgtype.Timestamp
+	Updatedat pgtype.Timestamp
+	Deletedat pgtype.Timestamp
+	Code      string
+	Number    string
+	Name      string
+	Type      string
+}
+
+type Loan struct {
+	ID                 int32
+	Createdat          pgtype.Timestamp
+	Updatedat          pgtype.Timestamp
+	Deletedat          pgtype.Timestamp
+	BorrowerID         int32
+	Amount             pgtype.Numeric
+	InterestRate       pgtype.Numeric
+	TenorPeriods       int32
+	Outstanding        pgtype.Numeric
+	DelinquentWeeks    int32
+	InstallmentAmount  pgtype.Numeric
+	Closedat           pgtype.Timestamp
+	Status             string
+	InterestMethod     string
+	ProductID          pgtype.Int4
+	RepaymentFrequency string
+	DisbursementDate   pgtype.Date
+	AccruedInterest    pgtype.Numeric
+}
+
+type LoanProduct struct {
+	ID                 int32
+	Createdat          pgtype.Timestamp
+	Updatedat          pgtype.Timestamp
+	Deletedat          pgtype.Timestamp
+	Name               string
+	MinAmount          pgtype.Numeric
+	MaxAmount          pgtype.Numeric
+	InterestRate       pgtype.Numeric
+	InterestMethod     string
+	TenorPeriods       []int32
+	RepaymentFrequency string
+	InstallmentFee     pgtype.Numeric
+	OriginationFeeRate pgtype.Numeric
+	Active             bool
+}
+
+type LoanRestructure struct {
+	ID                 int32
+	Createdat          pgtype.Timestamp
+	Updatedat          pgtype.Timestamp
+	Deletedat          pgtype.Timestamp
+	LoanID             int32
+	FromVersion        int32
+	ToVersion          int32
+	OutstandingBefore  pgtype.Numeric
+	Principal          pgtype.Numeric
+	OutstandingAfter   pgtype.Numeric
+	InterestRate       pgtype.Numeric
+	InterestMethod     string
+	TenorPeriods       int32
+	RepaymentFrequency string
+	Reason             string
+	RestructuredAt     pgtype.Timestamp
+}
+
+type LoanStatusHistory struct {
+	ID             int32
+	Createdat      pgtype.Timestamp
+	Updatedat      pgtype.Timestamp
+	Deletedat      pgtype.Timestamp
+	LoanID         int32
+	FromStatus     string
+	ToStatus       string
+	Event          string
+	Reason         string
+	TransitionedAt pgtype.Timestamp
+}
+
+type LoanWriteOff struct {
+	ID              int32
+	Createdat       pgtype.Timestamp
+	Updatedat       pgtype.Timestamp
+	Deletedat       pgtype.Timestamp
+	LoanID          int32
+	Principal       pgtype.Numeric
+	Interest        pgtype.Numeric
+	Fee             pgtype.Numeric
+	LateFees        pgtype.Numeric
+	Amount          pgtype.Numeric
+	RecoveredAmount pgtype.Numeric
+	Reason          string
+	Automatic       bool
+	WrittenOffAt    pgtype.Timestamp
+}
+
+type Payment struct {
+	ID             int32
+	Createdat      pgtype.Timestamp
+	Updatedat      pgtype.Timestamp
+	Deletedat      pgtype.Timestamp
+	LoanID         int32
+	Amount         pgtype.Numeric
+	PaidAt         pgtype.Timestamp
+	RebateAmount   pgtype.Numeric
+	ReversedAt     pgtype.Timestamp
+	ReversalReason string
+}
+
+type PaymentAllocation struct {
+	ID                int32
+	PaymentID         int32
+	BillingScheduleID int32
 	Amount            pgtype.Numeric
-	InterestRate      pgtype.Numeric
-	DurationWeeks     int32
-	Outstanding       pgtype.Numeric
-	DelinquentWeeks   int32
-	InstallmentAmount pgtype.Numeric
+	RemainingAmount   pgtype.Numeric
+	LateFeeID         pgtype.Int4
+	PrincipalAmount   pgtype.Numeric
+	InterestAmount    pgtype.Numeric
+	FeeAmount         pgtype.Numeric
+	InterestWaived    pgtype.Numeric
+}
+
+type PaymentHoliday struct {
+	ID                  int32
+	Createdat           pgtype.Timestamp
+	Updatedat           pgtype.Timestamp
+	Deletedat           pgtype.Timestamp
+	LoanID              int32
+	FromPeriod          int32
+	Periods             int32
+	StartDate           pgtype.Date
+	EndDate             pgtype.Date
+	CapitalisedInterest pgtype.Numeric
+	Reason              string
+}
+
+type Recovery struct {
+	ID          int32
+	Createdat   pgtype.Timestamp
+	Updatedat   pgtype.Timestamp
+	Deletedat   pgtype.Timestamp
+	LoanID      int32
+	Amount      pgtype.Numeric
+	RecoveredAt pgtype.Timestamp
 }
 
 type TemplateTable struct {
@@ -12,33 +12,73 @@ import (
 )
 
 const checkDelinquentAmount = `-- name: CheckDelinquentAmount :one
-SELECT loan_id, count(1) as total_week, sum(amount)
+SELECT loan_id, count(1) as total_week, sum(amount - paid_amount)::numeric AS amount
 FROM billing_schedule
-WHERE loan_id = $1 AND paid = false AND due_date <  now()
+WHERE loan_id = $1 AND paid = false AND superseded_at IS NULL AND due_date < $2::timestamp
 GROUP BY loan_id
 `
 
+type CheckDelinquentAmountParams struct {
+	LoanID int32
+	AsOf   pgtype.Timestamp
+}
+
 type CheckDelinquentAmountRow struct {
 	LoanID    int32
 	TotalWeek int64
-	Sum       int64
+	Amount    pgtype.Numeric
 }
 
-func (q *Queries) CheckDelinquentAmount(ctx context.Context, loanID int32) (CheckDelinquentAmountRow, error) {
-	row := q.db.QueryRow(ctx, checkDelinquentAmount, loanID)
+func (q *Queries) CheckDelinquentAmount(ctx context.Context, arg CheckDelinquentAmountParams) (CheckDelinquentAmountRow, error) {
+	row := q.db.QueryRow(ctx, checkDelinquentAmount, arg.LoanID, arg.AsOf)
 	var i CheckDelinquentAmountRow
-	err := row.Scan(&i.LoanID, &i.TotalWeek, &i.Sum)
+	err := row.Scan(&i.LoanID, &i.TotalWeek, &i.Amount)
 	return i, err
 }
 
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET response_status = $2, response_body = $3, completedat = now()
+WHERE key = $1
+`
+
+type CompleteIdempotencyKeyParams struct {
+	Key            string
+	ResponseStatus pgtype.Int4
+	ResponseBody   []byte
+}
+
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, completeIdempotencyKey, arg.Key, arg.ResponseStatus, arg.ResponseBody)
+	return err
+}
+
+const countOpenLoansByBorrowerID = `-- name: CountOpenLoansByBorrowerID :one
+SELECT count(*)
+FROM loans
+WHERE borrower_id = $1 AND status <> ALL($2::varchar[])
+`
+
+type CountOpenLoansByBorrowerIDParams struct {
+	BorrowerID     int32
+	ClosedStatuses []string
+}
+
+func (q *Queries) CountOpenLoansByBorrowerID(ctx context.Context, arg CountOpenLoansByBorrowerIDParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countOpenLoansByBorrowerID, arg.BorrowerID, arg.ClosedStatuses)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createBillingSchedule = `-- name: CreateBillingSchedule :exec
-INSERT INTO billing_schedule (loan_id, week, amount, due_date, paid)
+INSERT INTO billing_schedule (loan_id, period, amount, due_date, paid)
 VALUES ($1, $2, $3, $4, $5)
 `
 
 type CreateBillingScheduleParams struct {
 	LoanID  int32
-	Week    int32
+	Period  int32
 	Amount  pgtype.Numeric
 	DueDate pgtype.Date
 	Paid    pgtype.Bool
@@ -47,7 +87,7 @@ type CreateBillingScheduleParams struct {
 func (q *Queries) CreateBillingSchedule(ctx context.Context, arg CreateBillingScheduleParams) error {
 	_, err := q.db.Exec(ctx, createBillingSchedule,
 		arg.LoanID,
-		arg.Week,
+		arg.Period,
 		arg.Amount,
 		arg.DueDate,
 		arg.Paid,
@@ -56,13 +96,17 @@ func (q *Queries) CreateBillingSchedule(ctx context.Context, arg CreateBillingSc
 }
 
 const createBillingSchedules = `-- name: CreateBillingSchedules :exec
-INSERT INTO billing_schedule (loan_id, week, amount, due_date, paid)
+INSERT INTO billing_schedule (loan_id, period, amount, due_date, paid, principal_amount, interest_amount, fee_amount, version)
 VALUES (
     unnest($1::int[]),
     unnest($2::int[]),
     unnest($3::numeric[]),
     unnest($4::date[]),
-    unnest($5::boolean[])
+    unnest($5::boolean[]),
+    unnest($6::numeric[]),
+    unnest($7::numeric[]),
+    unnest($8::numeric[]),
+    unnest($9::int[])
 )
 `
 
@@ -72,6 +116,10 @@ type CreateBillingSchedulesParams struct {
 	Column3 []pgtype.Numeric
 	Column4 []pgtype.Date
 	Column5 []bool
+	Column6 []pgtype.Numeric
+	Column7 []pgtype.Numeric
+	Column8 []pgtype.Numeric
+	Column9 []int32
 }
 
 func (q *Queries) CreateBillingSchedules(ctx context.Context, arg CreateBillingSchedulesParams) error {
@@ -81,24 +129,169 @@ func (q *Queries) CreateBillingSchedules(ctx context.Context, arg CreateBillingS
 		arg.Column3,
 		arg.Column4,
 		arg.Column5,
+		arg.Column6,
+		arg.Column7,
+		arg.Column8,
+		arg.Column9,
+	)
+	return err
+}
+
+const createBorrower = `-- name: CreateBorrower :one
+INSERT INTO borrowers (name, email, phone)
+VALUES ($1, $2, $3)
+RETURNING id, createdat, updatedat, deletedat, name, email, phone
+`
+
+type CreateBorrowerParams struct {
+	Name  string
+	Email string
+	Phone string
+}
+
+func (q *Queries) CreateBorrower(ctx context.Context, arg CreateBorrowerParams) (Borrower, error) {
+	row := q.db.QueryRow(ctx, createBorrower, arg.Name, arg.Email, arg.Phone)
+	var i Borrower
+	err := row.Scan(
+		&i.ID,
+		&i.Createdat,
+		&i.Updatedat,
+		&i.Deletedat,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+	)
+	return i, err
+}
+
+const createIdempotencyKey = `-- name: CreateIdempotencyKey :execrows
+INSERT INTO idempotency_keys (key, request_hash)
+VALUES ($1, $2)
+ON CONFLICT (key) DO UPDATE SET createdat = CURRENT_TIMESTAMP
+WHERE idempotency_keys.completedat IS NULL
+  AND idempotency_keys.request_hash = EXCLUDED.request_hash
+  AND idempotency_keys.createdat < CURRENT_TIMESTAMP - $3::interval
+`
+
+type CreateIdempotencyKeyParams struct {
+	Key         string
+	RequestHash string
+	Lease       pgtype.Interval
+}
+
+// a reservation older than the lease was left behind by a request that never finished and is taken over
+func (q *Queries) CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createIdempotencyKey, arg.Key, arg.RequestHash, arg.Lease)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createJournalEntry = `-- name: CreateJournalEntry :one
+INSERT INTO journal_entries (loan_id, payment_id, kind, description, posted_at, reverses_entry_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, posted_at
+`
+
+type CreateJournalEntryParams struct {
+	LoanID          int32
+	PaymentID       pgtype.Int4
+	Kind            string
+	Description     string
+	PostedAt        pgtype.Timestamp
+	ReversesEntryID pgtype.Int4
+}
+
+type CreateJournalEntryRow struct {
+	ID       int32
+	PostedAt pgtype.Timestamp
+}
+
+func (q *Queries) CreateJournalEntry(ctx context.Context, arg CreateJournalEntryParams) (CreateJournalEntryRow, error) {
+	row := q.db.QueryRow(ctx, createJournalEntry,
+		arg.LoanID,
+		arg.PaymentID,
+		arg.Kind,
+		arg.Description,
+		arg.PostedAt,
+		arg.ReversesEntryID,
+	)
+	var i CreateJournalEntryRow
+	err := row.Scan(&i.ID, &i.PostedAt)
+	return i, err
+}
+
+const createJournalPosting = `-- name: CreateJournalPosting :exec
+INSERT INTO journal_postings (entry_id, account_code, debit, credit)
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateJournalPostingParams struct {
+	EntryID     int32
+	AccountCode string
+	Debit       pgtype.Numeric
+	Credit      pgtype.Numeric
+}
+
+func (q *Queries) CreateJournalPosting(ctx context.Context, arg CreateJournalPostingParams) error {
+	_, err := q.db.Exec(ctx, createJournalPosting,
+		arg.EntryID,
+		arg.AccountCode,
+		arg.Debit,
+		arg.Credit,
 	)
 	return err
 }
 
+const createLateFee = `-- name: CreateLateFee :one
+INSERT INTO late_fees (loan_id, billing_schedule_id, amount, charged_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, charged_at
+`
+
+type CreateLateFeeParams struct {
+	LoanID            int32
+	BillingScheduleID int32
+	Amount            pgtype.Numeric
+	ChargedAt         pgtype.Timestamp
+}
+
+type CreateLateFeeRow struct {
+	ID        int32
+	ChargedAt pgtype.Timestamp
+}
+
+func (q *Queries) CreateLateFee(ctx context.Context, arg CreateLateFeeParams) (CreateLateFeeRow, error) {
+	row := q.db.QueryRow(ctx, createLateFee,
+		arg.LoanID,
+		arg.BillingScheduleID,
+		arg.Amount,
+		arg.ChargedAt,
+	)
+	var i CreateLateFeeRow
+	err := row.Scan(&i.ID, &i.ChargedAt)
+	return i, err
+}
+
 const createLoan = `-- name: CreateLoan :one
-INSERT INTO loans (borrower_id, amount, interest_rate, duration_weeks, outstanding, delinquent_weeks, installment_amount)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO loans (borrower_id, amount, interest_rate, tenor_periods, outstanding, delinquent_weeks, installment_amount, interest_method, product_id, repayment_frequency, disbursement_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING id
 `
 
 type CreateLoanParams struct {
-	BorrowerID        int32
-	Amount            pgtype.Numeric
-	InterestRate      pgtype.Numeric
-	DurationWeeks     int32
-	Outstanding       pgtype.Numeric
-	DelinquentWeeks   int32
-	InstallmentAmount pgtype.Numeric
+	BorrowerID         int32
+	Amount             pgtype.Numeric
+	InterestRate       pgtype.Numeric
+	TenorPeriods       int32
+	Outstanding        pgtype.Numeric
+	DelinquentWeeks    int32
+	InstallmentAmount  pgtype.Numeric
+	InterestMethod     string
+	ProductID          pgtype.Int4
+	RepaymentFrequency string
+	DisbursementDate   pgtype.Date
 }
 
 func (q *Queries) CreateLoan(ctx context.Context, arg CreateLoanParams) (int32, error) {
@@ -106,223 +299,1894 @@ func (q *Queries) CreateLoan(ctx context.Context, arg CreateLoanParams) (int32,
 		arg.BorrowerID,
 		arg.Amount,
 		arg.InterestRate,
-		arg.DurationWeeks,
+		arg.TenorPeriods,
 		arg.Outstanding,
 		arg.DelinquentWeeks,
 		arg.InstallmentAmount,
+		arg.InterestMethod,
+		arg.ProductID,
+		arg.RepaymentFrequency,
+		arg.DisbursementDate,
 	)
 	var id int32
 	err := row.Scan(&id)
 	return id, err
 }
 
-const getBillingSchedule = `-- name: GetBillingSchedule :one
-SELECT id, loan_id, week, amount, due_date, paid
-FROM billing_schedule
-WHERE loan_id = $1 AND paid = false ORDER BY week LIMIT 1
+const createLoanProduct = `-- name: CreateLoanProduct :one
+INSERT INTO loan_products (name, min_amount, max_amount, interest_rate, interest_method, tenor_periods, repayment_frequency, installment_fee, origination_fee_rate)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, createdat, updatedat, deletedat, name, min_amount, max_amount, interest_rate, interest_method, tenor_periods, repayment_frequency, installment_fee, origination_fee_rate, active
 `
 
-func (q *Queries) GetBillingSchedule(ctx context.Context, loanID int32) (BillingSchedule, error) {
-	row := q.db.QueryRow(ctx, getBillingSchedule, loanID)
-	var i BillingSchedule
+type CreateLoanProductParams struct {
+	Name               string
+	MinAmount          pgtype.Numeric
+	MaxAmount          pgtype.Numeric
+	InterestRate       pgtype.Numeric
+	InterestMethod     string
+	TenorPeriods       []int32
+	RepaymentFrequency string
+	InstallmentFee     pgtype.Numeric
+	OriginationFeeRate pgtype.Numeric
+}
+
+func (q *Queries) CreateLoanProduct(ctx context.Context, arg CreateLoanProductParams) (LoanProduct, error) {
+	row := q.db.QueryRow(ctx, createLoanProduct,
+		arg.Name,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.InterestRate,
+		arg.InterestMethod,
+		arg.TenorPeriods,
+		arg.RepaymentFrequency,
+		arg.InstallmentFee,
+		arg.OriginationFeeRate,
+	)
+	var i LoanProduct
 	err := row.Scan(
 		&i.ID,
-		&i.LoanID,
-		&i.Week,
-		&i.Amount,
-		&i.DueDate,
-		&i.Paid,
+		&i.Createdat,
+		&i.Updatedat,
+		&i.Deletedat,
+		&i.Name,
+		&i.MinAmount,
+		&i.MaxAmount,
+		&i.InterestRate,
+		&i.InterestMethod,
+		&i.TenorPeriods,
+		&i.RepaymentFrequency,
+		&i.InstallmentFee,
+		&i.OriginationFeeRate,
+		&i.Active,
 	)
 	return i, err
 }
 
-const getLoanByID = `-- name: GetLoanByID :one
-SELECT id, amount, interest_rate, duration_weeks, outstanding, delinquent_weeks, installment_amount
-FROM loans
-WHERE id = $1
+const createLoanRestructure = `-- name: CreateLoanRestructure :one
+INSERT INTO loan_restructures (loan_id, from_version, to_version, outstanding_before, principal, outstanding_after, interest_rate, interest_method, tenor_periods, repayment_frequency, reason, restructured_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, restructured_at
 `
 
-type GetLoanByIDRow struct {
-	ID                int32
-	Amount            pgtype.Numeric
-	InterestRate      pgtype.Numeric
-	DurationWeeks     int32
-	Outstanding       pgtype.Numeric
-	DelinquentWeeks   int32
-	InstallmentAmount pgtype.Numeric
+type CreateLoanRestructureParams struct {
+	LoanID             int32
+	FromVersion        int32
+	ToVersion          int32
+	OutstandingBefore  pgtype.Numeric
+	Principal          pgtype.Numeric
+	OutstandingAfter   pgtype.Numeric
+	InterestRate       pgtype.Numeric
+	InterestMethod     string
+	TenorPeriods       int32
+	RepaymentFrequency string
+	Reason             string
+	RestructuredAt     pgtype.Timestamp
 }
 
-func (q *Queries) GetLoanByID(ctx context.Context, id int32) (GetLoanByIDRow, error) {
-	row := q.db.QueryRow(ctx, getLoanByID, id)
-	var i GetLoanByIDRow
-	err := row.Scan(
-		&i.ID,
-		&i.Amount,
-		&i.InterestRate,
-		&i.DurationWeeks,
-		&i.Outstanding,
-		&i.DelinquentWeeks,
-		&i.InstallmentAmount,
+type CreateLoanRestructureRow struct {
+	ID             int32
+	RestructuredAt pgtype.Timestamp
+}
+
+func (q *Queries) CreateLoanRestructure(ctx context.Context, arg CreateLoanRestructureParams) (CreateLoanRestructureRow, error) {
+	row := q.db.QueryRow(ctx, createLoanRestructure,
+		arg.LoanID,
+		arg.FromVersion,
+		arg.ToVersion,
+		arg.OutstandingBefore,
+		arg.Principal,
+		arg.OutstandingAfter,
+		arg.InterestRate,
+		arg.InterestMethod,
+		arg.TenorPeriods,
+		arg.RepaymentFrequency,
+		arg.Reason,
+		arg.RestructuredAt,
 	)
+	var i CreateLoanRestructureRow
+	err := row.Scan(&i.ID, &i.RestructuredAt)
 	return i, err
 }
 
-const getLoansByBorrowerID = `-- name: GetLoansByBorrowerID :many
-SELECT id, amount, interest_rate, duration_weeks, outstanding, delinquent_weeks
-FROM loans
-WHERE borrower_id = $1
+const createLoanStatusHistory = `-- name: CreateLoanStatusHistory :one
+INSERT INTO loan_status_history (loan_id, from_status, to_status, event, reason, transitioned_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, transitioned_at
 `
 
-type GetLoansByBorrowerIDRow struct {
-	ID              int32
-	Amount          pgtype.Numeric
-	InterestRate    pgtype.Numeric
-	DurationWeeks   int32
-	Outstanding     pgtype.Numeric
-	DelinquentWeeks int32
+type CreateLoanStatusHistoryParams struct {
+	LoanID         int32
+	FromStatus     string
+	ToStatus       string
+	Event          string
+	Reason         string
+	TransitionedAt pgtype.Timestamp
 }
 
-func (q *Queries) GetLoansByBorrowerID(ctx context.Context, borrowerID int32) ([]GetLoansByBorrowerIDRow, error) {
-	rows, err := q.db.Query(ctx, getLoansByBorrowerID, borrowerID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetLoansByBorrowerIDRow
-	for rows.Next() {
-		var i GetLoansByBorrowerIDRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.Amount,
-			&i.InterestRate,
-			&i.DurationWeeks,
-			&i.Outstanding,
-			&i.DelinquentWeeks,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type CreateLoanStatusHistoryRow struct {
+	ID             int32
+	TransitionedAt pgtype.Timestamp
 }
 
-const getLoansWithBorrower = `-- name: GetLoansWithBorrower :many
-SELECT 
-    loans.id AS loan_id, 
-    borrowers.id AS borrower_id, 
-    borrowers.name AS borrower_name, 
-    loans.amount, 
-    loans.interest_rate, 
-    loans.duration_weeks, 
-    loans.outstanding, 
-    loans.delinquent_weeks,
-    loans.installment_amount
-FROM loans
-JOIN borrowers ON loans.borrower_id = borrowers.id
-LIMIT $1 OFFSET $2
+func (q *Queries) CreateLoanStatusHistory(ctx context.Context, arg CreateLoanStatusHistoryParams) (CreateLoanStatusHistoryRow, error) {
+	row := q.db.QueryRow(ctx, createLoanStatusHistory,
+		arg.LoanID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.Event,
+		arg.Reason,
+		arg.TransitionedAt,
+	)
+	var i CreateLoanStatusHistoryRow
+	err := row.Scan(&i.ID, &i.TransitionedAt)
+	return i, err
+}
+
+const createLoanWriteOff = `-- name: CreateLoanWriteOff :one
+INSERT INTO loan_write_offs (loan_id, principal, interest, fee, late_fees, amount, reason, automatic, written_off_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, written_off_at
 `
 
-type GetLoansWithBorrowerParams struct {
-	Limit  int32
-	Offset int32
+type CreateLoanWriteOffParams struct {
+	LoanID       int32
+	Principal    pgtype.Numeric
+	Interest     pgtype.Numeric
+	Fee          pgtype.Numeric
+	LateFees     pgtype.Numeric
+	Amount       pgtype.Numeric
+	Reason       string
+	Automatic    bool
+	WrittenOffAt pgtype.Timestamp
 }
 
-type GetLoansWithBorrowerRow struct {
-	LoanID            int32
-	BorrowerID        int32
-	BorrowerName      string
-	Amount            pgtype.Numeric
-	InterestRate      pgtype.Numeric
-	DurationWeeks     int32
-	Outstanding       pgtype.Numeric
-	DelinquentWeeks   int32
-	InstallmentAmount pgtype.Numeric
+type CreateLoanWriteOffRow struct {
+	ID           int32
+	WrittenOffAt pgtype.Timestamp
 }
 
-func (q *Queries) GetLoansWithBorrower(ctx context.Context, arg GetLoansWithBorrowerParams) ([]GetLoansWithBorrowerRow, error) {
-	rows, err := q.db.Query(ctx, getLoansWithBorrower, arg.Limit, arg.Offset)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetLoansWithBorrowerRow
-	for rows.Next() {
-		var i GetLoansWithBorrowerRow
-		if err := rows.Scan(
-			&i.LoanID,
-			&i.BorrowerID,
-			&i.BorrowerName,
-			&i.Amount,
-			&i.InterestRate,
-			&i.DurationWeeks,
-			&i.Outstanding,
-			&i.DelinquentWeeks,
-			&i.InstallmentAmount,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *Queries) CreateLoanWriteOff(ctx context.Context, arg CreateLoanWriteOffParams) (CreateLoanWriteOffRow, error) {
+	row := q.db.QueryRow(ctx, createLoanWriteOff,
+		arg.LoanID,
+		arg.Principal,
+		arg.Interest,
+		arg.Fee,
+		arg.LateFees,
+		arg.Amount,
+		arg.Reason,
+		arg.Automatic,
+		arg.WrittenOffAt,
+	)
+	var i CreateLoanWriteOffRow
+	err := row.Scan(&i.ID, &i.WrittenOffAt)
+	return i, err
 }
 
-const updateBillingSchedule = `-- name: UpdateBillingSchedule :exec
-UPDATE billing_schedule
-SET paid = $1
-WHERE loan_id = $2 AND week = $3
+const createPayment = `-- name: CreatePayment :one
+INSERT INTO payments (loan_id, amount, rebate_amount, paid_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, paid_at
 `
 
-type UpdateBillingScheduleParams struct {
-	Paid   pgtype.Bool
-	LoanID int32
-	Week   int32
+type CreatePaymentParams struct {
+	LoanID       int32
+	Amount       pgtype.Numeric
+	RebateAmount pgtype.Numeric
+	PaidAt       pgtype.Timestamp
 }
 
-func (q *Queries) UpdateBillingSchedule(ctx context.Context, arg UpdateBillingScheduleParams) error {
-	_, err := q.db.Exec(ctx, updateBillingSchedule, arg.Paid, arg.LoanID, arg.Week)
+type CreatePaymentRow struct {
+	ID     int32
+	PaidAt pgtype.Timestamp
+}
+
+func (q *Queries) CreatePayment(ctx context.Context, arg CreatePaymentParams) (CreatePaymentRow, error) {
+	row := q.db.QueryRow(ctx, createPayment,
+		arg.LoanID,
+		arg.Amount,
+		arg.RebateAmount,
+		arg.PaidAt,
+	)
+	var i CreatePaymentRow
+	err := row.Scan(&i.ID, &i.PaidAt)
+	return i, err
+}
+
+const createPaymentAllocation = `-- name: CreatePaymentAllocation :exec
+INSERT INTO payment_allocations (payment_id, billing_schedule_id, amount, remaining_amount, late_fee_id, principal_amount, interest_amount, fee_amount, interest_waived)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+`
+
+type CreatePaymentAllocationParams struct {
+	PaymentID         int32
+	BillingScheduleID int32
+	Amount            pgtype.Numeric
+	RemainingAmount   pgtype.Numeric
+	LateFeeID         pgtype.Int4
+	PrincipalAmount   pgtype.Numeric
+	InterestAmount    pgtype.Numeric
+	FeeAmount         pgtype.Numeric
+	InterestWaived    pgtype.Numeric
+}
+
+func (q *Queries) CreatePaymentAllocation(ctx context.Context, arg CreatePaymentAllocationParams) error {
+	_, err := q.db.Exec(ctx, createPaymentAllocation,
+		arg.PaymentID,
+		arg.BillingScheduleID,
+		arg.Amount,
+		arg.RemainingAmount,
+		arg.LateFeeID,
+		arg.PrincipalAmount,
+		arg.InterestAmount,
+		arg.FeeAmount,
+		arg.InterestWaived,
+	)
 	return err
 }
 
-const updateLoan = `-- name: UpdateLoan :exec
-UPDATE loans
-SET amount = $1, interest_rate = $2, duration_weeks = $3, outstanding = $4, delinquent_weeks = $5
-WHERE id = $6
+const createPaymentHoliday = `-- name: CreatePaymentHoliday :one
+INSERT INTO payment_holidays (loan_id, from_period, periods, start_date, end_date, capitalised_interest, reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, createdat
 `
 
-type UpdateLoanParams struct {
+type CreatePaymentHolidayParams struct {
+	LoanID              int32
+	FromPeriod          int32
+	Periods             int32
+	StartDate           pgtype.Date
+	EndDate             pgtype.Date
+	CapitalisedInterest pgtype.Numeric
+	Reason              string
+}
+
+type CreatePaymentHolidayRow struct {
+	ID        int32
+	Createdat pgtype.Timestamp
+}
+
+func (q *Queries) CreatePaymentHoliday(ctx context.Context, arg CreatePaymentHolidayParams) (CreatePaymentHolidayRow, error) {
+	row := q.db.QueryRow(ctx, createPaymentHoliday,
+		arg.LoanID,
+		arg.FromPeriod,
+		arg.Periods,
+		arg.StartDate,
+		arg.EndDate,
+		arg.CapitalisedInterest,
+		arg.Reason,
+	)
+	var i CreatePaymentHolidayRow
+	err := row.Scan(&i.ID, &i.Createdat)
+	return i, err
+}
+
+const createRecovery = `-- name: CreateRecovery :one
+INSERT INTO recoveries (loan_id, amount, recovered_at)
+VALUES ($1, $2, $3)
+RETURNING id, recovered_at
+`
+
+type CreateRecoveryParams struct {
+	LoanID      int32
+	Amount      pgtype.Numeric
+	RecoveredAt pgtype.Timestamp
+}
+
+type CreateRecoveryRow struct {
+	ID          int32
+	RecoveredAt pgtype.Timestamp
+}
+
+func (q *Queries) CreateRecovery(ctx context.Context, arg CreateRecoveryParams) (CreateRecoveryRow, error) {
+	row := q.db.QueryRow(ctx, createRecovery, arg.LoanID, arg.Amount, arg.RecoveredAt)
+	var i CreateRecoveryRow
+	err := row.Scan(&i.ID, &i.RecoveredAt)
+	return i, err
+}
+
+const deferBillingSchedule = `-- name: DeferBillingSchedule :exec
+UPDATE billing_schedule
+SET original_due_date = COALESCE(original_due_date, due_date), due_date = $1, deferred_periods = deferred_periods + $2,
+    interest_amount = $3, amount = $4
+WHERE id = $5 AND paid = false AND superseded_at IS NULL
+`
+
+type DeferBillingScheduleParams struct {
+	DueDate         pgtype.Date
+	DeferredPeriods int32
+	InterestAmount  pgtype.Numeric
 	Amount          pgtype.Numeric
-	InterestRate    pgtype.Numeric
-	DurationWeeks   int32
-	Outstanding     pgtype.Numeric
-	DelinquentWeeks int32
 	ID              int32
 }
 
-func (q *Queries) UpdateLoan(ctx context.Context, arg UpdateLoanParams) error {
-	_, err := q.db.Exec(ctx, updateLoan,
+func (q *Queries) DeferBillingSchedule(ctx context.Context, arg DeferBillingScheduleParams) error {
+	_, err := q.db.Exec(ctx, deferBillingSchedule,
+		arg.DueDate,
+		arg.DeferredPeriods,
+		arg.InterestAmount,
 		arg.Amount,
-		arg.InterestRate,
-		arg.DurationWeeks,
-		arg.Outstanding,
-		arg.DelinquentWeeks,
 		arg.ID,
 	)
 	return err
 }
 
-const updateRepaymentSchedule = `-- name: UpdateRepaymentSchedule :exec
-UPDATE billing_schedule
-SET paid = true
-WHERE loan_id = $1 AND due_date < now()
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1 AND completedat IS NULL
 `
 
-func (q *Queries) UpdateRepaymentSchedule(ctx context.Context, loanID int32) error {
-	_, err := q.db.Exec(ctx, updateRepaymentSchedule, loanID)
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	_, err := q.db.Exec(ctx, deleteIdempotencyKey, key)
 	return err
 }
+
+const getBillingSchedule = `-- name: GetBillingSchedule :one
+SELECT id, loan_id, period, amount, due_date, paid, paid_amount, principal_amount, interest_amount, fee_amount, version, superseded_at, original_due_date, deferred_periods, waived_interest, accrued_interest
+FROM billing_schedule
+WHERE loan_id = $1 AND paid = false AND superseded_at IS NULL ORDER BY period LIMIT 1
+`
+
+func (q *Queries) GetBillingSchedule(ctx context.Context, loanID int32) (BillingSchedule, error) {
+	row := q.db.QueryRow(ctx, getBillingSchedule, loanID)
+	var i BillingSchedule
+	err := row.Scan(
+		&i.ID,
+		&i.LoanID,
+		&i.Period,
+		&i.Amount,
+		&i.DueDate,
+		&i.Paid,
+		&i.PaidAmount,
+		&i.PrincipalAmount,
+		&i.InterestAmount,
+		&i.FeeAmount,
+		&i.Version,
+		&i.SupersededAt,
+		&i.OriginalDueDate,
+		&i.DeferredPeriods,
+		&i.WaivedInterest,
+		&i.AccruedInterest,
+	)
+	return i, err
+}
+
+const getBillingSchedulesByLoanID = `-- name: GetBillingSchedulesByLoanID :many
+SELECT id, loan_id, period, amount, due_date, paid, paid_amount, principal_amount, interest_amount, fee_amount, version, superseded_at, original_due_date, deferred_periods, waived_interest, accrued_interest
+FROM billing_schedule
+WHERE loan_id = $1
+ORDER BY version, period
+`
+
+func (q *Queries) GetBillingSchedulesByLoanID(ctx context.Context, loanID int32) ([]BillingSchedule, error) {
+	rows, err := q.db.Query(ctx, getBillingSchedulesByLoanID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BillingSchedule
+	for rows.Next() {
+		var i BillingSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.Period,
+			&i.Amount,
+			&i.DueDate,
+			&i.Paid,
+			&i.PaidAmount,
+			&i.PrincipalAmount,
+			&i.InterestAmount,
+			&i.FeeAmount,
+			&i.Version,
+			&i.SupersededAt,
+			&i.OriginalDueDate,
+			&i.DeferredPeriods,
+			&i.WaivedInterest,
+			&i.AccruedInterest,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getBorrowerByID = `-- name: GetBorrowerByID :one
+SELECT id, createdat, updatedat, deletedat, name, email, phone
+FROM borrowers
+WHERE id = $1 AND deletedat IS NULL
+`
+
+func (q *Queries) GetBorrowerByID(ctx context.Context, id int32) (Borrower, error) {
+	row := q.db.QueryRow(ctx, getBorrowerByID, id)
+	var i Borrower
+	err := row.Scan(
+		&i.ID,
+		&i.Createdat,
+		&i.Updatedat,
+		&i.Deletedat,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+	)
+	return i, err
+}
+
+const getBorrowerByLoanID = `-- name: GetBorrowerByLoanID :one
+SELECT borrowers.id, borrowers.name, borrowers.email, borrowers.phone
+FROM borrowers
+JOIN loans ON loans.borrower_id = borrowers.id
+WHERE loans.id = $1
+`
+
+type GetBorrowerByLoanIDRow struct {
+	ID    int32
+	Name  string
+	Email string
+	Phone string
+}
+
+func (q *Queries) GetBorrowerByLoanID(ctx context.Context, id int32) (GetBorrowerByLoanIDRow, error) {
+	row := q.db.QueryRow(ctx, getBorrowerByLoanID, id)
+	var i GetBorrowerByLoanIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+	)
+	return i, err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT key, request_hash, response_status, response_body, createdat, completedat
+FROM idempotency_keys
+WHERE key = $1
+`
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseBody,
+		&i.Createdat,
+		&i.Completedat,
+	)
+	return i, err
+}
+
+const getInterestAccrualsByLoanID = `-- name: GetInterestAccrualsByLoanID :many
+SELECT id, loan_id, accrual_date, method, amount, accrued_to_date
+FROM interest_accruals
+WHERE loan_id = $1
+ORDER BY accrual_date
+`
+
+type GetInterestAccrualsByLoanIDRow struct {
+	ID            int32
+	LoanID        int32
+	AccrualDate   pgtype.Date
+	Method        string
+	Amount        pgtype.Numeric
+	AccruedToDate pgtype.Numeric
+}
+
+func (q *Queries) GetInterestAccrualsByLoanID(ctx context.Context, loanID int32) ([]GetInterestAccrualsByLoanIDRow, error) {
+	rows, err := q.db.Query(ctx, getInterestAccrualsByLoanID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetInterestAccrualsByLoanIDRow
+	for rows.Next() {
+		var i GetInterestAccrualsByLoanIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.AccrualDate,
+			&i.Method,
+			&i.Amount,
+			&i.AccruedToDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getInterestAccrualTotals = `-- name: GetInterestAccrualTotals :many
+SELECT accrual_date, count(1) AS loans, SUM(amount)::numeric AS amount
+FROM interest_accruals
+WHERE accrual_date BETWEEN $1 AND $2
+GROUP BY accrual_date
+ORDER BY accrual_date
+`
+
+type GetInterestAccrualTotalsParams struct {
+	FromDate pgtype.Date
+	ToDate   pgtype.Date
+}
+
+type GetInterestAccrualTotalsRow struct {
+	AccrualDate pgtype.Date
+	Loans       int64
+	Amount      pgtype.Numeric
+}
+
+func (q *Queries) GetInterestAccrualTotals(ctx context.Context, arg GetInterestAccrualTotalsParams) ([]GetInterestAccrualTotalsRow, error) {
+	rows, err := q.db.Query(ctx, getInterestAccrualTotals, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetInterestAccrualTotalsRow
+	for rows.Next() {
+		var i GetInterestAccrualTotalsRow
+		if err := rows.Scan(&i.AccrualDate, &i.Loans, &i.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getJournalEntriesByLoanID = `-- name: GetJournalEntriesByLoanID :many
+SELECT id, loan_id, payment_id, kind, description, posted_at, reverses_entry_id
+FROM journal_entries
+WHERE loan_id = $1
+ORDER BY id
+`
+
+type GetJournalEntriesByLoanIDRow struct {
+	ID              int32
+	LoanID          int32
+	PaymentID       pgtype.Int4
+	Kind            string
+	Description     string
+	PostedAt        pgtype.Timestamp
+	ReversesEntryID pgtype.Int4
+}
+
+func (q *Queries) GetJournalEntriesByLoanID(ctx context.Context, loanID int32) ([]GetJournalEntriesByLoanIDRow, error) {
+	rows, err := q.db.Query(ctx, getJournalEntriesByLoanID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetJournalEntriesByLoanIDRow
+	for rows.Next() {
+		var i GetJournalEntriesByLoanIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.PaymentID,
+			&i.Kind,
+			&i.Description,
+			&i.PostedAt,
+			&i.ReversesEntryID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getJournalPostingsByLoanID = `-- name: GetJournalPostingsByLoanID :many
+SELECT journal_postings.entry_id, journal_postings.account_code, journal_postings.debit, journal_postings.credit
+FROM journal_postings
+JOIN journal_entries ON journal_entries.id = journal_postings.entry_id
+WHERE journal_entries.loan_id = $1
+ORDER BY journal_postings.entry_id, journal_postings.id
+`
+
+type GetJournalPostingsByLoanIDRow struct {
+	EntryID     int32
+	AccountCode string
+	Debit       pgtype.Numeric
+	Credit      pgtype.Numeric
+}
+
+func (q *Queries) GetJournalPostingsByLoanID(ctx context.Context, loanID int32) ([]GetJournalPostingsByLoanIDRow, error) {
+	rows, err := q.db.Query(ctx, getJournalPostingsByLoanID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetJournalPostingsByLoanIDRow
+	for rows.Next() {
+		var i GetJournalPostingsByLoanIDRow
+		if err := rows.Scan(
+			&i.EntryID,
+			&i.AccountCode,
+			&i.Debit,
+			&i.Credit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLateFeesByLoanID = `-- name: GetLateFeesByLoanID :many
+SELECT
+    late_fees.id,
+    late_fees.loan_id,
+    late_fees.billing_schedule_id,
+    billing_schedule.period,
+    late_fees.amount,
+    late_fees.paid_amount,
+    late_fees.charged_at
+FROM late_fees
+JOIN billing_schedule ON billing_schedule.id = late_fees.billing_schedule_id
+WHERE late_fees.loan_id = $1
+ORDER BY billing_schedule.period
+`
+
+type GetLateFeesByLoanIDRow struct {
+	ID                int32
+	LoanID            int32
+	BillingScheduleID int32
+	Period            int32
+	Amount            pgtype.Numeric
+	PaidAmount        pgtype.Numeric
+	ChargedAt         pgtype.Timestamp
+}
+
+func (q *Queries) GetLateFeesByLoanID(ctx context.Context, loanID int32) ([]GetLateFeesByLoanIDRow, error) {
+	rows, err := q.db.Query(ctx, getLateFeesByLoanID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLateFeesByLoanIDRow
+	for rows.Next() {
+		var i GetLateFeesByLoanIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.BillingScheduleID,
+			&i.Period,
+			&i.Amount,
+			&i.PaidAmount,
+			&i.ChargedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoanByID = `-- name: GetLoanByID :one
+SELECT id, amount, interest_rate, tenor_periods, outstanding, delinquent_weeks, installment_amount, closedat, status, interest_method, product_id, repayment_frequency, disbursement_date, accrued_interest
+FROM loans
+WHERE id = $1
+`
+
+type GetLoanByIDRow struct {
+	ID                 int32
+	Amount             pgtype.Numeric
+	InterestRate       pgtype.Numeric
+	TenorPeriods       int32
+	Outstanding        pgtype.Numeric
+	DelinquentWeeks    int32
+	InstallmentAmount  pgtype.Numeric
+	Closedat           pgtype.Timestamp
+	Status             string
+	InterestMethod     string
+	ProductID          pgtype.Int4
+	RepaymentFrequency string
+	DisbursementDate   pgtype.Date
+	AccruedInterest    pgtype.Numeric
+}
+
+func (q *Queries) GetLoanByID(ctx context.Context, id int32) (GetLoanByIDRow, error) {
+	row := q.db.QueryRow(ctx, getLoanByID, id)
+	var i GetLoanByIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.InterestRate,
+		&i.TenorPeriods,
+		&i.Outstanding,
+		&i.DelinquentWeeks,
+		&i.InstallmentAmount,
+		&i.Closedat,
+		&i.Status,
+		&i.InterestMethod,
+		&i.ProductID,
+		&i.RepaymentFrequency,
+		&i.DisbursementDate,
+		&i.AccruedInterest,
+	)
+	return i, err
+}
+
+const getLoanByIDForUpdate = `-- name: GetLoanByIDForUpdate :one
+SELECT id, amount, interest_rate, tenor_periods, outstanding, delinquent_weeks, installment_amount, closedat, status, interest_method, product_id, repayment_frequency, disbursement_date, accrued_interest
+FROM loans
+WHERE id = $1
+FOR UPDATE
+`
+
+type GetLoanByIDForUpdateRow struct {
+	ID                 int32
+	Amount             pgtype.Numeric
+	InterestRate       pgtype.Numeric
+	TenorPeriods       int32
+	Outstanding        pgtype.Numeric
+	DelinquentWeeks    int32
+	InstallmentAmount  pgtype.Numeric
+	Closedat           pgtype.Timestamp
+	Status             string
+	InterestMethod     string
+	ProductID          pgtype.Int4
+	RepaymentFrequency string
+	DisbursementDate   pgtype.Date
+	AccruedInterest    pgtype.Numeric
+}
+
+func (q *Queries) GetLoanByIDForUpdate(ctx context.Context, id int32) (GetLoanByIDForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, getLoanByIDForUpdate, id)
+	var i GetLoanByIDForUpdateRow
+	err := row.Scan(
+		&i.ID,
+		&i.Amount,
+		&i.InterestRate,
+		&i.TenorPeriods,
+		&i.Outstanding,
+		&i.DelinquentWeeks,
+		&i.InstallmentAmount,
+		&i.Closedat,
+		&i.Status,
+		&i.InterestMethod,
+		&i.ProductID,
+		&i.RepaymentFrequency,
+		&i.DisbursementDate,
+		&i.AccruedInterest,
+	)
+	return i, err
+}
+
+const getLoanProductByID = `-- name: GetLoanProductByID :one
+SELECT id, createdat, updatedat, deletedat, name, min_amount, max_amount, interest_rate, interest_method, tenor_periods, repayment_frequency, installment_fee, origination_fee_rate, active
+FROM loan_products
+WHERE id = $1
+`
+
+func (q *Queries) GetLoanProductByID(ctx context.Context, id int32) (LoanProduct, error) {
+	row := q.db.QueryRow(ctx, getLoanProductByID, id)
+	var i LoanProduct
+	err := row.Scan(
+		&i.ID,
+		&i.Createdat,
+		&i.Updatedat,
+		&i.Deletedat,
+		&i.Name,
+		&i.MinAmount,
+		&i.MaxAmount,
+		&i.InterestRate,
+		&i.InterestMethod,
+		&i.TenorPeriods,
+		&i.RepaymentFrequency,
+		&i.InstallmentFee,
+		&i.OriginationFeeRate,
+		&i.Active,
+	)
+	return i, err
+}
+
+const getLoansByBorrowerID = `-- name: GetLoansByBorrowerID :many
+SELECT id, amount, interest_rate, tenor_periods, outstanding, delinquent_weeks, status, interest_method, product_id, repayment_frequency, disbursement_date, accrued_interest
+FROM loans
+WHERE borrower_id = $1
+`
+
+type GetLoansByBorrowerIDRow struct {
+	ID                 int32
+	Amount             pgtype.Numeric
+	InterestRate       pgtype.Numeric
+	TenorPeriods       int32
+	Outstanding        pgtype.Numeric
+	DelinquentWeeks    int32
+	Status             string
+	InterestMethod     string
+	ProductID          pgtype.Int4
+	RepaymentFrequency string
+	DisbursementDate   pgtype.Date
+	AccruedInterest    pgtype.Numeric
+}
+
+func (q *Queries) GetLoansByBorrowerID(ctx context.Context, borrowerID int32) ([]GetLoansByBorrowerIDRow, error) {
+	rows, err := q.db.Query(ctx, getLoansByBorrowerID, borrowerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLoansByBorrowerIDRow
+	for rows.Next() {
+		var i GetLoansByBorrowerIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.InterestRate,
+			&i.TenorPeriods,
+			&i.Outstanding,
+			&i.DelinquentWeeks,
+			&i.Status,
+			&i.InterestMethod,
+			&i.ProductID,
+			&i.RepaymentFrequency,
+			&i.DisbursementDate,
+			&i.AccruedInterest,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoanStatusHistory = `-- name: GetLoanStatusHistory :many
+SELECT id, loan_id, from_status, to_status, event, reason, transitioned_at
+FROM loan_status_history
+WHERE loan_id = $1
+ORDER BY transitioned_at, id
+`
+
+type GetLoanStatusHistoryRow struct {
+	ID             int32
+	LoanID         int32
+	FromStatus     string
+	ToStatus       string
+	Event          string
+	Reason         string
+	TransitionedAt pgtype.Timestamp
+}
+
+func (q *Queries) GetLoanStatusHistory(ctx context.Context, loanID int32) ([]GetLoanStatusHistoryRow, error) {
+	rows, err := q.db.Query(ctx, getLoanStatusHistory, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLoanStatusHistoryRow
+	for rows.Next() {
+		var i GetLoanStatusHistoryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.Event,
+			&i.Reason,
+			&i.TransitionedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoansWithBorrower = `-- name: GetLoansWithBorrower :many
+SELECT 
+    loans.id AS loan_id, 
+    borrowers.id AS borrower_id, 
+    borrowers.name AS borrower_name, 
+    loans.amount, 
+    loans.interest_rate, 
+    loans.tenor_periods, 
+    loans.outstanding, 
+    loans.delinquent_weeks,
+    loans.installment_amount,
+    loans.status,
+    loans.repayment_frequency
+FROM loans
+JOIN borrowers ON loans.borrower_id = borrowers.id
+LIMIT $1 OFFSET $2
+`
+
+type GetLoansWithBorrowerParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetLoansWithBorrowerRow struct {
+	LoanID             int32
+	BorrowerID         int32
+	BorrowerName       string
+	Amount             pgtype.Numeric
+	InterestRate       pgtype.Numeric
+	TenorPeriods       int32
+	Outstanding        pgtype.Numeric
+	DelinquentWeeks    int32
+	InstallmentAmount  pgtype.Numeric
+	Status             string
+	RepaymentFrequency string
+}
+
+func (q *Queries) GetLoansWithBorrower(ctx context.Context, arg GetLoansWithBorrowerParams) ([]GetLoansWithBorrowerRow, error) {
+	rows, err := q.db.Query(ctx, getLoansWithBorrower, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLoansWithBorrowerRow
+	for rows.Next() {
+		var i GetLoansWithBorrowerRow
+		if err := rows.Scan(
+			&i.LoanID,
+			&i.BorrowerID,
+			&i.BorrowerName,
+			&i.Amount,
+			&i.InterestRate,
+			&i.TenorPeriods,
+			&i.Outstanding,
+			&i.DelinquentWeeks,
+			&i.InstallmentAmount,
+			&i.Status,
+			&i.RepaymentFrequency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLoanWriteOffByLoanID = `-- name: GetLoanWriteOffByLoanID :one
+SELECT id, loan_id, principal, interest, fee, late_fees, amount, recovered_amount, reason, automatic, written_off_at
+FROM loan_write_offs
+WHERE loan_id = $1
+`
+
+type GetLoanWriteOffByLoanIDRow struct {
+	ID              int32
+	LoanID          int32
+	Principal       pgtype.Numeric
+	Interest        pgtype.Numeric
+	Fee             pgtype.Numeric
+	LateFees        pgtype.Numeric
+	Amount          pgtype.Numeric
+	RecoveredAmount pgtype.Numeric
+	Reason          string
+	Automatic       bool
+	WrittenOffAt    pgtype.Timestamp
+}
+
+func (q *Queries) GetLoanWriteOffByLoanID(ctx context.Context, loanID int32) (GetLoanWriteOffByLoanIDRow, error) {
+	row := q.db.QueryRow(ctx, getLoanWriteOffByLoanID, loanID)
+	var i GetLoanWriteOffByLoanIDRow
+	err := row.Scan(
+		&i.ID,
+		&i.LoanID,
+		&i.Principal,
+		&i.Interest,
+		&i.Fee,
+		&i.LateFees,
+		&i.Amount,
+		&i.RecoveredAmount,
+		&i.Reason,
+		&i.Automatic,
+		&i.WrittenOffAt,
+	)
+	return i, err
+}
+
+const getLoanWriteOffsBetween = `-- name: GetLoanWriteOffsBetween :many
+SELECT id, loan_id, principal, interest, fee, late_fees, amount, recovered_amount, reason, automatic, written_off_at
+FROM loan_write_offs
+WHERE written_off_at >= $1 AND written_off_at < $2
+ORDER BY written_off_at, id
+`
+
+type GetLoanWriteOffsBetweenParams struct {
+	FromTime pgtype.Timestamp
+	ToTime   pgtype.Timestamp
+}
+
+type GetLoanWriteOffsBetweenRow struct {
+	ID              int32
+	LoanID          int32
+	Principal       pgtype.Numeric
+	Interest        pgtype.Numeric
+	Fee             pgtype.Numeric
+	LateFees        pgtype.Numeric
+	Amount          pgtype.Numeric
+	RecoveredAmount pgtype.Numeric
+	Reason          string
+	Automatic       bool
+	WrittenOffAt    pgtype.Timestamp
+}
+
+func (q *Queries) GetLoanWriteOffsBetween(ctx context.Context, arg GetLoanWriteOffsBetweenParams) ([]GetLoanWriteOffsBetweenRow, error) {
+	rows, err := q.db.Query(ctx, getLoanWriteOffsBetween, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLoanWriteOffsBetweenRow
+	for rows.Next() {
+		var i GetLoanWriteOffsBetweenRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.Principal,
+			&i.Interest,
+			&i.Fee,
+			&i.LateFees,
+			&i.Amount,
+			&i.RecoveredAmount,
+			&i.Reason,
+			&i.Automatic,
+			&i.WrittenOffAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPaymentAllocationsByLoanID = `-- name: GetPaymentAllocationsByLoanID :many
+SELECT
+    payment_allocations.payment_id,
+    payment_allocations.billing_schedule_id,
+    billing_schedule.period,
+    payment_allocations.amount,
+    payment_allocations.remaining_amount,
+    payment_allocations.late_fee_id,
+    payment_allocations.principal_amount,
+    payment_allocations.interest_amount,
+    payment_allocations.fee_amount,
+    payment_allocations.interest_waived
+FROM payment_allocations
+JOIN payments ON payments.id = payment_allocations.payment_id
+JOIN billing_schedule ON billing_schedule.id = payment_allocations.billing_schedule_id
+WHERE payments.loan_id = $1
+ORDER BY payment_allocations.payment_id, payment_allocations.id
+`
+
+type GetPaymentAllocationsByLoanIDRow struct {
+	PaymentID         int32
+	BillingScheduleID int32
+	Period            int32
+	Amount            pgtype.Numeric
+	RemainingAmount   pgtype.Numeric
+	LateFeeID         pgtype.Int4
+	PrincipalAmount   pgtype.Numeric
+	InterestAmount    pgtype.Numeric
+	FeeAmount         pgtype.Numeric
+	InterestWaived    pgtype.Numeric
+}
+
+func (q *Queries) GetPaymentAllocationsByLoanID(ctx context.Context, loanID int32) ([]GetPaymentAllocationsByLoanIDRow, error) {
+	rows, err := q.db.Query(ctx, getPaymentAllocationsByLoanID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPaymentAllocationsByLoanIDRow
+	for rows.Next() {
+		var i GetPaymentAllocationsByLoanIDRow
+		if err := rows.Scan(
+			&i.PaymentID,
+			&i.BillingScheduleID,
+			&i.Period,
+			&i.Amount,
+			&i.RemainingAmount,
+			&i.LateFeeID,
+			&i.PrincipalAmount,
+			&i.InterestAmount,
+			&i.FeeAmount,
+			&i.InterestWaived,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPaymentsByLoanID = `-- name: GetPaymentsByLoanID :many
+SELECT id, loan_id, amount, paid_at, rebate_amount, reversed_at, reversal_reason
+FROM payments
+WHERE loan_id = $1
+ORDER BY paid_at, id
+`
+
+type GetPaymentsByLoanIDRow struct {
+	ID             int32
+	LoanID         int32
+	Amount         pgtype.Numeric
+	PaidAt         pgtype.Timestamp
+	RebateAmount   pgtype.Numeric
+	ReversedAt     pgtype.Timestamp
+	ReversalReason string
+}
+
+func (q *Queries) GetPaymentsByLoanID(ctx context.Context, loanID int32) ([]GetPaymentsByLoanIDRow, error) {
+	rows, err := q.db.Query(ctx, getPaymentsByLoanID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPaymentsByLoanIDRow
+	for rows.Next() {
+		var i GetPaymentsByLoanIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.Amount,
+			&i.PaidAt,
+			&i.RebateAmount,
+			&i.ReversedAt,
+			&i.ReversalReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecoveriesBetween = `-- name: GetRecoveriesBetween :many
+SELECT id, loan_id, amount, recovered_at
+FROM recoveries
+WHERE recovered_at >= $1 AND recovered_at < $2
+ORDER BY recovered_at, id
+`
+
+type GetRecoveriesBetweenParams struct {
+	FromTime pgtype.Timestamp
+	ToTime   pgtype.Timestamp
+}
+
+type GetRecoveriesBetweenRow struct {
+	ID          int32
+	LoanID      int32
+	Amount      pgtype.Numeric
+	RecoveredAt pgtype.Timestamp
+}
+
+func (q *Queries) GetRecoveriesBetween(ctx context.Context, arg GetRecoveriesBetweenParams) ([]GetRecoveriesBetweenRow, error) {
+	rows, err := q.db.Query(ctx, getRecoveriesBetween, arg.FromTime, arg.ToTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRecoveriesBetweenRow
+	for rows.Next() {
+		var i GetRecoveriesBetweenRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.Amount,
+			&i.RecoveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRecoveriesByLoanID = `-- name: GetRecoveriesByLoanID :many
+SELECT id, loan_id, amount, recovered_at
+FROM recoveries
+WHERE loan_id = $1
+ORDER BY recovered_at, id
+`
+
+type GetRecoveriesByLoanIDRow struct {
+	ID          int32
+	LoanID      int32
+	Amount      pgtype.Numeric
+	RecoveredAt pgtype.Timestamp
+}
+
+func (q *Queries) GetRecoveriesByLoanID(ctx context.Context, loanID int32) ([]GetRecoveriesByLoanIDRow, error) {
+	rows, err := q.db.Query(ctx, getRecoveriesByLoanID, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRecoveriesByLoanIDRow
+	for rows.Next() {
+		var i GetRecoveriesByLoanIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.Amount,
+			&i.RecoveredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrialBalance = `-- name: GetTrialBalance :many
+SELECT
+    ledger_accounts.code,
+    ledger_accounts.number,
+    ledger_accounts.name,
+    ledger_accounts.type,
+    COALESCE(SUM(journal_postings.debit), 0)::numeric AS debit,
+    COALESCE(SUM(journal_postings.credit), 0)::numeric AS credit
+FROM ledger_accounts
+LEFT JOIN (
+    journal_postings
+    JOIN journal_entries ON journal_entries.id = journal_postings.entry_id AND journal_entries.posted_at <= $1
+) ON journal_postings.account_code = ledger_accounts.code
+GROUP BY ledger_accounts.code, ledger_accounts.number, ledger_accounts.name, ledger_accounts.type
+ORDER BY ledger_accounts.number
+`
+
+type GetTrialBalanceRow struct {
+	Code   string
+	Number string
+	Name   string
+	Type   string
+	Debit  pgtype.Numeric
+	Credit pgtype.Numeric
+}
+
+func (q *Queries) GetTrialBalance(ctx context.Context, postedAt pgtype.Timestamp) ([]GetTrialBalanceRow, error) {
+	rows, err := q.db.Query(ctx, getTrialBalance, postedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTrialBalanceRow
+	for rows.Next() {
+		var i GetTrialBalanceRow
+		if err := rows.Scan(
+			&i.Code,
+			&i.Number,
+			&i.Name,
+			&i.Type,
+			&i.Debit,
+			&i.Credit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnpaidBillingSchedules = `-- name: GetUnpaidBillingSchedules :many
+SELECT id, loan_id, period, amount, due_date, paid, paid_amount, principal_amount, interest_amount, fee_amount, version, superseded_at, original_due_date, deferred_periods, waived_interest, accrued_interest
+FROM billing_schedule
+WHERE loan_id = $1 AND paid = false AND superseded_at IS NULL
+ORDER BY period
+`
+
+func (q *Queries) GetUnpaidBillingSchedules(ctx context.Context, loanID int32) ([]BillingSchedule, error) {
+	rows, err := q.db.Query(ctx, getUnpaidBillingSchedules, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BillingSchedule
+	for rows.Next() {
+		var i BillingSchedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.Period,
+			&i.Amount,
+			&i.DueDate,
+			&i.Paid,
+			&i.PaidAmount,
+			&i.PrincipalAmount,
+			&i.InterestAmount,
+			&i.FeeAmount,
+			&i.Version,
+			&i.SupersededAt,
+			&i.OriginalDueDate,
+			&i.DeferredPeriods,
+			&i.WaivedInterest,
+			&i.AccruedInterest,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBorrowers = `-- name: ListBorrowers :many
+SELECT id, createdat, updatedat, deletedat, name, email, phone
+FROM borrowers
+WHERE deletedat IS NULL
+ORDER BY id
+LIMIT $1 OFFSET $2
+`
+
+type ListBorrowersParams struct {
+	Limit  int32
+	Offset int32
+}
+
+func (q *Queries) ListBorrowers(ctx context.Context, arg ListBorrowersParams) ([]Borrower, error) {
+	rows, err := q.db.Query(ctx, listBorrowers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Borrower
+	for rows.Next() {
+		var i Borrower
+		if err := rows.Scan(
+			&i.ID,
+			&i.Createdat,
+			&i.Updatedat,
+			&i.Deletedat,
+			&i.Name,
+			&i.Email,
+			&i.Phone,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listHolidaysByRegion = `-- name: ListHolidaysByRegion :many
+SELECT id, createdat, updatedat, deletedat, region, date, name
+FROM holidays
+WHERE region = $1 AND deletedat IS NULL
+ORDER BY date
+`
+
+func (q *Queries) ListHolidaysByRegion(ctx context.Context, region string) ([]Holiday, error) {
+	rows, err := q.db.Query(ctx, listHolidaysByRegion, region)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Holiday
+	for rows.Next() {
+		var i Holiday
+		if err := rows.Scan(
+			&i.ID,
+			&i.Createdat,
+			&i.Updatedat,
+			&i.Deletedat,
+			&i.Region,
+			&i.Date,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLoanIDsByStatus = `-- name: ListLoanIDsByStatus :many
+SELECT id
+FROM loans
+WHERE status = ANY($1::varchar[]) AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListLoanIDsByStatusParams struct {
+	Statuses  []string
+	AfterID   int32
+	BatchSize int32
+}
+
+func (q *Queries) ListLoanIDsByStatus(ctx context.Context, arg ListLoanIDsByStatusParams) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listLoanIDsByStatus, arg.Statuses, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLoanProducts = `-- name: ListLoanProducts :many
+SELECT id, createdat, updatedat, deletedat, name, min_amount, max_amount, interest_rate, interest_method, tenor_periods, repayment_frequency, installment_fee, origination_fee_rate, active
+FROM loan_products
+ORDER BY id
+`
+
+func (q *Queries) ListLoanProducts(ctx context.Context) ([]LoanProduct, error) {
+	rows, err := q.db.Query(ctx, listLoanProducts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoanProduct
+	for rows.Next() {
+		var i LoanProduct
+		if err := rows.Scan(
+			&i.ID,
+			&i.Createdat,
+			&i.Updatedat,
+			&i.Deletedat,
+			&i.Name,
+			&i.MinAmount,
+			&i.MaxAmount,
+			&i.InterestRate,
+			&i.InterestMethod,
+			&i.TenorPeriods,
+			&i.RepaymentFrequency,
+			&i.InstallmentFee,
+			&i.OriginationFeeRate,
+			&i.Active,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockBorrowerForShare = `-- name: LockBorrowerForShare :one
+SELECT id
+FROM borrowers
+WHERE id = $1 AND deletedat IS NULL
+FOR SHARE
+`
+
+func (q *Queries) LockBorrowerForShare(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, lockBorrowerForShare, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const lockBorrowerForUpdate = `-- name: LockBorrowerForUpdate :one
+SELECT id
+FROM borrowers
+WHERE id = $1 AND deletedat IS NULL
+FOR UPDATE
+`
+
+func (q *Queries) LockBorrowerForUpdate(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRow(ctx, lockBorrowerForUpdate, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const reversePayment = `-- name: ReversePayment :execrows
+UPDATE payments
+SET reversed_at = $1, reversal_reason = $2, updatedat = now()
+WHERE id = $3 AND loan_id = $4 AND reversed_at IS NULL
+`
+
+type ReversePaymentParams struct {
+	ReversedAt     pgtype.Timestamp
+	ReversalReason string
+	ID             int32
+	LoanID         int32
+}
+
+func (q *Queries) ReversePayment(ctx context.Context, arg ReversePaymentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reversePayment,
+		arg.ReversedAt,
+		arg.ReversalReason,
+		arg.ID,
+		arg.LoanID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setLoanProductActive = `-- name: SetLoanProductActive :execrows
+UPDATE loan_products
+SET active = $1, updatedat = now()
+WHERE id = $2
+`
+
+type SetLoanProductActiveParams struct {
+	Active bool
+	ID     int32
+}
+
+func (q *Queries) SetLoanProductActive(ctx context.Context, arg SetLoanProductActiveParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setLoanProductActive, arg.Active, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteBorrower = `-- name: SoftDeleteBorrower :execrows
+UPDATE borrowers
+SET deletedat = now(), updatedat = now()
+WHERE id = $1 AND deletedat IS NULL
+`
+
+func (q *Queries) SoftDeleteBorrower(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteBorrower, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const supersedeBillingSchedules = `-- name: SupersedeBillingSchedules :execrows
+UPDATE billing_schedule
+SET superseded_at = $1
+WHERE loan_id = $2 AND paid = false AND superseded_at IS NULL
+`
+
+type SupersedeBillingSchedulesParams struct {
+	SupersededAt pgtype.Timestamp
+	LoanID       int32
+}
+
+func (q *Queries) SupersedeBillingSchedules(ctx context.Context, arg SupersedeBillingSchedulesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, supersedeBillingSchedules, arg.SupersededAt, arg.LoanID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateBillingSchedule = `-- name: UpdateBillingSchedule :exec
+UPDATE billing_schedule
+SET paid = $1, paid_amount = $2, waived_interest = $3
+WHERE loan_id = $4 AND period = $5 AND superseded_at IS NULL
+`
+
+type UpdateBillingScheduleParams struct {
+	Paid           pgtype.Bool
+	PaidAmount     pgtype.Numeric
+	WaivedInterest pgtype.Numeric
+	LoanID         int32
+	Period         int32
+}
+
+func (q *Queries) UpdateBillingSchedule(ctx context.Context, arg UpdateBillingScheduleParams) error {
+	_, err := q.db.Exec(ctx, updateBillingSchedule,
+		arg.Paid,
+		arg.PaidAmount,
+		arg.WaivedInterest,
+		arg.LoanID,
+		arg.Period,
+	)
+	return err
+}
+
+const updateBillingScheduleAccruedInterest = `-- name: UpdateBillingScheduleAccruedInterest :exec
+UPDATE billing_schedule
+SET accrued_interest = $1
+WHERE id = $2
+`
+
+type UpdateBillingScheduleAccruedInterestParams struct {
+	AccruedInterest pgtype.Numeric
+	ID              int32
+}
+
+func (q *Queries) UpdateBillingScheduleAccruedInterest(ctx context.Context, arg UpdateBillingScheduleAccruedInterestParams) error {
+	_, err := q.db.Exec(ctx, updateBillingScheduleAccruedInterest, arg.AccruedInterest, arg.ID)
+	return err
+}
+
+const updateBorrower = `-- name: UpdateBorrower :one
+UPDATE borrowers
+SET name = COALESCE($1, name),
+    email = COALESCE($2, email),
+    phone = COALESCE($3, phone),
+    updatedat = now()
+WHERE id = $4 AND deletedat IS NULL
+RETURNING id, createdat, updatedat, deletedat, name, email, phone
+`
+
+type UpdateBorrowerParams struct {
+	Name  pgtype.Text
+	Email pgtype.Text
+	Phone pgtype.Text
+	ID    int32
+}
+
+func (q *Queries) UpdateBorrower(ctx context.Context, arg UpdateBorrowerParams) (Borrower, error) {
+	row := q.db.QueryRow(ctx, updateBorrower,
+		arg.Name,
+		arg.Email,
+		arg.Phone,
+		arg.ID,
+	)
+	var i Borrower
+	err := row.Scan(
+		&i.ID,
+		&i.Createdat,
+		&i.Updatedat,
+		&i.Deletedat,
+		&i.Name,
+		&i.Email,
+		&i.Phone,
+	)
+	return i, err
+}
+
+const updateLateFeePaidAmount = `-- name: UpdateLateFeePaidAmount :exec
+UPDATE late_fees
+SET paid_amount = $1, updatedat = now()
+WHERE id = $2
+`
+
+type UpdateLateFeePaidAmountParams struct {
+	PaidAmount pgtype.Numeric
+	ID         int32
+}
+
+func (q *Queries) UpdateLateFeePaidAmount(ctx context.Context, arg UpdateLateFeePaidAmountParams) error {
+	_, err := q.db.Exec(ctx, updateLateFeePaidAmount, arg.PaidAmount, arg.ID)
+	return err
+}
+
+const updateLoan = `-- name: UpdateLoan :exec
+UPDATE loans
+SET amount = $1, interest_rate = $2, tenor_periods = $3, outstanding = $4, delinquent_weeks = $5
+WHERE id = $6
+`
+
+type UpdateLoanParams struct {
+	Amount          pgtype.Numeric
+	InterestRate    pgtype.Numeric
+	TenorPeriods    int32
+	Outstanding     pgtype.Numeric
+	DelinquentWeeks int32
+	ID              int32
+}
+
+func (q *Queries) UpdateLoan(ctx context.Context, arg UpdateLoanParams) error {
+	_, err := q.db.Exec(ctx, updateLoan,
+		arg.Amount,
+		arg.InterestRate,
+		arg.TenorPeriods,
+		arg.Outstanding,
+		arg.DelinquentWeeks,
+		arg.ID,
+	)
+	return err
+}
+
+const updateLoanAccruedInterest = `-- name: UpdateLoanAccruedInterest :exec
+UPDATE loans
+SET accrued_interest = $1, updatedat = now()
+WHERE id = $2
+`
+
+type UpdateLoanAccruedInterestParams struct {
+	AccruedInterest pgtype.Numeric
+	ID              int32
+}
+
+func (q *Queries) UpdateLoanAccruedInterest(ctx context.Context, arg UpdateLoanAccruedInterestParams) error {
+	_, err := q.db.Exec(ctx, updateLoanAccruedInterest, arg.AccruedInterest, arg.ID)
+	return err
+}
+
+const updateLoanDelinquentWeeks = `-- name: UpdateLoanDelinquentWeeks :exec
+UPDATE loans
+SET delinquent_weeks = $1, updatedat = now()
+WHERE id = $2
+`
+
+type UpdateLoanDelinquentWeeksParams struct {
+	DelinquentWeeks int32
+	ID              int32
+}
+
+func (q *Queries) UpdateLoanDelinquentWeeks(ctx context.Context, arg UpdateLoanDelinquentWeeksParams) error {
+	_, err := q.db.Exec(ctx, updateLoanDelinquentWeeks, arg.DelinquentWeeks, arg.ID)
+	return err
+}
+
+const updateLoanStatus = `-- name: UpdateLoanStatus :execrows
+UPDATE loans
+SET status = $1, closedat = $2, updatedat = now()
+WHERE id = $3 AND status = $4
+`
+
+type UpdateLoanStatusParams struct {
+	ToStatus   string
+	ClosedAt   pgtype.Timestamp
+	ID         int32
+	FromStatus string
+}
+
+func (q *Queries) UpdateLoanStatus(ctx context.Context, arg UpdateLoanStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateLoanStatus,
+		arg.ToStatus,
+		arg.ClosedAt,
+		arg.ID,
+		arg.FromStatus,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateLoanTerms = `-- name: UpdateLoanTerms :exec
+UPDATE loans
+SET interest_rate = $1, interest_method = $2, tenor_periods = $3, repayment_frequency = $4, outstanding = $5,
+    installment_amount = $6, delinquent_weeks = $7, updatedat = now()
+WHERE id = $8
+`
+
+type UpdateLoanTermsParams struct {
+	InterestRate       pgtype.Numeric
+	InterestMethod     string
+	TenorPeriods       int32
+	RepaymentFrequency string
+	Outstanding        pgtype.Numeric
+	InstallmentAmount  pgtype.Numeric
+	DelinquentWeeks    int32
+	ID                 int32
+}
+
+func (q *Queries) UpdateLoanTerms(ctx context.Context, arg UpdateLoanTermsParams) error {
+	_, err := q.db.Exec(ctx, updateLoanTerms,
+		arg.InterestRate,
+		arg.InterestMethod,
+		arg.TenorPeriods,
+		arg.RepaymentFrequency,
+		arg.Outstanding,
+		arg.InstallmentAmount,
+		arg.DelinquentWeeks,
+		arg.ID,
+	)
+	return err
+}
+
+const updateLoanWriteOffRecoveredAmount = `-- name: UpdateLoanWriteOffRecoveredAmount :exec
+UPDATE loan_write_offs
+SET recovered_amount = $1, updatedat = now()
+WHERE id = $2
+`
+
+type UpdateLoanWriteOffRecoveredAmountParams struct {
+	RecoveredAmount pgtype.Numeric
+	ID              int32
+}
+
+func (q *Queries) UpdateLoanWriteOffRecoveredAmount(ctx context.Context, arg UpdateLoanWriteOffRecoveredAmountParams) error {
+	_, err := q.db.Exec(ctx, updateLoanWriteOffRecoveredAmount, arg.RecoveredAmount, arg.ID)
+	return err
+}
+
+const upsertInterestAccrual = `-- name: UpsertInterestAccrual :one
+INSERT INTO interest_accruals (loan_id, accrual_date, method, amount, accrued_to_date)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (loan_id, accrual_date) DO UPDATE
+SET method = EXCLUDED.method, amount = interest_accruals.amount + EXCLUDED.amount,
+    accrued_to_date = EXCLUDED.accrued_to_date, updatedat = now()
+RETURNING id, amount
+`
+
+type UpsertInterestAccrualParams struct {
+	LoanID        int32
+	AccrualDate   pgtype.Date
+	Method        string
+	Amount        pgtype.Numeric
+	AccruedToDate pgtype.Numeric
+}
+
+type UpsertInterestAccrualRow struct {
+	ID     int32
+	Amount pgtype.Numeric
+}
+
+func (q *Queries) UpsertInterestAccrual(ctx context.Context, arg UpsertInterestAccrualParams) (UpsertInterestAccrualRow, error) {
+	row := q.db.QueryRow(ctx, upsertInterestAccrual,
+		arg.LoanID,
+		arg.AccrualDate,
+		arg.Method,
+		arg.Amount,
+		arg.AccruedToDate,
+	)
+	var i UpsertInterestAccrualRow
+	err := row.Scan(&i.ID, &i.Amount)
+	return i, err
+}
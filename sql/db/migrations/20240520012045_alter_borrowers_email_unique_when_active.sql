@@ -0,0 +1,9 @@
+-- migrate:up
+-- the email of a deleted borrower can be registered again
+ALTER TABLE borrowers DROP CONSTRAINT borrowers_email_key;
+CREATE UNIQUE INDEX idx_borrowers_email ON borrowers(email) WHERE deletedat IS NULL;
+
+-- migrate:down
+-- fails while a deleted borrower shares its email with another borrower
+DROP INDEX idx_borrowers_email;
+ALTER TABLE borrowers ADD CONSTRAINT borrowers_email_key UNIQUE (email);
@@ -0,0 +1,27 @@
+-- migrate:up
+CREATE TABLE payments (
+    LIKE template_table INCLUDING ALL,
+    loan_id INT NOT NULL,
+    amount NUMERIC(15, 2) NOT NULL,
+    paid_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_loan
+        FOREIGN KEY(loan_id)
+        REFERENCES loans(id)
+);
+
+CREATE INDEX idx_payments_loan_id ON payments(loan_id);
+
+CREATE TABLE payment_allocations (
+    id SERIAL PRIMARY KEY,
+    payment_id INT NOT NULL,
+    billing_schedule_id INT NOT NULL,
+    amount NUMERIC(15, 2) NOT NULL,
+    FOREIGN KEY (payment_id) REFERENCES payments(id),
+    FOREIGN KEY (billing_schedule_id) REFERENCES billing_schedule(id)
+);
+
+CREATE INDEX idx_payment_allocations_payment_id ON payment_allocations(payment_id);
+
+-- migrate:down
+DROP TABLE payment_allocations;
+DROP TABLE payments;
@@ -0,0 +1,15 @@
+-- migrate:up
+ALTER TABLE billing_schedule
+ADD COLUMN paid_amount NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+UPDATE billing_schedule SET paid_amount = amount WHERE paid = true;
+
+ALTER TABLE payment_allocations
+ADD COLUMN remaining_amount NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+-- migrate:down
+ALTER TABLE payment_allocations
+DROP COLUMN remaining_amount;
+
+ALTER TABLE billing_schedule
+DROP COLUMN paid_amount;
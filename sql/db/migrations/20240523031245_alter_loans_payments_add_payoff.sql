@@ -0,0 +1,13 @@
+-- migrate:up
+ALTER TABLE loans
+ADD COLUMN closedat TIMESTAMP;
+
+ALTER TABLE payments
+ADD COLUMN rebate_amount NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+-- migrate:down
+ALTER TABLE payments
+DROP COLUMN rebate_amount;
+
+ALTER TABLE loans
+DROP COLUMN closedat;
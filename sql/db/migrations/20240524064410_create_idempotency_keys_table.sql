@@ -0,0 +1,13 @@
+-- migrate:up
+CREATE TABLE idempotency_keys (
+    -- the Idempotency-Key header prefixed by the method and path it was sent to
+    key TEXT PRIMARY KEY,
+    request_hash CHAR(64) NOT NULL,
+    response_status INT,
+    response_body BYTEA,
+    createdat TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completedat TIMESTAMP
+);
+
+-- migrate:down
+DROP TABLE idempotency_keys;
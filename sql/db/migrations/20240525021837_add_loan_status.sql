@@ -0,0 +1,30 @@
+-- migrate:up
+ALTER TABLE loans
+ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'approved', 'active', 'delinquent', 'paid_off', 'written_off', 'cancelled'));
+
+-- loans booked before the lifecycle existed were disbursed on creation
+UPDATE loans
+SET status = CASE WHEN closedat IS NOT NULL OR outstanding <= 0 THEN 'paid_off' ELSE 'active' END,
+    closedat = CASE WHEN closedat IS NULL AND outstanding <= 0 THEN updatedat ELSE closedat END;
+
+CREATE TABLE loan_status_history (
+    LIKE template_table INCLUDING ALL,
+    loan_id INT NOT NULL,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    transitioned_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_loan
+        FOREIGN KEY(loan_id)
+        REFERENCES loans(id)
+);
+
+CREATE INDEX idx_loan_status_history_loan_id ON loan_status_history(loan_id);
+
+-- migrate:down
+DROP TABLE loan_status_history;
+
+ALTER TABLE loans
+DROP COLUMN status;
@@ -0,0 +1,27 @@
+-- migrate:up
+CREATE TABLE late_fees (
+    LIKE template_table INCLUDING ALL,
+    loan_id INT NOT NULL,
+    billing_schedule_id INT NOT NULL UNIQUE,
+    amount NUMERIC(15, 2) NOT NULL,
+    paid_amount NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    charged_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_loan
+        FOREIGN KEY(loan_id)
+        REFERENCES loans(id),
+    CONSTRAINT fk_billing_schedule
+        FOREIGN KEY(billing_schedule_id)
+        REFERENCES billing_schedule(id)
+);
+
+CREATE INDEX idx_late_fees_loan_id ON late_fees(loan_id);
+
+-- an allocation with a late fee paid that fee, billing_schedule_id is the week it was charged for
+ALTER TABLE payment_allocations
+ADD COLUMN late_fee_id INT REFERENCES late_fees(id);
+
+-- migrate:down
+ALTER TABLE payment_allocations
+DROP COLUMN late_fee_id;
+
+DROP TABLE late_fees;
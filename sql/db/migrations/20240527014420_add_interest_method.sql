@@ -0,0 +1,30 @@
+-- migrate:up
+-- rates are stored as a fraction, the extra decimals allow rates such as 12.75%
+ALTER TABLE loans
+ALTER COLUMN interest_rate TYPE NUMERIC(9, 6);
+
+ALTER TABLE loans
+ADD COLUMN interest_method VARCHAR(20) NOT NULL DEFAULT 'flat'
+    CHECK (interest_method IN ('flat', 'annuity', 'equal_principal'));
+
+ALTER TABLE billing_schedule
+ADD COLUMN principal_amount NUMERIC(15, 2) NOT NULL DEFAULT 0,
+ADD COLUMN interest_amount NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+-- every existing loan has flat interest, so each installment carries the same share of interest: rate / (1 + rate)
+UPDATE billing_schedule
+SET interest_amount = ROUND(billing_schedule.amount * loans.interest_rate / (1 + loans.interest_rate), 2),
+    principal_amount = billing_schedule.amount - ROUND(billing_schedule.amount * loans.interest_rate / (1 + loans.interest_rate), 2)
+FROM loans
+WHERE loans.id = billing_schedule.loan_id;
+
+-- migrate:down
+ALTER TABLE billing_schedule
+DROP COLUMN principal_amount,
+DROP COLUMN interest_amount;
+
+ALTER TABLE loans
+DROP COLUMN interest_method;
+
+ALTER TABLE loans
+ALTER COLUMN interest_rate TYPE NUMERIC(5, 2);
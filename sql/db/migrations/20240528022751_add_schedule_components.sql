@@ -0,0 +1,45 @@
+-- migrate:up
+ALTER TABLE billing_schedule
+ADD COLUMN fee_amount NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE payment_allocations
+ADD COLUMN principal_amount NUMERIC(15, 2) NOT NULL DEFAULT 0,
+ADD COLUMN interest_amount NUMERIC(15, 2) NOT NULL DEFAULT 0,
+ADD COLUMN fee_amount NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+UPDATE payment_allocations
+SET fee_amount = amount
+WHERE late_fee_id IS NOT NULL;
+
+-- installment payments settled the interest of their week before its principal
+UPDATE payment_allocations
+SET interest_amount = settled.interest_amount,
+    principal_amount = payment_allocations.amount - settled.interest_amount
+FROM (
+    SELECT
+        paid.id,
+        LEAST(paid.paid_after, paid.week_interest) - LEAST(paid.paid_after - paid.amount, paid.week_interest) AS interest_amount
+    FROM (
+        SELECT
+            payment_allocations.id,
+            payment_allocations.amount,
+            billing_schedule.interest_amount AS week_interest,
+            SUM(payment_allocations.amount) OVER (
+                PARTITION BY payment_allocations.billing_schedule_id
+                ORDER BY payment_allocations.id
+            ) AS paid_after
+        FROM payment_allocations
+        JOIN billing_schedule ON billing_schedule.id = payment_allocations.billing_schedule_id
+        WHERE payment_allocations.late_fee_id IS NULL
+    ) AS paid
+) AS settled
+WHERE settled.id = payment_allocations.id;
+
+-- migrate:down
+ALTER TABLE payment_allocations
+DROP COLUMN principal_amount,
+DROP COLUMN interest_amount,
+DROP COLUMN fee_amount;
+
+ALTER TABLE billing_schedule
+DROP COLUMN fee_amount;
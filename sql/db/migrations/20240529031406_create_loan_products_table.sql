@@ -0,0 +1,27 @@
+-- migrate:up
+CREATE TABLE loan_products (
+    LIKE template_table INCLUDING ALL,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    min_amount NUMERIC(15, 2) NOT NULL,
+    max_amount NUMERIC(15, 2) NOT NULL,
+    interest_rate NUMERIC(9, 6) NOT NULL,
+    interest_method VARCHAR(20) NOT NULL DEFAULT 'flat'
+        CHECK (interest_method IN ('flat', 'annuity', 'equal_principal')),
+    tenor_weeks INT[] NOT NULL,
+    repayment_frequency VARCHAR(20) NOT NULL DEFAULT 'weekly'
+        CHECK (repayment_frequency IN ('weekly')),
+    installment_fee NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    origination_fee_rate NUMERIC(9, 6) NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    CHECK (min_amount > 0 AND max_amount >= min_amount)
+);
+
+-- loans booked before the catalogue existed keep a NULL product
+ALTER TABLE loans
+ADD COLUMN product_id INT REFERENCES loan_products(id);
+
+-- migrate:down
+ALTER TABLE loans
+DROP COLUMN product_id;
+
+DROP TABLE loan_products;
@@ -0,0 +1,38 @@
+-- migrate:up
+-- installments are no longer always weekly, tenors and installment numbers count repayment periods
+ALTER TABLE loans
+RENAME COLUMN duration_weeks TO tenor_periods;
+
+ALTER TABLE loans
+ADD COLUMN repayment_frequency VARCHAR(20) NOT NULL DEFAULT 'weekly'
+    CHECK (repayment_frequency IN ('daily', 'weekly', 'bi_weekly', 'semi_monthly', 'monthly'));
+
+ALTER TABLE billing_schedule
+RENAME COLUMN week TO period;
+
+ALTER TABLE loan_products
+RENAME COLUMN tenor_weeks TO tenor_periods;
+
+ALTER TABLE loan_products
+DROP CONSTRAINT loan_products_repayment_frequency_check,
+ADD CONSTRAINT loan_products_repayment_frequency_check
+    CHECK (repayment_frequency IN ('daily', 'weekly', 'bi_weekly', 'semi_monthly', 'monthly'));
+
+-- migrate:down
+-- fails while products with other frequencies exist, their loans cannot be described in weeks
+ALTER TABLE loan_products
+DROP CONSTRAINT loan_products_repayment_frequency_check,
+ADD CONSTRAINT loan_products_repayment_frequency_check
+    CHECK (repayment_frequency IN ('weekly'));
+
+ALTER TABLE loan_products
+RENAME COLUMN tenor_periods TO tenor_weeks;
+
+ALTER TABLE billing_schedule
+RENAME COLUMN period TO week;
+
+ALTER TABLE loans
+DROP COLUMN repayment_frequency;
+
+ALTER TABLE loans
+RENAME COLUMN tenor_periods TO duration_weeks;
@@ -0,0 +1,11 @@
+-- migrate:up
+CREATE TABLE holidays (
+    LIKE template_table INCLUDING ALL,
+    region VARCHAR(10) NOT NULL,
+    date DATE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    UNIQUE (region, date)
+);
+
+-- migrate:down
+DROP TABLE holidays;
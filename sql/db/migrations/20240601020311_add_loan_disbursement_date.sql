@@ -0,0 +1,13 @@
+-- migrate:up
+ALTER TABLE loans
+ADD COLUMN disbursement_date DATE;
+
+-- schedules used to start when the loan was created
+UPDATE loans SET disbursement_date = createdat::date;
+
+ALTER TABLE loans
+ALTER COLUMN disbursement_date SET NOT NULL;
+
+-- migrate:down
+ALTER TABLE loans
+DROP COLUMN disbursement_date;
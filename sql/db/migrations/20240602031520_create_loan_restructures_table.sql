@@ -0,0 +1,38 @@
+-- migrate:up
+-- a restructure supersedes the unpaid rows of the current schedule version with a new version,
+-- superseded rows are kept for audit and no longer count towards the balance or the arrears
+ALTER TABLE billing_schedule
+ADD COLUMN version INT NOT NULL DEFAULT 1,
+ADD COLUMN superseded_at TIMESTAMP;
+
+CREATE INDEX idx_billing_schedule_loan_id_active ON billing_schedule(loan_id, period) WHERE superseded_at IS NULL;
+
+CREATE TABLE loan_restructures (
+    LIKE template_table INCLUDING ALL,
+    loan_id INT NOT NULL,
+    from_version INT NOT NULL,
+    to_version INT NOT NULL,
+    outstanding_before NUMERIC(15, 2) NOT NULL,
+    principal NUMERIC(15, 2) NOT NULL,
+    outstanding_after NUMERIC(15, 2) NOT NULL,
+    interest_rate NUMERIC(9, 6) NOT NULL,
+    interest_method VARCHAR(20) NOT NULL,
+    tenor_periods INT NOT NULL,
+    repayment_frequency VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    restructured_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_loan
+        FOREIGN KEY(loan_id)
+        REFERENCES loans(id)
+);
+
+CREATE INDEX idx_loan_restructures_loan_id ON loan_restructures(loan_id);
+
+-- migrate:down
+DROP TABLE loan_restructures;
+
+DROP INDEX idx_billing_schedule_loan_id_active;
+
+ALTER TABLE billing_schedule
+DROP COLUMN version,
+DROP COLUMN superseded_at;
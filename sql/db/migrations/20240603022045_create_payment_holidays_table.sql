@@ -0,0 +1,29 @@
+-- migrate:up
+-- a payment holiday moves the upcoming installments and every later one by a number of periods,
+-- original_due_date keeps the due date of the schedule before its first deferral
+ALTER TABLE billing_schedule
+ADD COLUMN original_due_date DATE,
+ADD COLUMN deferred_periods INT NOT NULL DEFAULT 0;
+
+CREATE TABLE payment_holidays (
+    LIKE template_table INCLUDING ALL,
+    loan_id INT NOT NULL,
+    from_period INT NOT NULL,
+    periods INT NOT NULL CHECK (periods > 0),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    capitalised_interest NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    reason TEXT NOT NULL DEFAULT '',
+    CONSTRAINT fk_loan
+        FOREIGN KEY(loan_id)
+        REFERENCES loans(id)
+);
+
+CREATE INDEX idx_payment_holidays_loan_id ON payment_holidays(loan_id);
+
+-- migrate:down
+DROP TABLE payment_holidays;
+
+ALTER TABLE billing_schedule
+DROP COLUMN original_due_date,
+DROP COLUMN deferred_periods;
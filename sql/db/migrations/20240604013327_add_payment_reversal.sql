@@ -0,0 +1,10 @@
+-- migrate:up
+-- a reversed payment is kept with its allocations, the amounts it settled are owed again
+ALTER TABLE payments
+ADD COLUMN reversed_at TIMESTAMP,
+ADD COLUMN reversal_reason TEXT NOT NULL DEFAULT '';
+
+-- migrate:down
+ALTER TABLE payments
+DROP COLUMN reversed_at,
+DROP COLUMN reversal_reason;
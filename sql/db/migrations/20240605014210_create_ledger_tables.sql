@@ -0,0 +1,81 @@
+-- migrate:up
+-- the chart of accounts of the loan book, postings refer to accounts by code
+CREATE TABLE ledger_accounts (
+    LIKE template_table INCLUDING ALL,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    number VARCHAR(8) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('asset', 'liability', 'income', 'expense'))
+);
+
+INSERT INTO ledger_accounts (code, number, name, type) VALUES
+    ('cash', '1000', 'Cash', 'asset'),
+    ('loans_receivable', '1100', 'Loans receivable', 'asset'),
+    ('interest_receivable', '1200', 'Interest receivable', 'asset'),
+    ('fees_receivable', '1300', 'Fees receivable', 'asset'),
+    ('unearned_income', '2100', 'Unearned interest and fees', 'liability'),
+    ('interest_income', '4000', 'Interest income', 'income'),
+    ('fee_income', '4100', 'Fee income', 'income'),
+    ('write_off_expense', '5000', 'Loan write-offs', 'expense');
+
+CREATE TABLE journal_entries (
+    LIKE template_table INCLUDING ALL,
+    loan_id INT NOT NULL,
+    payment_id INT,
+    kind VARCHAR(32) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    posted_at TIMESTAMP NOT NULL,
+    reverses_entry_id INT,
+    CONSTRAINT fk_loan
+        FOREIGN KEY(loan_id)
+        REFERENCES loans(id),
+    CONSTRAINT fk_payment
+        FOREIGN KEY(payment_id)
+        REFERENCES payments(id),
+    CONSTRAINT fk_reverses_entry
+        FOREIGN KEY(reverses_entry_id)
+        REFERENCES journal_entries(id)
+);
+
+CREATE INDEX idx_journal_entries_loan_id ON journal_entries(loan_id);
+CREATE INDEX idx_journal_entries_posted_at ON journal_entries(posted_at);
+
+-- a posting is either a debit or a credit
+CREATE TABLE journal_postings (
+    LIKE template_table INCLUDING ALL,
+    entry_id INT NOT NULL,
+    account_code VARCHAR(32) NOT NULL,
+    debit NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    credit NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    CHECK (debit >= 0 AND credit >= 0 AND (debit = 0) <> (credit = 0)),
+    CONSTRAINT fk_entry
+        FOREIGN KEY(entry_id)
+        REFERENCES journal_entries(id),
+    CONSTRAINT fk_account
+        FOREIGN KEY(account_code)
+        REFERENCES ledger_accounts(code)
+);
+
+CREATE INDEX idx_journal_postings_entry_id ON journal_postings(entry_id);
+
+-- the postings of an entry must balance once the transaction writing them commits
+CREATE FUNCTION check_journal_entry_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF (SELECT SUM(debit) - SUM(credit) FROM journal_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'journal entry % does not balance', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER journal_entry_balanced
+AFTER INSERT OR UPDATE ON journal_postings
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION check_journal_entry_balanced();
+
+-- migrate:down
+DROP TRIGGER journal_entry_balanced ON journal_postings;
+DROP FUNCTION check_journal_entry_balanced();
+DROP TABLE journal_postings;
+DROP TABLE journal_entries;
+DROP TABLE ledger_accounts;
@@ -0,0 +1,19 @@
+-- migrate:up
+-- the unearned interest an early payoff waived rather than collected, per schedule row and per allocation
+ALTER TABLE billing_schedule
+ADD COLUMN waived_interest NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE payment_allocations
+ADD COLUMN interest_waived NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+-- rows settled by an earlier payoff were marked paid with the rebate left unpaid
+UPDATE billing_schedule
+SET waived_interest = LEAST(amount - paid_amount, interest_amount)
+WHERE paid AND paid_amount < amount;
+
+-- migrate:down
+ALTER TABLE payment_allocations
+DROP COLUMN interest_waived;
+
+ALTER TABLE billing_schedule
+DROP COLUMN waived_interest;
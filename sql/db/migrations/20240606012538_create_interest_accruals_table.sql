@@ -0,0 +1,32 @@
+-- migrate:up
+-- the interest recognised so far on every installment and, summed over the current ones, on the loan
+ALTER TABLE billing_schedule
+ADD COLUMN accrued_interest NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+ALTER TABLE loans
+ADD COLUMN accrued_interest NUMERIC(15, 2) NOT NULL DEFAULT 0;
+
+-- one row per loan and day the accrual job recognised interest on, amount is what that day added
+CREATE TABLE interest_accruals (
+    LIKE template_table INCLUDING ALL,
+    loan_id INT NOT NULL,
+    accrual_date DATE NOT NULL,
+    method VARCHAR(32) NOT NULL,
+    amount NUMERIC(15, 2) NOT NULL,
+    accrued_to_date NUMERIC(15, 2) NOT NULL,
+    UNIQUE (loan_id, accrual_date),
+    CONSTRAINT fk_loan
+        FOREIGN KEY(loan_id)
+        REFERENCES loans(id)
+);
+
+CREATE INDEX idx_interest_accruals_accrual_date ON interest_accruals(accrual_date);
+
+-- migrate:down
+DROP TABLE interest_accruals;
+
+ALTER TABLE loans
+DROP COLUMN accrued_interest;
+
+ALTER TABLE billing_schedule
+DROP COLUMN accrued_interest;
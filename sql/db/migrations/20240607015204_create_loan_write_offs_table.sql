@@ -0,0 +1,60 @@
+-- migrate:up
+-- what was left of a loan when it was written off, recovered_amount sums the recoveries collected on it since
+CREATE TABLE loan_write_offs (
+    LIKE template_table INCLUDING ALL,
+    loan_id INT NOT NULL UNIQUE,
+    principal NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    interest NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    fee NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    late_fees NUMERIC(15, 2) NOT NULL DEFAULT 0,
+    amount NUMERIC(15, 2) NOT NULL,
+    recovered_amount NUMERIC(15, 2) NOT NULL DEFAULT 0 CHECK (recovered_amount <= amount),
+    reason TEXT NOT NULL DEFAULT '',
+    automatic BOOLEAN NOT NULL DEFAULT false,
+    written_off_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_loan
+        FOREIGN KEY(loan_id)
+        REFERENCES loans(id)
+);
+
+CREATE INDEX idx_loan_write_offs_written_off_at ON loan_write_offs(written_off_at);
+
+-- money collected on a written-off loan, kept apart from the payments settling its schedule
+CREATE TABLE recoveries (
+    LIKE template_table INCLUDING ALL,
+    loan_id INT NOT NULL,
+    amount NUMERIC(15, 2) NOT NULL CHECK (amount > 0),
+    recovered_at TIMESTAMP NOT NULL,
+    CONSTRAINT fk_loan
+        FOREIGN KEY(loan_id)
+        REFERENCES loans(id)
+);
+
+CREATE INDEX idx_recoveries_loan_id ON recoveries(loan_id);
+CREATE INDEX idx_recoveries_recovered_at ON recoveries(recovered_at);
+
+INSERT INTO ledger_accounts (code, number, name, type) VALUES
+    ('recovery_income', '4200', 'Recoveries on written-off loans', 'income');
+
+-- loans written off before write-offs were recorded keep their outstanding as the amount written off,
+-- its breakdown is unknown
+INSERT INTO loan_write_offs (loan_id, amount, written_off_at)
+SELECT id, outstanding, COALESCE(closedat, updatedat, createdat)
+FROM loans
+WHERE status = 'written_off';
+
+UPDATE loans
+SET outstanding = 0, updatedat = now()
+WHERE status = 'written_off';
+
+-- migrate:down
+UPDATE loans
+SET outstanding = loan_write_offs.amount - loan_write_offs.late_fees, updatedat = now()
+FROM loan_write_offs
+WHERE loans.id = loan_write_offs.loan_id;
+
+DELETE FROM ledger_accounts WHERE code = 'recovery_income';
+
+DROP TABLE recoveries;
+
+DROP TABLE loan_write_offs;
@@ -1,15 +1,39 @@
 -- name: GetLoanByID :one
-SELECT id, amount, interest_rate, duration_weeks, outstanding, delinquent_weeks, installment_amount
+SELECT id, amount, interest_rate, tenor_periods, outstanding, delinquent_weeks, installment_amount, closedat, status, interest_method, product_id, repayment_frequency, disbursement_date, accrued_interest
 FROM loans
 WHERE id = $1;
 
+-- name: GetLoanByIDForUpdate :one
+SELECT id, amount, interest_rate, tenor_periods, outstanding, delinquent_weeks, installment_amount, closedat, status, interest_method, product_id, repayment_frequency, disbursement_date, accrued_interest
+FROM loans
+WHERE id = $1
+FOR UPDATE;
+
 -- name: UpdateLoan :exec
 UPDATE loans
-SET amount = $1, interest_rate = $2, duration_weeks = $3, outstanding = $4, delinquent_weeks = $5
+SET amount = $1, interest_rate = $2, tenor_periods = $3, outstanding = $4, delinquent_weeks = $5
 WHERE id = $6;
 
+-- name: UpdateLoanTerms :exec
+UPDATE loans
+SET interest_rate = $1, interest_method = $2, tenor_periods = $3, repayment_frequency = $4, outstanding = $5,
+    installment_amount = $6, delinquent_weeks = $7, updatedat = now()
+WHERE id = $8;
+
+-- name: UpdateLoanDelinquentWeeks :exec
+UPDATE loans
+SET delinquent_weeks = $1, updatedat = now()
+WHERE id = $2;
+
+-- name: ListLoanIDsByStatus :many
+SELECT id
+FROM loans
+WHERE status = ANY(sqlc.arg('statuses')::varchar[]) AND id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('batch_size');
+
 -- name: GetLoansByBorrowerID :many
-SELECT id, amount, interest_rate, duration_weeks, outstanding, delinquent_weeks
+SELECT id, amount, interest_rate, tenor_periods, outstanding, delinquent_weeks, status, interest_method, product_id, repayment_frequency, disbursement_date, accrued_interest
 FROM loans
 WHERE borrower_id = $1;
 
@@ -20,52 +44,374 @@ SELECT
     borrowers.name AS borrower_name, 
     loans.amount, 
     loans.interest_rate, 
-    loans.duration_weeks, 
+    loans.tenor_periods, 
     loans.outstanding, 
     loans.delinquent_weeks,
-    loans.installment_amount
+    loans.installment_amount,
+    loans.status,
+    loans.repayment_frequency
 FROM loans
 JOIN borrowers ON loans.borrower_id = borrowers.id
 LIMIT $1 OFFSET $2;
 
 
 -- name: CreateLoan :one
-INSERT INTO loans (borrower_id, amount, interest_rate, duration_weeks, outstanding, delinquent_weeks, installment_amount)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
+INSERT INTO loans (borrower_id, amount, interest_rate, tenor_periods, outstanding, delinquent_weeks, installment_amount, interest_method, product_id, repayment_frequency, disbursement_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 RETURNING id;
 
 -- name: CreateBillingSchedule :exec
-INSERT INTO billing_schedule (loan_id, week, amount, due_date, paid)
+INSERT INTO billing_schedule (loan_id, period, amount, due_date, paid)
 VALUES ($1, $2, $3, $4, $5);
 
 -- name: CreateBillingSchedules :exec
-INSERT INTO billing_schedule (loan_id, week, amount, due_date, paid)
+INSERT INTO billing_schedule (loan_id, period, amount, due_date, paid, principal_amount, interest_amount, fee_amount, version)
 VALUES (
     unnest($1::int[]),
     unnest($2::int[]),
     unnest($3::numeric[]),
     unnest($4::date[]),
-    unnest($5::boolean[])
+    unnest($5::boolean[]),
+    unnest($6::numeric[]),
+    unnest($7::numeric[]),
+    unnest($8::numeric[]),
+    unnest($9::int[])
 );
 
 
 -- name: UpdateBillingSchedule :exec
 UPDATE billing_schedule
-SET paid = $1
-WHERE loan_id = $2 AND week = $3;
+SET paid = $1, paid_amount = $2, waived_interest = $3
+WHERE loan_id = $4 AND period = $5 AND superseded_at IS NULL;
 
 -- name: GetBillingSchedule :one
-SELECT id, loan_id, week, amount, due_date, paid
+SELECT id, loan_id, period, amount, due_date, paid, paid_amount, principal_amount, interest_amount, fee_amount, version, superseded_at, original_due_date, deferred_periods, waived_interest, accrued_interest
 FROM billing_schedule
-WHERE loan_id = $1 AND paid = false ORDER BY week LIMIT 1;
+WHERE loan_id = $1 AND paid = false AND superseded_at IS NULL ORDER BY period LIMIT 1;
+
+-- name: GetUnpaidBillingSchedules :many
+SELECT id, loan_id, period, amount, due_date, paid, paid_amount, principal_amount, interest_amount, fee_amount, version, superseded_at, original_due_date, deferred_periods, waived_interest, accrued_interest
+FROM billing_schedule
+WHERE loan_id = $1 AND paid = false AND superseded_at IS NULL
+ORDER BY period;
+
+-- name: GetBillingSchedulesByLoanID :many
+SELECT id, loan_id, period, amount, due_date, paid, paid_amount, principal_amount, interest_amount, fee_amount, version, superseded_at, original_due_date, deferred_periods, waived_interest, accrued_interest
+FROM billing_schedule
+WHERE loan_id = $1
+ORDER BY version, period;
+
+-- name: SupersedeBillingSchedules :execrows
+UPDATE billing_schedule
+SET superseded_at = $1
+WHERE loan_id = $2 AND paid = false AND superseded_at IS NULL;
+
+-- name: DeferBillingSchedule :exec
+UPDATE billing_schedule
+SET original_due_date = COALESCE(original_due_date, due_date), due_date = $1, deferred_periods = deferred_periods + $2,
+    interest_amount = $3, amount = $4
+WHERE id = $5 AND paid = false AND superseded_at IS NULL;
 
 -- name: CheckDelinquentAmount :one
-SELECT loan_id, count(1) as total_week, sum(amount)
+SELECT loan_id, count(1) as total_week, sum(amount - paid_amount)::numeric AS amount
 FROM billing_schedule
-WHERE loan_id = $1 AND paid = false AND due_date <  now()
+WHERE loan_id = sqlc.arg('loan_id') AND paid = false AND superseded_at IS NULL AND due_date < sqlc.arg('as_of')::timestamp
 GROUP BY loan_id;
 
--- name: UpdateRepaymentSchedule :exec
+-- name: CreateBorrower :one
+INSERT INTO borrowers (name, email, phone)
+VALUES ($1, $2, $3)
+RETURNING id, createdat, updatedat, deletedat, name, email, phone;
+
+-- name: GetBorrowerByID :one
+SELECT id, createdat, updatedat, deletedat, name, email, phone
+FROM borrowers
+WHERE id = $1 AND deletedat IS NULL;
+
+-- name: ListBorrowers :many
+SELECT id, createdat, updatedat, deletedat, name, email, phone
+FROM borrowers
+WHERE deletedat IS NULL
+ORDER BY id
+LIMIT $1 OFFSET $2;
+
+-- name: UpdateBorrower :one
+UPDATE borrowers
+SET name = COALESCE(sqlc.narg('name'), name),
+    email = COALESCE(sqlc.narg('email'), email),
+    phone = COALESCE(sqlc.narg('phone'), phone),
+    updatedat = now()
+WHERE id = sqlc.arg('id') AND deletedat IS NULL
+RETURNING id, createdat, updatedat, deletedat, name, email, phone;
+
+-- name: LockBorrowerForUpdate :one
+SELECT id
+FROM borrowers
+WHERE id = $1 AND deletedat IS NULL
+FOR UPDATE;
+
+-- name: LockBorrowerForShare :one
+SELECT id
+FROM borrowers
+WHERE id = $1 AND deletedat IS NULL
+FOR SHARE;
+
+-- name: CountOpenLoansByBorrowerID :one
+SELECT count(*)
+FROM loans
+WHERE borrower_id = sqlc.arg('borrower_id') AND status <> ALL(sqlc.arg('closed_statuses')::varchar[]);
+
+-- name: SoftDeleteBorrower :execrows
+UPDATE borrowers
+SET deletedat = now(), updatedat = now()
+WHERE id = $1 AND deletedat IS NULL;
+
+-- name: CreatePayment :one
+INSERT INTO payments (loan_id, amount, rebate_amount, paid_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, paid_at;
+
+-- name: CreatePaymentAllocation :exec
+INSERT INTO payment_allocations (payment_id, billing_schedule_id, amount, remaining_amount, late_fee_id, principal_amount, interest_amount, fee_amount, interest_waived)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetPaymentsByLoanID :many
+SELECT id, loan_id, amount, paid_at, rebate_amount, reversed_at, reversal_reason
+FROM payments
+WHERE loan_id = $1
+ORDER BY paid_at, id;
+
+-- name: ReversePayment :execrows
+UPDATE payments
+SET reversed_at = $1, reversal_reason = $2, updatedat = now()
+WHERE id = $3 AND loan_id = $4 AND reversed_at IS NULL;
+
+-- name: GetPaymentAllocationsByLoanID :many
+SELECT
+    payment_allocations.payment_id,
+    payment_allocations.billing_schedule_id,
+    billing_schedule.period,
+    payment_allocations.amount,
+    payment_allocations.remaining_amount,
+    payment_allocations.late_fee_id,
+    payment_allocations.principal_amount,
+    payment_allocations.interest_amount,
+    payment_allocations.fee_amount,
+    payment_allocations.interest_waived
+FROM payment_allocations
+JOIN payments ON payments.id = payment_allocations.payment_id
+JOIN billing_schedule ON billing_schedule.id = payment_allocations.billing_schedule_id
+WHERE payments.loan_id = $1
+ORDER BY payment_allocations.payment_id, payment_allocations.id;
+
+-- name: CreateLateFee :one
+INSERT INTO late_fees (loan_id, billing_schedule_id, amount, charged_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, charged_at;
+
+-- name: GetLateFeesByLoanID :many
+SELECT
+    late_fees.id,
+    late_fees.loan_id,
+    late_fees.billing_schedule_id,
+    billing_schedule.period,
+    late_fees.amount,
+    late_fees.paid_amount,
+    late_fees.charged_at
+FROM late_fees
+JOIN billing_schedule ON billing_schedule.id = late_fees.billing_schedule_id
+WHERE late_fees.loan_id = $1
+ORDER BY billing_schedule.period;
+
+-- name: UpdateLateFeePaidAmount :exec
+UPDATE late_fees
+SET paid_amount = $1, updatedat = now()
+WHERE id = $2;
+
+-- name: CreateLoanRestructure :one
+INSERT INTO loan_restructures (loan_id, from_version, to_version, outstanding_before, principal, outstanding_after, interest_rate, interest_method, tenor_periods, repayment_frequency, reason, restructured_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, restructured_at;
+
+-- name: CreatePaymentHoliday :one
+INSERT INTO payment_holidays (loan_id, from_period, periods, start_date, end_date, capitalised_interest, reason)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, createdat;
+
+-- name: UpdateLoanStatus :execrows
+UPDATE loans
+SET status = sqlc.arg('to_status'), closedat = sqlc.narg('closed_at'), updatedat = now()
+WHERE id = sqlc.arg('id') AND status = sqlc.arg('from_status');
+
+-- name: CreateLoanStatusHistory :one
+INSERT INTO loan_status_history (loan_id, from_status, to_status, event, reason, transitioned_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, transitioned_at;
+
+-- name: GetLoanStatusHistory :many
+SELECT id, loan_id, from_status, to_status, event, reason, transitioned_at
+FROM loan_status_history
+WHERE loan_id = $1
+ORDER BY transitioned_at, id;
+
+-- name: CreateIdempotencyKey :execrows
+-- a reservation older than the lease was left behind by a request that never finished and is taken over
+INSERT INTO idempotency_keys (key, request_hash)
+VALUES (sqlc.arg('key'), sqlc.arg('request_hash'))
+ON CONFLICT (key) DO UPDATE SET createdat = CURRENT_TIMESTAMP
+WHERE idempotency_keys.completedat IS NULL
+  AND idempotency_keys.request_hash = EXCLUDED.request_hash
+  AND idempotency_keys.createdat < CURRENT_TIMESTAMP - sqlc.arg('lease')::interval;
+
+-- name: GetIdempotencyKey :one
+SELECT key, request_hash, response_status, response_body, createdat, completedat
+FROM idempotency_keys
+WHERE key = $1;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET response_status = $2, response_body = $3, completedat = now()
+WHERE key = $1;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1 AND completedat IS NULL;
+
+-- name: CreateLoanProduct :one
+INSERT INTO loan_products (name, min_amount, max_amount, interest_rate, interest_method, tenor_periods, repayment_frequency, installment_fee, origination_fee_rate)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, createdat, updatedat, deletedat, name, min_amount, max_amount, interest_rate, interest_method, tenor_periods, repayment_frequency, installment_fee, origination_fee_rate, active;
+
+-- name: GetLoanProductByID :one
+SELECT id, createdat, updatedat, deletedat, name, min_amount, max_amount, interest_rate, interest_method, tenor_periods, repayment_frequency, installment_fee, origination_fee_rate, active
+FROM loan_products
+WHERE id = $1;
+
+-- name: ListLoanProducts :many
+SELECT id, createdat, updatedat, deletedat, name, min_amount, max_amount, interest_rate, interest_method, tenor_periods, repayment_frequency, installment_fee, origination_fee_rate, active
+FROM loan_products
+ORDER BY id;
+
+-- name: SetLoanProductActive :execrows
+UPDATE loan_products
+SET active = $1, updatedat = now()
+WHERE id = $2;
+
+-- name: ListHolidaysByRegion :many
+SELECT id, createdat, updatedat, deletedat, region, date, name
+FROM holidays
+WHERE region = $1 AND deletedat IS NULL
+ORDER BY date;
+
+-- name: CreateJournalEntry :one
+INSERT INTO journal_entries (loan_id, payment_id, kind, description, posted_at, reverses_entry_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, posted_at;
+
+-- name: CreateJournalPosting :exec
+INSERT INTO journal_postings (entry_id, account_code, debit, credit)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetJournalEntriesByLoanID :many
+SELECT id, loan_id, payment_id, kind, description, posted_at, reverses_entry_id
+FROM journal_entries
+WHERE loan_id = $1
+ORDER BY id;
+
+-- name: GetJournalPostingsByLoanID :many
+SELECT journal_postings.entry_id, journal_postings.account_code, journal_postings.debit, journal_postings.credit
+FROM journal_postings
+JOIN journal_entries ON journal_entries.id = journal_postings.entry_id
+WHERE journal_entries.loan_id = $1
+ORDER BY journal_postings.entry_id, journal_postings.id;
+
+-- name: GetTrialBalance :many
+SELECT
+    ledger_accounts.code,
+    ledger_accounts.number,
+    ledger_accounts.name,
+    ledger_accounts.type,
+    COALESCE(SUM(journal_postings.debit), 0)::numeric AS debit,
+    COALESCE(SUM(journal_postings.credit), 0)::numeric AS credit
+FROM ledger_accounts
+LEFT JOIN (
+    journal_postings
+    JOIN journal_entries ON journal_entries.id = journal_postings.entry_id AND journal_entries.posted_at <= $1
+) ON journal_postings.account_code = ledger_accounts.code
+GROUP BY ledger_accounts.code, ledger_accounts.number, ledger_accounts.name, ledger_accounts.type
+ORDER BY ledger_accounts.number;
+
+-- name: UpdateBillingScheduleAccruedInterest :exec
 UPDATE billing_schedule
-SET paid = true
-WHERE loan_id = $1 AND due_date < now();
+SET accrued_interest = $1
+WHERE id = $2;
+
+-- name: UpdateLoanAccruedInterest :exec
+UPDATE loans
+SET accrued_interest = $1, updatedat = now()
+WHERE id = $2;
+
+-- name: UpsertInterestAccrual :one
+INSERT INTO interest_accruals (loan_id, accrual_date, method, amount, accrued_to_date)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (loan_id, accrual_date) DO UPDATE
+SET method = EXCLUDED.method, amount = interest_accruals.amount + EXCLUDED.amount,
+    accrued_to_date = EXCLUDED.accrued_to_date, updatedat = now()
+RETURNING id, amount;
+
+-- name: GetInterestAccrualsByLoanID :many
+SELECT id, loan_id, accrual_date, method, amount, accrued_to_date
+FROM interest_accruals
+WHERE loan_id = $1
+ORDER BY accrual_date;
+
+-- name: GetInterestAccrualTotals :many
+SELECT accrual_date, count(1) AS loans, SUM(amount)::numeric AS amount
+FROM interest_accruals
+WHERE accrual_date BETWEEN sqlc.arg('from_date') AND sqlc.arg('to_date')
+GROUP BY accrual_date
+ORDER BY accrual_date;
+
+-- name: CreateLoanWriteOff :one
+INSERT INTO loan_write_offs (loan_id, principal, interest, fee, late_fees, amount, reason, automatic, written_off_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, written_off_at;
+
+-- name: GetLoanWriteOffByLoanID :one
+SELECT id, loan_id, principal, interest, fee, late_fees, amount, recovered_amount, reason, automatic, written_off_at
+FROM loan_write_offs
+WHERE loan_id = $1;
+
+-- name: GetLoanWriteOffsBetween :many
+SELECT id, loan_id, principal, interest, fee, late_fees, amount, recovered_amount, reason, automatic, written_off_at
+FROM loan_write_offs
+WHERE written_off_at >= sqlc.arg('from_time') AND written_off_at < sqlc.arg('to_time')
+ORDER BY written_off_at, id;
+
+-- name: UpdateLoanWriteOffRecoveredAmount :exec
+UPDATE loan_write_offs
+SET recovered_amount = $1, updatedat = now()
+WHERE id = $2;
+
+-- name: CreateRecovery :one
+INSERT INTO recoveries (loan_id, amount, recovered_at)
+VALUES ($1, $2, $3)
+RETURNING id, recovered_at;
+
+-- name: GetRecoveriesByLoanID :many
+SELECT id, loan_id, amount, recovered_at
+FROM recoveries
+WHERE loan_id = $1
+ORDER BY recovered_at, id;
+
+-- name: GetRecoveriesBetween :many
+SELECT id, loan_id, amount, recovered_at
+FROM recoveries
+WHERE recovered_at >= sqlc.arg('from_time') AND recovered_at < sqlc.arg('to_time')
+ORDER BY recovered_at, id;
+
+-- name: GetBorrowerByLoanID :one
+SELECT borrowers.id, borrowers.name, borrowers.email, borrowers.phone
+FROM borrowers
+JOIN loans ON loans.borrower_id = borrowers.id
+WHERE loans.id = $1;